@@ -2,6 +2,7 @@ package auth
 
 import (
 	"os/exec"
+	"slices"
 	"strings"
 )
 
@@ -22,6 +23,10 @@ func (ExecRunner) Run(name string, args ...string) (string, error) {
 type Resolver struct {
 	Cmd    CmdRunner
 	Getenv func(string) string
+	// EnterpriseHosts are the hosts, besides GH_HOST, that the
+	// GH_ENTERPRISE_TOKEN and GITHUB_ENTERPRISE_TOKEN variables are sent
+	// to.
+	EnterpriseHosts []string
 }
 
 // ResolveToken returns a token using the priority: gh auth token > GITHUB_TOKEN > empty.
@@ -36,3 +41,42 @@ func (r *Resolver) ResolveToken() string {
 	}
 	return ""
 }
+
+// ResolveHostToken returns a token for a GitHub Enterprise Server host
+// using the priority: gh auth token --hostname host > GITHUB_TOKEN_<HOST>
+// > GH_ENTERPRISE_TOKEN > GITHUB_ENTERPRISE_TOKEN > empty. <HOST> is host
+// in upper case with characters other than letters and digits replaced by
+// underscores, as in GITHUB_TOKEN_GHE_EXAMPLE_COM. The enterprise tokens
+// name no host, so they are only used for GH_HOST and EnterpriseHosts.
+func (r *Resolver) ResolveHostToken(host string) string {
+	if out, err := r.Cmd.Run("gh", "auth", "token", "--hostname", host); err == nil {
+		if tok := strings.TrimSpace(out); tok != "" {
+			return tok
+		}
+	}
+	keys := []string{HostTokenEnv("GITHUB_TOKEN", host)}
+	if host == r.Getenv("GH_HOST") || slices.Contains(r.EnterpriseHosts, host) {
+		keys = append(keys, "GH_ENTERPRISE_TOKEN", "GITHUB_ENTERPRISE_TOKEN")
+	}
+	for _, key := range keys {
+		if tok := r.Getenv(key); tok != "" {
+			return tok
+		}
+	}
+	return ""
+}
+
+// HostTokenEnv returns the environment variable holding the token of a
+// self-hosted instance: prefix, an underscore, and host as described in
+// ResolveHostToken.
+func HostTokenEnv(prefix, host string) string {
+	return prefix + "_" + strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		}
+		return '_'
+	}, host)
+}
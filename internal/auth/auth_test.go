@@ -67,3 +67,58 @@ func TestResolveToken_GHPriority(t *testing.T) {
 		t.Errorf("gh auth token should have priority; got %q", tok)
 	}
 }
+
+type recordingCmd struct {
+	args []string
+}
+
+func (c *recordingCmd) Run(name string, args ...string) (string, error) {
+	c.args = args
+	return "ghe_token\n", nil
+}
+
+func TestResolveHostToken_GHAuthToken(t *testing.T) {
+	cmd := &recordingCmd{}
+	r := &Resolver{Cmd: cmd, Getenv: func(string) string { return "" }}
+	if tok := r.ResolveHostToken("ghe.example.com"); tok != "ghe_token" {
+		t.Errorf("got %q, want %q", tok, "ghe_token")
+	}
+	want := "auth token --hostname ghe.example.com"
+	if got := fmt.Sprint(cmd.args); got != "["+want+"]" {
+		t.Errorf("gh args: got %s, want [%s]", got, want)
+	}
+}
+
+func TestResolveHostToken_EnvFallback(t *testing.T) {
+	env := map[string]string{
+		"GITHUB_TOKEN":                   "github_com_token",
+		"GITHUB_TOKEN_GHE_EXAMPLE_COM":   "host_token",
+		"GH_ENTERPRISE_TOKEN":            "enterprise_token",
+		"GITHUB_TOKEN_OTHER_EXAMPLE_COM": "",
+	}
+	r := &Resolver{
+		Cmd:    &fakeCmd{err: fmt.Errorf("gh not found")},
+		Getenv: func(key string) string { return env[key] },
+	}
+	if tok := r.ResolveHostToken("ghe.example.com"); tok != "host_token" {
+		t.Errorf("got %q, want %q", tok, "host_token")
+	}
+	if tok := r.ResolveHostToken("other.example.com"); tok != "" {
+		t.Errorf("an unrelated host should get no enterprise token, got %q", tok)
+	}
+
+	r.EnterpriseHosts = []string{"other.example.com"}
+	if tok := r.ResolveHostToken("other.example.com"); tok != "enterprise_token" {
+		t.Errorf("configured host: got %q, want %q", tok, "enterprise_token")
+	}
+	env["GH_HOST"] = "gh.example.com"
+	if tok := r.ResolveHostToken("gh.example.com"); tok != "enterprise_token" {
+		t.Errorf("GH_HOST: got %q, want %q", tok, "enterprise_token")
+	}
+}
+
+func TestHostTokenEnv(t *testing.T) {
+	if got := HostTokenEnv("GITHUB_TOKEN", "ghe.example.com:8443"); got != "GITHUB_TOKEN_GHE_EXAMPLE_COM_8443" {
+		t.Errorf("got %q", got)
+	}
+}
@@ -17,13 +17,19 @@ type Provider struct {
 
 // NewProvider creates a caching decorator around the given provider.
 // When noCache is true, the cache is bypassed on reads but results are
-// still written (so subsequent runs without --no-cache benefit).
-func NewProvider(underlying provider.Provider, store *Store, noCache bool) *Provider {
-	return &Provider{
+// still written (so subsequent runs without --no-cache benefit). The
+// decorator is a BatchProvider only when the underlying provider is one,
+// so that other providers still return each result as it is fetched.
+func NewProvider(underlying provider.Provider, store *Store, noCache bool) provider.Provider {
+	p := &Provider{
 		underlying: underlying,
 		store:      store,
 		noCache:    noCache,
 	}
+	if bp, ok := underlying.(provider.BatchProvider); ok {
+		return &BatchProvider{Provider: p, underlying: bp}
+	}
+	return p
 }
 
 func (p *Provider) Scheme() string {
@@ -50,3 +56,44 @@ func (p *Provider) Fetch(ctx context.Context, identifier string) (provider.Resul
 
 	return result, nil
 }
+
+var _ provider.BatchProvider = (*BatchProvider)(nil)
+
+// BatchProvider is a Provider around a provider.BatchProvider.
+type BatchProvider struct {
+	*Provider
+	underlying provider.BatchProvider
+}
+
+// FetchBatch serves cached identifiers from the store and fetches the rest
+// together, so that the underlying provider still batches every miss.
+func (p *BatchProvider) FetchBatch(ctx context.Context, identifiers []string) []provider.Result {
+	scheme := p.Scheme()
+	results := make([]provider.Result, len(identifiers))
+
+	var missing []int
+	for i, id := range identifiers {
+		if !p.noCache {
+			if cached, ok := p.store.Get(scheme + ":" + id); ok {
+				results[i] = cached
+				continue
+			}
+		}
+		missing = append(missing, i)
+	}
+	if len(missing) == 0 {
+		return results
+	}
+
+	ids := make([]string, len(missing))
+	for j, i := range missing {
+		ids[j] = identifiers[i]
+	}
+	for j, result := range p.underlying.FetchBatch(ctx, ids) {
+		results[missing[j]] = result
+		if result.Error == "" {
+			_ = p.store.Set(scheme+":"+ids[j], result)
+		}
+	}
+	return results
+}
@@ -155,3 +155,55 @@ func TestProviderTTLExpiry(t *testing.T) {
 		t.Fatalf("calls = %d, want 2 (TTL expiry should re-fetch)", mock.calls.Load())
 	}
 }
+
+// mockBatchProvider records the identifiers of every FetchBatch call.
+type mockBatchProvider struct {
+	mockProvider
+	batches [][]string
+}
+
+func (m *mockBatchProvider) FetchBatch(_ context.Context, identifiers []string) []provider.Result {
+	m.batches = append(m.batches, identifiers)
+	results := make([]provider.Result, len(identifiers))
+	for i, id := range identifiers {
+		results[i] = provider.Result{Target: "github:" + id, GitHub: &provider.GitHubMetrics{Stars: i}}
+	}
+	return results
+}
+
+func TestProviderFetchBatch(t *testing.T) {
+	mock := &mockBatchProvider{mockProvider: mockProvider{scheme: "github"}}
+	store := NewStore(t.TempDir(), 24*time.Hour)
+	p, ok := NewProvider(mock, store, false).(provider.BatchProvider)
+	if !ok {
+		t.Fatal("the cache around a BatchProvider should be a BatchProvider")
+	}
+
+	if err := store.Set("github:cached/repo", provider.Result{Target: "github:cached/repo", GitHub: &provider.GitHubMetrics{Stars: 99}}); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	results := p.FetchBatch(context.Background(), []string{"a/one", "cached/repo", "b/two"})
+	if len(mock.batches) != 1 || len(mock.batches[0]) != 2 || mock.batches[0][1] != "b/two" {
+		t.Fatalf("misses should be fetched in one batch, got %v", mock.batches)
+	}
+	if results[1].GitHub.Stars != 99 || results[2].Target != "github:b/two" {
+		t.Errorf("got %+v", results)
+	}
+
+	p.FetchBatch(context.Background(), []string{"a/one", "b/two"})
+	if len(mock.batches) != 1 {
+		t.Errorf("second batch should be served from cache, got %d batches", len(mock.batches))
+	}
+}
+
+func TestProviderNotBatch(t *testing.T) {
+	mock := &mockProvider{
+		scheme: "npm",
+		result: provider.Result{Target: "npm:react", NPM: &provider.NPMMetrics{}},
+	}
+	p := NewProvider(mock, NewStore(t.TempDir(), 24*time.Hour), false)
+	if _, ok := p.(provider.BatchProvider); ok {
+		t.Error("the cache around a provider without FetchBatch should fetch each identifier on its own")
+	}
+}
@@ -15,7 +15,9 @@ import (
 // nested metrics struct changes (fields added, removed, or renamed).
 // A mismatch between the stored version and the current version causes
 // a cache miss, preventing stale zero-valued fields from being served.
-const schemaVersion = 2
+// Fields set after fetching, such as Dependency, are never cached and
+// need no increment.
+const schemaVersion = 16
 
 // entry is the on-disk JSON structure for a cache entry.
 type entry struct {
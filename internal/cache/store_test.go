@@ -1,8 +1,10 @@
 package cache
 
 import (
+	"encoding/json"
 	"os"
 	"path/filepath"
+	"strconv"
 	"testing"
 	"time"
 
@@ -115,20 +117,38 @@ func TestStoreVersionMismatch(t *testing.T) {
 		t.Fatalf("Set: %v", err)
 	}
 
-	// Tamper with the cached file: set version to 0 (simulates old schema)
+	// Rewrite the cached file with another version. Rewriting it with the
+	// current version must still hit, so that a miss below is down to the
+	// version and not to a malformed file.
 	key := "npm:react"
 	path := store.path(key)
-	data, err := os.ReadFile(path)
-	if err != nil {
-		t.Fatalf("ReadFile: %v", err)
-	}
-	tampered := []byte(`{"version":0,` + string(data[len(`{"version":2,`):]))
-	if err := os.WriteFile(path, tampered, 0o644); err != nil {
-		t.Fatalf("WriteFile: %v", err)
-	}
-
-	_, ok := store.Get(key)
-	if ok {
+	setVersion := func(version int) {
+		t.Helper()
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("ReadFile: %v", err)
+		}
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(data, &fields); err != nil {
+			t.Fatalf("Unmarshal: %v", err)
+		}
+		fields["version"] = json.RawMessage(strconv.Itoa(version))
+		if data, err = json.Marshal(fields); err != nil {
+			t.Fatalf("Marshal: %v", err)
+		}
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+	}
+
+	setVersion(schemaVersion)
+	if _, ok := store.Get(key); !ok {
+		t.Fatal("expected cache hit for a rewritten entry of the current version")
+	}
+
+	// Simulate an entry written by an old schema.
+	setVersion(0)
+	if _, ok := store.Get(key); ok {
 		t.Fatal("expected cache miss for old schema version")
 	}
 }
@@ -0,0 +1,134 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/yutakobayashidev/repiq/internal/format"
+	"github.com/yutakobayashidev/repiq/internal/lockfile"
+	"github.com/yutakobayashidev/repiq/internal/manifest"
+	"github.com/yutakobayashidev/repiq/internal/policy"
+	"github.com/yutakobayashidev/repiq/internal/provider"
+)
+
+// runCheck fetches metrics for targets and manifests and evaluates them
+// against a policy file.
+func runCheck(args []string, stdout, stderr io.Writer) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	fs := flag.NewFlagSet("repiq check", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	policyPath := fs.String("policy", "repiq-policy.yaml", "policy file")
+	jsonFlag := fs.Bool("json", false, "output report as JSON")
+	fs.Bool("markdown", false, "output report as Markdown (default)")
+	fetch := addFetchFlags(fs, cfg)
+
+	fs.Usage = func() {
+		_, _ = io.WriteString(stderr, `Usage: repiq check [flags] <target|manifest|lockfile> [...]
+
+Fetch metrics and fail when any target violates the policy. Arguments may
+be targets (npm:react) or manifests and lockfiles as accepted by repiq scan.
+
+Policy file (YAML):
+  rules:
+    - field: last_commit_days
+      max: 365
+    - field: license
+      allow: [MIT, Apache-2.0, BSD-3-Clause]
+    - scheme: npm
+      field: weekly_downloads
+      min: 1000
+    - field: max_severity
+      deny: [HIGH, CRITICAL]
+
+Exit status is 0 when every target passes, 1 when a target could not be
+fetched, and 2 when a target violates the policy.
+
+Examples:
+  repiq check github:facebook/react npm:react
+  repiq check --policy ci/policy.yaml --json package.json
+
+Flags:
+`)
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := fetch.validate(); err != nil {
+		return err
+	}
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return fmt.Errorf("no targets specified")
+	}
+
+	pol, err := policy.Load(*policyPath)
+	if err != nil {
+		return err
+	}
+
+	registry, err := fetch.registry()
+	if err != nil {
+		return err
+	}
+
+	var targets, paths []string
+	for _, arg := range fs.Args() {
+		if manifest.Supported(arg) || lockfile.Supported(arg) {
+			paths = append(paths, arg)
+		} else {
+			targets = append(targets, arg)
+		}
+	}
+	parsed, err := parseTargets(registry, targets)
+	if err != nil {
+		return err
+	}
+	found, deps, err := collectTargets(paths)
+	if err != nil {
+		return err
+	}
+	seen := make(map[provider.Target]bool)
+	var all []provider.Target
+	for _, t := range append(parsed, found...) {
+		if !seen[t] {
+			seen[t] = true
+			all = append(all, t)
+		}
+	}
+
+	results := fetchAll(registry, fetch.fetchOptions(), all)
+	for i, t := range all {
+		results[i].Dependency = deps[t]
+	}
+	fetch.finish(stderr, results)
+	rep := pol.Check(results)
+
+	formatter := format.PolicyMarkdown
+	if *jsonFlag {
+		formatter = format.PolicyJSON
+	}
+	if err := formatter(stdout, rep); err != nil {
+		return fmt.Errorf("formatting output: %w", err)
+	}
+
+	// Fetch errors take precedence: the policy could not be fully evaluated.
+	if len(rep.Errors) > 0 {
+		return &ExitError{Code: ExitFailure, Err: fmt.Errorf("one or more targets failed")}
+	}
+	if len(rep.Violations) > 0 {
+		return &ExitError{
+			Code: ExitPolicyViolation,
+			Err:  fmt.Errorf("%d targets violate the policy", len(rep.ViolatingTargets())),
+		}
+	}
+	return nil
+}
@@ -1,55 +1,126 @@
 package cli
 
 import (
+	"cmp"
 	"context"
 	"flag"
 	"fmt"
 	"io"
+	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/yutakobayashidev/repiq/internal/auth"
 	"github.com/yutakobayashidev/repiq/internal/cache"
+	"github.com/yutakobayashidev/repiq/internal/config"
 	"github.com/yutakobayashidev/repiq/internal/format"
+	"github.com/yutakobayashidev/repiq/internal/httpclient"
+	"github.com/yutakobayashidev/repiq/internal/osv"
 	"github.com/yutakobayashidev/repiq/internal/provider"
 	cratesprovider "github.com/yutakobayashidev/repiq/internal/provider/crates"
+	dockerprovider "github.com/yutakobayashidev/repiq/internal/provider/docker"
+	gemprovider "github.com/yutakobayashidev/repiq/internal/provider/gem"
+	giteaprovider "github.com/yutakobayashidev/repiq/internal/provider/gitea"
 	ghprovider "github.com/yutakobayashidev/repiq/internal/provider/github"
+	gitlabprovider "github.com/yutakobayashidev/repiq/internal/provider/gitlab"
 	golangprovider "github.com/yutakobayashidev/repiq/internal/provider/golang"
+	hexprovider "github.com/yutakobayashidev/repiq/internal/provider/hex"
+	mavenprovider "github.com/yutakobayashidev/repiq/internal/provider/maven"
 	npmprovider "github.com/yutakobayashidev/repiq/internal/provider/npm"
+	nugetprovider "github.com/yutakobayashidev/repiq/internal/provider/nuget"
+	packagistprovider "github.com/yutakobayashidev/repiq/internal/provider/packagist"
+	pubprovider "github.com/yutakobayashidev/repiq/internal/provider/pub"
 	pypiprovider "github.com/yutakobayashidev/repiq/internal/provider/pypi"
+	scorecardprovider "github.com/yutakobayashidev/repiq/internal/provider/scorecard"
 )
 
 // Version is set at build time via ldflags.
 var Version = "dev"
 
-const timeout = 30 * time.Second
+// Exit codes carried by ExitError.
+const (
+	ExitFailure         = 1
+	ExitPolicyViolation = 2
+)
+
+// ExitError is returned when the process should exit with a specific code.
+// Other errors exit with ExitFailure.
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitError) Error() string { return e.Err.Error() }
+
+func (e *ExitError) Unwrap() error { return e.Err }
 
 // Run executes the CLI with the given arguments.
 func Run(args []string, stdout, stderr io.Writer) error {
+	if len(args) > 0 {
+		switch args[0] {
+		case "scan":
+			return runScan(args[1:], stdout, stderr)
+		case "check":
+			return runCheck(args[1:], stdout, stderr)
+		case "history":
+			return runHistory(args[1:], stdout, stderr)
+		case "diff":
+			return runDiff(args[1:], stdout, stderr)
+		case "config":
+			return runConfig(args[1:], stdout, stderr)
+		}
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
 	fs := flag.NewFlagSet("repiq", flag.ContinueOnError)
 	fs.SetOutput(stderr)
 
-	jsonFlag := fs.Bool("json", false, "output as JSON array")
-	ndjsonFlag := fs.Bool("ndjson", false, "output as newline-delimited JSON")
-	markdownFlag := fs.Bool("markdown", false, "output as Markdown table (default)")
+	out := addOutputFlags(fs, cfg)
 	versionFlag := fs.Bool("version", false, "print version and exit")
-	noCacheFlag := fs.Bool("no-cache", false, "bypass cache and always fetch from API")
 
 	fs.Usage = func() {
 		_, _ = io.WriteString(stderr, `Usage: repiq [flags] <scheme>:<identifier> [...]
+       repiq scan [flags] <manifest> [...]
+       repiq check [flags] <target|manifest> [...]
+       repiq history [flags] <scheme>:<identifier>
+       repiq diff [flags] <old.json> <new.json>
+       repiq config show
 
 Fetch objective metrics for OSS libraries and repositories.
 
 Examples:
   repiq github:facebook/react
+  repiq gitlab:gitlab-org/gitlab-runner
+  repiq codeberg:forgejo/forgejo
   repiq npm:react
   repiq pypi:requests
   repiq crates:serde
   repiq go:golang.org/x/text
+  repiq gem:rails
+  repiq maven:com.google.guava:guava
+  repiq nuget:Newtonsoft.Json
+  repiq packagist:laravel/framework
+  repiq hex:phoenix
+  repiq pub:http
+  repiq docker:library/nginx
+  repiq scorecard:github.com/ossf/scorecard
   repiq --json github:facebook/react
   repiq --ndjson github:facebook/react npm:react pypi:flask
+  repiq --with-source npm:react
+  repiq --stream npm:react pypi:flask crates:serde
+  repiq scan package.json go.mod
+  repiq check --policy repiq-policy.yaml package.json
+  repiq --record github:facebook/react
+  repiq history --since 90d github:facebook/react
+  repiq diff before.json after.json
 
 Flags:
 `)
@@ -59,6 +130,9 @@ Flags:
 	if err := fs.Parse(args); err != nil {
 		return err
 	}
+	if err := out.validate(); err != nil {
+		return err
+	}
 
 	if *versionFlag {
 		_, _ = fmt.Fprintf(stdout, "repiq %s\n", Version)
@@ -66,93 +140,506 @@ Flags:
 	}
 
 	targets := fs.Args()
+	if len(targets) == 0 {
+		targets = cfg.Targets
+	}
 	if len(targets) == 0 {
 		fs.Usage()
 		return fmt.Errorf("no targets specified")
 	}
 
-	// Determine output format. When multiple flags are set, priority:
-	// json > ndjson > markdown (default). This matches the spec's
-	// "last specified wins" intent for wrapper scripts that set defaults.
+	registry, err := out.registry()
+	if err != nil {
+		return err
+	}
+
+	// Parse and validate all targets first.
+	parsed, err := parseTargets(registry, targets)
+	if err != nil {
+		return err
+	}
+
+	opts, formatter := out.output(stdout)
+	results := fetchAll(registry, opts, parsed)
+	out.finish(stderr, results)
+	return report(stdout, formatter, results)
+}
+
+// parseTargets parses raw targets and checks that every scheme is
+// registered.
+func parseTargets(registry *provider.Registry, raw []string) ([]provider.Target, error) {
+	parsed := make([]provider.Target, len(raw))
+	for i, r := range raw {
+		t, err := provider.ParseTarget(r)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := registry.Lookup(t.Scheme); !ok {
+			return nil, fmt.Errorf("unknown scheme %q in target %q", t.Scheme, r)
+		}
+		parsed[i] = t
+	}
+	return parsed, nil
+}
+
+// outputFlags holds the flags shared by every command that fetches and
+// prints results.
+type outputFlags struct {
+	*fetchFlags
+	json     *bool
+	ndjson   *bool
+	markdown *bool
+	stream   *bool
+}
+
+func addOutputFlags(fs *flag.FlagSet, cfg *config.Config) *outputFlags {
+	return &outputFlags{
+		json:       fs.Bool("json", false, "output as JSON array"),
+		ndjson:     fs.Bool("ndjson", false, "output as newline-delimited JSON"),
+		markdown:   fs.Bool("markdown", false, "output as Markdown table (default)"),
+		stream:     fs.Bool("stream", false, "output each result as newline-delimited JSON as soon as it is fetched, with its input position as \"index\""),
+		fetchFlags: addFetchFlags(fs, cfg),
+	}
+}
+
+// output returns the options to fetch with and the formatter to print the
+// results with. With --stream, results are written to stdout by fetchAll
+// as they complete, and the formatter only reports write errors.
+func (o *outputFlags) output(stdout io.Writer) (fetchOptions, func(io.Writer, []provider.Result) error) {
+	opts := o.fetchOptions()
+	if !*o.stream {
+		return opts, o.formatter()
+	}
+	stream := format.NewStream(stdout)
+	opts.emit = stream.Write
+	return opts, func(io.Writer, []provider.Result) error { return stream.Err() }
+}
+
+// fetchFlags holds the flags shared by every command that fetches results,
+// whatever it prints.
+type fetchFlags struct {
+	cfg         *config.Config
+	noCache     *bool
+	noVulns     *bool
+	withSource  *bool
+	record      *bool
+	verbose     *bool
+	concurrency *int
+	timeout     *time.Duration
+	reqTimeout  *time.Duration
+	transport   *httpclient.Transport
+}
+
+func addFetchFlags(fs *flag.FlagSet, cfg *config.Config) *fetchFlags {
+	return &fetchFlags{
+		cfg:         cfg,
+		noCache:     fs.Bool("no-cache", false, "bypass cache and always fetch from API"),
+		noVulns:     fs.Bool("no-vulns", false, "skip the OSV.dev vulnerability lookup"),
+		withSource:  fs.Bool("with-source", false, "also fetch GitHub metrics for each package's source repository"),
+		record:      fs.Bool("record", false, "append today's results to the history shown by repiq history"),
+		verbose:     fs.Bool("verbose", false, "print API requests, retries and remaining rate limits to stderr"),
+		concurrency: fs.Int("concurrency", cfg.Concurrency, fmt.Sprintf("maximum API requests in flight (at most %d per host)", httpclient.DefaultPerHost)),
+		timeout:     fs.Duration("timeout", cfg.Timeout, "give up on targets not fetched within this time and print the rest"),
+		reqTimeout:  fs.Duration("request-timeout", cfg.RequestTimeout, "time limit for each API request before it is retried"),
+	}
+}
+
+// validate checks flag values that the flag package cannot.
+func (f *fetchFlags) validate() error {
+	if *f.concurrency < 1 {
+		return fmt.Errorf("--concurrency must be at least 1, got %d", *f.concurrency)
+	}
+	if *f.timeout <= 0 {
+		return fmt.Errorf("--timeout must be positive, got %s", *f.timeout)
+	}
+	if *f.reqTimeout <= 0 {
+		return fmt.Errorf("--request-timeout must be positive, got %s", *f.reqTimeout)
+	}
+	return nil
+}
+
+func (f *fetchFlags) fetchOptions() fetchOptions {
+	opts := fetchOptions{
+		withSource: *f.withSource,
+		timeout:    *f.timeout,
+	}
+	if !*f.noVulns {
+		opts.vulns = osv.New(f.cfg.Registries["osv"].URL).WithTransport(f.httpTransport())
+	}
+	return opts
+}
+
+// registry returns the providers to fetch with, set up as configured.
+func (f *fetchFlags) registry() (*provider.Registry, error) {
+	return newRegistry(f.cfg, *f.noCache, f.httpTransport())
+}
+
+// httpTransport returns the transport every request of the run goes
+// through, created on first use with the flags' limits.
+func (f *fetchFlags) httpTransport() *httpclient.Transport {
+	if f.transport == nil {
+		f.transport = httpclient.NewTransport(*f.concurrency, *f.reqTimeout)
+	}
+	return f.transport
+}
+
+// finish records history and prints the --verbose summary once results are
+// fetched.
+func (f *fetchFlags) finish(stderr io.Writer, results []provider.Result) {
+	if *f.record {
+		recordHistory(stderr, results)
+	}
+	if *f.verbose {
+		writeHTTPStats(stderr, f.httpTransport().Stats.Hosts(), time.Now())
+	}
+}
+
+// fetchOptions controls how fetchAll fetches targets and the lookups it
+// performs afterwards.
+type fetchOptions struct {
+	// timeout bounds the whole fetch, lookups included; 0 means
+	// config.DefaultTimeout. Targets not fetched by then fail with a timeout
+	// error while the others keep their results.
+	timeout time.Duration
+	// vulns enriches registry results with known vulnerabilities; nil
+	// disables the lookup.
+	vulns *osv.Client
+	// withSource nests the GitHub metrics of each registry package's
+	// source repository in its result.
+	withSource bool
+	// emit, when set, is called with the index and enriched result of each
+	// target as soon as it is done, and for unfinished targets when the
+	// timeout expires. Calls are serialized.
+	emit func(i int, r provider.Result)
+}
+
+// formatter determines the output format. When multiple flags are set,
+// priority: json > ndjson > markdown. This matches the spec's "last
+// specified wins" intent for wrapper scripts that set defaults. Without
+// any, the configured format is used.
+func (o *outputFlags) formatter() func(io.Writer, []provider.Result) error {
 	formatter := format.Markdown
-	if *ndjsonFlag {
+	switch o.cfg.Format {
+	case "json":
+		formatter = format.JSON
+	case "ndjson":
 		formatter = format.NDJSON
 	}
-	if *jsonFlag {
+	if *o.ndjson {
+		formatter = format.NDJSON
+	}
+	if *o.json {
 		formatter = format.JSON
 	}
-	if *markdownFlag && !*jsonFlag && !*ndjsonFlag {
+	if *o.markdown && !*o.json && !*o.ndjson {
 		formatter = format.Markdown
 	}
+	return formatter
+}
+
+// registrySchemes are the schemes whose API can be configured under
+// [registries], and whether it has a stats_url.
+var registrySchemes = map[string]bool{
+	"npm": true, "pypi": true, "crates": false, "go": false, "gem": false, "maven": false,
+	"nuget": false, "packagist": true, "hex": false, "pub": false, "docker": false,
+	"gitlab": false, "codeberg": false, "gitea": false, "scorecard": false, "osv": false,
+}
+
+// newRegistry sets up every provider as configured by cfg, sending its
+// requests through transport, and wrapped with the disk cache when a user
+// cache directory is available.
+func newRegistry(cfg *config.Config, noCache bool, transport *httpclient.Transport) (*provider.Registry, error) {
+	headers := make(map[string]http.Header)
+	for scheme, r := range cfg.Registries {
+		hasStats, ok := registrySchemes[scheme]
+		if !ok {
+			return nil, fmt.Errorf("config: registries: unsupported scheme %q", scheme)
+		}
+		if r.StatsURL != "" && !hasStats {
+			return nil, fmt.Errorf("config: registries: %s has no stats_url", scheme)
+		}
+		if len(r.Headers) > 0 {
+			host, err := registryHost(scheme, r.URL)
+			if err != nil {
+				return nil, err
+			}
+			for name, value := range r.Headers {
+				addHeader(headers, host, name, value)
+			}
+		}
+	}
+	for host, token := range cfg.Tokens {
+		addHeader(headers, host, "Authorization", "Bearer "+token)
+	}
+	transport.Headers = headers
 
-	// Set up providers.
 	resolver := &auth.Resolver{
-		Cmd:    auth.ExecRunner{},
-		Getenv: os.Getenv,
+		Cmd:             auth.ExecRunner{},
+		Getenv:          os.Getenv,
+		EnterpriseHosts: cfg.UserTokenHosts(),
 	}
 	token := resolver.ResolveToken()
+	if token == "" {
+		token = cfg.Tokens["api.github.com"]
+	}
 
-	registry := provider.NewRegistry()
+	reg := cfg.Registries
+	providers := []provider.Provider{
+		ghprovider.New(token, "").WithHosts(func(host string) (string, string) {
+			token := resolver.ResolveHostToken(host)
+			if token == "" {
+				token = cfg.Tokens[host]
+			}
+			return ghprovider.EnterpriseBaseURL(host), token
+		}).WithTransport(transport),
+		gitlabprovider.New(reg["gitlab"].URL, forgeToken(reg["gitlab"].URL, "gitlab.com", "GITLAB_TOKEN", "GITLAB_TOKEN")).WithHosts(func(host string) (string, string) {
+			return gitlabprovider.HostBaseURL(host), os.Getenv(auth.HostTokenEnv("GITLAB_TOKEN", host))
+		}).WithTransport(transport),
+		giteaprovider.New("codeberg", cmp.Or(reg["codeberg"].URL, giteaprovider.CodebergURL), forgeToken(reg["codeberg"].URL, "codeberg.org", "CODEBERG_TOKEN", "GITEA_TOKEN")).WithHosts(giteaHost).WithTransport(transport),
+		giteaprovider.New("gitea", reg["gitea"].URL, forgeToken(reg["gitea"].URL, "", "", "GITEA_TOKEN")).WithHosts(giteaHost).WithTransport(transport),
+		npmprovider.New(reg["npm"].URL, reg["npm"].StatsURL).WithTransport(transport),
+		pypiprovider.New(reg["pypi"].URL, reg["pypi"].StatsURL).WithTransport(transport),
+		cratesprovider.New(reg["crates"].URL).WithTransport(transport),
+		golangprovider.NewFromEnv(reg["go"].URL, "", os.Getenv).WithTransport(transport),
+		gemprovider.New(reg["gem"].URL).WithTransport(transport),
+		mavenprovider.New(reg["maven"].URL).WithTransport(transport),
+		nugetprovider.New(reg["nuget"].URL).WithTransport(transport),
+		packagistprovider.New(reg["packagist"].URL, reg["packagist"].StatsURL).WithTransport(transport),
+		hexprovider.New(reg["hex"].URL).WithTransport(transport),
+		pubprovider.New(reg["pub"].URL).WithTransport(transport),
+		dockerprovider.New(reg["docker"].URL).WithTransport(transport),
+		scorecardprovider.New(reg["scorecard"].URL).WithTransport(transport),
+	}
 
-	ghProvider := provider.Provider(ghprovider.New(token, ""))
-	npmProvider := provider.Provider(npmprovider.New("", ""))
-	pypiProvider := provider.Provider(pypiprovider.New("", ""))
-	cratesProvider := provider.Provider(cratesprovider.New(""))
-	goProvider := provider.Provider(golangprovider.New("", ""))
+	registry := provider.NewRegistry()
+	for _, p := range providers {
+		registry.Register(p)
+	}
+	for scheme := range cfg.SchemeTTL {
+		if _, ok := registry.Lookup(scheme); !ok {
+			return nil, fmt.Errorf("config: cache.schemes: unknown scheme %q", scheme)
+		}
+	}
 
 	if cacheDir, err := os.UserCacheDir(); err == nil {
-		store := cache.NewStore(filepath.Join(cacheDir, "repiq"), 24*time.Hour)
-		ghProvider = cache.NewProvider(ghProvider, store, *noCacheFlag)
-		npmProvider = cache.NewProvider(npmProvider, store, *noCacheFlag)
-		pypiProvider = cache.NewProvider(pypiProvider, store, *noCacheFlag)
-		cratesProvider = cache.NewProvider(cratesProvider, store, *noCacheFlag)
-		goProvider = cache.NewProvider(goProvider, store, *noCacheFlag)
+		dir := filepath.Join(cacheDir, "repiq")
+		for _, p := range providers {
+			store := cache.NewStore(dir, cfg.TTL(p.Scheme()))
+			registry.Register(cache.NewProvider(p, store, noCache))
+		}
 	}
+	return registry, nil
+}
 
-	registry.Register(ghProvider)
-	registry.Register(npmProvider)
-	registry.Register(pypiProvider)
-	registry.Register(cratesProvider)
-	registry.Register(goProvider)
-
-	// Parse and validate all targets first.
-	parsed := make([]provider.Target, len(targets))
-	for i, raw := range targets {
-		t, err := provider.ParseTarget(raw)
-		if err != nil {
-			return err
+// forgeToken returns the token sent to the forge API at rawURL: publicEnv
+// for its public instance at publicHost, which an empty rawURL stands for,
+// and the host's own <hostPrefix>_<HOST> variable for any other host, since
+// a registry URL may come from a project's config.
+func forgeToken(rawURL, publicHost, publicEnv, hostPrefix string) string {
+	host := publicHost
+	if rawURL != "" {
+		u, err := url.Parse(rawURL)
+		if err != nil || u.Host == "" {
+			return ""
 		}
-		if _, ok := registry.Lookup(t.Scheme); !ok {
-			return fmt.Errorf("unknown scheme %q in target %q", t.Scheme, raw)
-		}
-		parsed[i] = t
+		host = u.Host
+	}
+	switch host {
+	case "":
+		return ""
+	case publicHost:
+		return os.Getenv(publicEnv)
 	}
+	return os.Getenv(auth.HostTokenEnv(hostPrefix, host))
+}
+
+// giteaHost resolves the API and token of a Gitea or Forgejo host.
+func giteaHost(host string) (string, string) {
+	return giteaprovider.HostBaseURL(host), os.Getenv(auth.HostTokenEnv("GITEA_TOKEN", host))
+}
 
-	// Fetch all targets in parallel.
+// registryHost returns the host a registry's headers are sent to. Headers
+// need a registry URL: they are never sent to a default public registry.
+func registryHost(scheme, rawURL string) (string, error) {
+	if rawURL == "" {
+		return "", fmt.Errorf("config: registries: %s has headers but no url", scheme)
+	}
+	// A Go proxy URL may be a GOPROXY list; headers go to its first entry.
+	first, _, _ := strings.Cut(strings.ReplaceAll(rawURL, "|", ","), ",")
+	u, err := url.Parse(first)
+	if err != nil || u.Host == "" {
+		return "", fmt.Errorf("config: registries: %s: invalid url %q", scheme, rawURL)
+	}
+	return u.Host, nil
+}
+
+func addHeader(headers map[string]http.Header, host, name, value string) {
+	if headers[host] == nil {
+		headers[host] = make(http.Header)
+	}
+	headers[host].Set(name, value)
+}
+
+// loadConfig loads the configuration for the working directory.
+func loadConfig() (*config.Config, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	return config.Load(cwd, os.Getenv)
+}
+
+// enrichGrace bounds the enrichment of the targets fetched before the
+// timeout, once it has expired.
+const enrichGrace = 10 * time.Second
+
+// streamWindow is how long streamed results are buffered before they are
+// enriched and emitted together.
+const streamWindow = 200 * time.Millisecond
+
+// fetchAll fetches all targets, one scheme at a time in parallel. Every
+// target's scheme must already be registered. Providers that support it
+// fetch their targets in batches. Registry results are then enriched as
+// requested by opts, in one pass over all results, or per result as it
+// arrives when streaming. Targets still unfinished when opts.timeout
+// expires get a timeout error; the others keep their results.
+func fetchAll(registry *provider.Registry, opts fetchOptions, targets []provider.Target) []provider.Result {
+	timeout := cmp.Or(opts.timeout, config.DefaultTimeout)
 	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 
-	results := make([]provider.Result, len(parsed))
+	var schemes []string
+	byScheme := make(map[string][]int)
+	for i, t := range targets {
+		if _, ok := byScheme[t.Scheme]; !ok {
+			schemes = append(schemes, t.Scheme)
+		}
+		byScheme[t.Scheme] = append(byScheme[t.Scheme], i)
+	}
+
+	results := make([]provider.Result, len(targets))
+	fetched := make([]bool, len(targets))
+	var pending []int
+	var mu sync.Mutex
 	var wg sync.WaitGroup
-	wg.Add(len(parsed))
-	for i, t := range parsed {
-		go func(i int, t provider.Target) {
+	wg.Add(len(schemes))
+	for _, scheme := range schemes {
+		go func(indices []int) {
 			defer wg.Done()
-			p, _ := registry.Lookup(t.Scheme)
-			result, err := p.Fetch(ctx, t.Identifier)
-			if err != nil {
-				results[i] = provider.Result{
-					Target: t.Scheme + ":" + t.Identifier,
-					Error:  err.Error(),
+			p, _ := registry.Lookup(targets[indices[0]].Scheme)
+			ids := make([]string, len(indices))
+			for j, i := range indices {
+				ids[j] = targets[i].Identifier
+			}
+			provider.FetchEach(ctx, p, ids, func(j int, result provider.Result) {
+				mu.Lock()
+				defer mu.Unlock()
+				// Past the deadline results only carry its error, and
+				// fetchAll may already have stopped waiting.
+				if ctx.Err() != nil {
+					return
 				}
-				return
+				i := indices[j]
+				results[i] = result
+				fetched[i] = true
+				pending = append(pending, i)
+			})
+		}(byScheme[scheme])
+	}
+
+	// flush enriches the results fetched since the last flush together and
+	// emits them, so that streaming does not cost one OSV query per result.
+	flush := func(ctx context.Context) {
+		mu.Lock()
+		batch := pending
+		pending = nil
+		mu.Unlock()
+		if len(batch) == 0 {
+			return
+		}
+		rs := make([]provider.Result, len(batch))
+		for k, i := range batch {
+			rs[k] = results[i]
+		}
+		enrich(ctx, registry, opts, rs)
+		for k, i := range batch {
+			results[i] = rs[k]
+			opts.emit(i, rs[k])
+		}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	if opts.emit == nil {
+		select {
+		case <-done:
+		case <-ctx.Done():
+		}
+	} else {
+		ticker := time.NewTicker(streamWindow)
+	wait:
+		for {
+			select {
+			case <-done:
+				break wait
+			case <-ctx.Done():
+				break wait
+			case <-ticker.C:
+				flush(ctx)
 			}
-			results[i] = result
-		}(i, t)
+		}
+		ticker.Stop()
+	}
+
+	// The targets fetched in time are still enriched, within a grace
+	// period of their own rather than with the expired context.
+	enrichCtx := ctx
+	if ctx.Err() != nil {
+		var cancel context.CancelFunc
+		enrichCtx, cancel = context.WithTimeout(context.Background(), enrichGrace)
+		defer cancel()
+	}
+	if opts.emit != nil {
+		flush(enrichCtx)
 	}
-	wg.Wait()
 
-	// Output results.
+	mu.Lock()
+	for i, ok := range fetched {
+		if !ok {
+			results[i] = provider.Result{
+				Target: targets[i].String(),
+				Error:  fmt.Sprintf("timed out after %s", timeout),
+			}
+			if opts.emit != nil {
+				opts.emit(i, results[i])
+			}
+		}
+	}
+	mu.Unlock()
+
+	if opts.emit == nil {
+		enrich(enrichCtx, registry, opts, results)
+	}
+	return results
+}
+
+// enrich adds the vulnerabilities and source repositories requested by
+// opts to results.
+func enrich(ctx context.Context, registry *provider.Registry, opts fetchOptions, results []provider.Result) {
+	if opts.vulns != nil {
+		opts.vulns.Enrich(ctx, results)
+	}
+	if opts.withSource {
+		attachSources(ctx, registry, results)
+	}
+}
+
+// report writes results with formatter and returns an error when any
+// target failed, so the process exits non-zero.
+func report(stdout io.Writer, formatter func(io.Writer, []provider.Result) error, results []provider.Result) error {
 	if err := formatter(stdout, results); err != nil {
 		return fmt.Errorf("formatting output: %w", err)
 	}
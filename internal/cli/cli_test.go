@@ -2,8 +2,23 @@ package cli
 
 import (
 	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
+
+	"github.com/yutakobayashidev/repiq/internal/cache"
+	"github.com/yutakobayashidev/repiq/internal/config"
+	"github.com/yutakobayashidev/repiq/internal/httpclient"
+	"github.com/yutakobayashidev/repiq/internal/osv"
+	"github.com/yutakobayashidev/repiq/internal/provider"
 )
 
 func TestRunNoArgs(t *testing.T) {
@@ -55,6 +70,14 @@ func TestRunUnknownScheme(t *testing.T) {
 	}
 }
 
+func TestRunInvalidConcurrency(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	err := Run([]string{"--concurrency", "0", "unknown:x"}, &stdout, &stderr)
+	if err == nil || !strings.Contains(err.Error(), "--concurrency") {
+		t.Errorf("got %v, want a --concurrency error", err)
+	}
+}
+
 func TestRunMultipleTargets(t *testing.T) {
 	var stdout, stderr bytes.Buffer
 	err := Run([]string{"unknown:a", "unknown:b"}, &stdout, &stderr)
@@ -89,3 +112,613 @@ func TestRunMultipleFormatFlagsNoError(t *testing.T) {
 		t.Errorf("format flags should not be exclusive, got: %v", err)
 	}
 }
+
+func TestRunScanNoArgs(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	err := Run([]string{"scan"}, &stdout, &stderr)
+	if err == nil {
+		t.Fatal("expected error for scan without manifests")
+	}
+	if !strings.Contains(stderr.String(), "Usage: repiq scan") {
+		t.Errorf("expected scan usage in stderr, got: %q", stderr.String())
+	}
+}
+
+func TestRunScanUnsupportedManifest(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	err := Run([]string{"scan", "Gemfile"}, &stdout, &stderr)
+	if err == nil {
+		t.Fatal("expected error for unsupported manifest")
+	}
+	if !strings.Contains(err.Error(), "unsupported manifest") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestRunScanEmptyManifest(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "package.json")
+	if err := os.WriteFile(path, []byte(`{"name":"empty"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var stdout, stderr bytes.Buffer
+	if err := Run([]string{"scan", path}, &stdout, &stderr); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(stderr.String(), "no dependencies found") {
+		t.Errorf("expected notice in stderr, got: %q", stderr.String())
+	}
+}
+
+func TestRunCheckNoArgs(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	err := Run([]string{"check"}, &stdout, &stderr)
+	if err == nil {
+		t.Fatal("expected error for no targets")
+	}
+	if !strings.Contains(stderr.String(), "Usage: repiq check") {
+		t.Errorf("expected check usage in stderr, got: %q", stderr.String())
+	}
+}
+
+func TestRunCheckMissingPolicy(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	missing := filepath.Join(t.TempDir(), "repiq-policy.yaml")
+	err := Run([]string{"check", "--policy", missing, "npm:react"}, &stdout, &stderr)
+	if err == nil {
+		t.Fatal("expected error for missing policy file")
+	}
+}
+
+func TestRunCheckInvalidPolicy(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "repiq-policy.yaml")
+	if err := os.WriteFile(path, []byte("rules:\n  - field: no_such_field\n    max: 1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var stdout, stderr bytes.Buffer
+	err := Run([]string{"check", "--policy", path, "npm:react"}, &stdout, &stderr)
+	if err == nil || !strings.Contains(err.Error(), "no_such_field") {
+		t.Fatalf("expected unknown field error, got %v", err)
+	}
+}
+
+func TestRunCheckUnknownScheme(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "repiq-policy.yaml")
+	if err := os.WriteFile(path, []byte("rules:\n  - field: stars\n    min: 1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var stdout, stderr bytes.Buffer
+	err := Run([]string{"check", "--policy", path, "unknown:thing"}, &stdout, &stderr)
+	if err == nil || !strings.Contains(err.Error(), "unknown scheme") {
+		t.Fatalf("expected unknown scheme error, got %v", err)
+	}
+}
+
+func TestExitError(t *testing.T) {
+	inner := errors.New("2 targets violate the policy")
+	var err error = &ExitError{Code: ExitPolicyViolation, Err: inner}
+	if err.Error() != inner.Error() {
+		t.Errorf("got %q, want %q", err.Error(), inner.Error())
+	}
+	if !errors.Is(err, inner) {
+		t.Error("ExitError should unwrap to the inner error")
+	}
+}
+
+// fakeGitHub counts fetches and returns fixed metrics for every repository.
+type fakeGitHub struct {
+	mu      sync.Mutex
+	fetches map[string]int
+}
+
+func (f *fakeGitHub) Scheme() string { return "github" }
+
+func (f *fakeGitHub) Fetch(_ context.Context, id string) (provider.Result, error) {
+	f.mu.Lock()
+	f.fetches[id]++
+	f.mu.Unlock()
+	if id == "gone/repo" {
+		return provider.Result{}, errors.New("GitHub API: 404 Not Found")
+	}
+	return provider.Result{Target: "github:" + id, GitHub: &provider.GitHubMetrics{Stars: 42}}, nil
+}
+
+func TestAttachSources(t *testing.T) {
+	gh := &fakeGitHub{fetches: make(map[string]int)}
+	registry := provider.NewRegistry()
+	registry.Register(gh)
+
+	results := []provider.Result{
+		{Target: "npm:react", NPM: &provider.NPMMetrics{SourceRepo: "https://github.com/facebook/react"}},
+		{Target: "npm:react-dom", NPM: &provider.NPMMetrics{SourceRepo: "https://github.com/facebook/react"}},
+		{Target: "crates:serde", Crates: &provider.CratesMetrics{SourceRepo: "https://github.com/serde-rs/serde"}},
+		{Target: "github:serde-rs/serde", GitHub: &provider.GitHubMetrics{Stars: 7}},
+		{Target: "pypi:gitlab-only", PyPI: &provider.PyPIMetrics{SourceRepo: "https://gitlab.com/group/project"}},
+		{Target: "go:example.com/gone", Go: &provider.GoMetrics{SourceRepo: "https://github.com/gone/repo"}},
+		{Target: "npm:missing", Error: "npm registry: 404 Not Found"},
+	}
+	attachSources(context.Background(), registry, results)
+
+	if gh.fetches["facebook/react"] != 1 {
+		t.Errorf("facebook/react fetched %d times, want 1", gh.fetches["facebook/react"])
+	}
+	if gh.fetches["serde-rs/serde"] != 0 {
+		t.Error("serde-rs/serde was already a target and should not be refetched")
+	}
+	for _, i := range []int{0, 1} {
+		if src := results[i].Source; src == nil || src.GitHub == nil || src.GitHub.Stars != 42 {
+			t.Errorf("%s: got source %+v", results[i].Target, src)
+		}
+	}
+	if src := results[2].Source; src == nil || src.GitHub.Stars != 7 {
+		t.Errorf("crates:serde: got source %+v, want the github: target's result", src)
+	}
+	if results[3].Source != nil || results[4].Source != nil || results[6].Source != nil {
+		t.Error("only registry results with a GitHub source_repo should get a source")
+	}
+	if src := results[5].Source; src == nil || src.Target != "github:gone/repo" || src.Error == "" {
+		t.Errorf("go:example.com/gone: got source %+v, want an error result", src)
+	}
+	if results[5].Error != "" {
+		t.Errorf("source failure should not fail the package, got %q", results[5].Error)
+	}
+}
+
+// stuckProvider returns "fast" right away and blocks on anything else
+// until unblock is closed, ignoring cancellation. Its results are npm
+// packages when npm is set.
+type stuckProvider struct {
+	unblock chan struct{}
+	npm     bool
+}
+
+func (stuckProvider) Scheme() string { return "stuck" }
+
+func (p stuckProvider) Fetch(_ context.Context, id string) (provider.Result, error) {
+	if id != "fast" {
+		<-p.unblock
+	}
+	if p.npm {
+		return provider.Result{Target: "stuck:" + id, NPM: &provider.NPMMetrics{LatestVersion: "1.0.0"}}, nil
+	}
+	return provider.Result{Target: "stuck:" + id, GitHub: &provider.GitHubMetrics{Stars: 1}}, nil
+}
+
+func TestFetchAllTimeout(t *testing.T) {
+	p := stuckProvider{unblock: make(chan struct{})}
+	defer close(p.unblock)
+	registry := provider.NewRegistry()
+	registry.Register(p)
+
+	targets := []provider.Target{{Scheme: "stuck", Identifier: "fast"}, {Scheme: "stuck", Identifier: "slow"}}
+	start := time.Now()
+	results := fetchAll(registry, fetchOptions{timeout: 50 * time.Millisecond}, targets)
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("fetchAll took %v, should return at the deadline", elapsed)
+	}
+
+	if results[0].GitHub == nil || results[0].Error != "" {
+		t.Errorf("finished target should keep its result, got %+v", results[0])
+	}
+	if results[1].Target != "stuck:slow" || results[1].Error != "timed out after 50ms" {
+		t.Errorf("unfinished target: got %+v", results[1])
+	}
+}
+
+// readOnlyStore returns a cache store that cannot be written to: the
+// fetches stuckProvider blocks finish after the test has returned, when a
+// temporary directory would already be removed.
+func readOnlyStore() *cache.Store {
+	return cache.NewStore(filepath.Join(os.DevNull, "repiq"), time.Hour)
+}
+
+func TestFetchAllTimeoutCached(t *testing.T) {
+	p := stuckProvider{unblock: make(chan struct{})}
+	defer close(p.unblock)
+	registry := provider.NewRegistry()
+	registry.Register(cache.NewProvider(p, readOnlyStore(), false))
+
+	targets := []provider.Target{{Scheme: "stuck", Identifier: "fast"}, {Scheme: "stuck", Identifier: "slow"}}
+	results := fetchAll(registry, fetchOptions{timeout: 50 * time.Millisecond}, targets)
+
+	if results[0].GitHub == nil || results[0].Error != "" {
+		t.Errorf("finished target should keep its result through the cache, got %+v", results[0])
+	}
+	if results[1].Error != "timed out after 50ms" {
+		t.Errorf("unfinished target: got %+v", results[1])
+	}
+}
+
+func TestFetchAllTimeoutEnrich(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1/querybatch", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"results":[{"vulns":[{"id":"GHSA-test"}]}]}`))
+	})
+	mux.HandleFunc("GET /v1/vulns/GHSA-test", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"id":"GHSA-test","database_specific":{"severity":"HIGH"}}`))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	p := stuckProvider{unblock: make(chan struct{}), npm: true}
+	defer close(p.unblock)
+	registry := provider.NewRegistry()
+	registry.Register(p)
+
+	targets := []provider.Target{{Scheme: "stuck", Identifier: "fast"}, {Scheme: "stuck", Identifier: "slow"}}
+	opts := fetchOptions{timeout: 50 * time.Millisecond, vulns: osv.New(srv.URL)}
+	results := fetchAll(registry, opts, targets)
+
+	if results[0].Error != "" || results[0].NPM == nil || results[0].NPM.OpenVulns != 1 {
+		t.Errorf("a target fetched in time should still be enriched, got %+v", results[0])
+	}
+	if results[1].Error != "timed out after 50ms" {
+		t.Errorf("unfinished target: got %q", results[1].Error)
+	}
+}
+
+func TestFetchAllEmit(t *testing.T) {
+	p := stuckProvider{unblock: make(chan struct{})}
+	defer close(p.unblock)
+	registry := provider.NewRegistry()
+	registry.Register(p)
+
+	var order []int
+	var emitted []provider.Result
+	opts := fetchOptions{
+		timeout: 50 * time.Millisecond,
+		emit: func(i int, r provider.Result) {
+			order = append(order, i)
+			emitted = append(emitted, r)
+		},
+	}
+	targets := []provider.Target{{Scheme: "stuck", Identifier: "slow"}, {Scheme: "stuck", Identifier: "fast"}}
+	fetchAll(registry, opts, targets)
+
+	if len(order) != 2 || order[0] != 1 || order[1] != 0 {
+		t.Fatalf("got emit order %v, want the finished target first, then the timed out one", order)
+	}
+	if emitted[0].GitHub == nil || emitted[1].Error != "timed out after 50ms" {
+		t.Errorf("got %+v", emitted)
+	}
+}
+
+func TestFetchAllEmitBatchesVulns(t *testing.T) {
+	var queries atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		queries.Add(1)
+		_, _ = w.Write([]byte(`{"results":[{},{},{}]}`))
+	}))
+	defer srv.Close()
+
+	p := stuckProvider{unblock: make(chan struct{}), npm: true}
+	close(p.unblock)
+	registry := provider.NewRegistry()
+	registry.Register(p)
+
+	var emitted int
+	opts := fetchOptions{
+		timeout: 5 * time.Second,
+		vulns:   osv.New(srv.URL),
+		emit:    func(int, provider.Result) { emitted++ },
+	}
+	targets := []provider.Target{{Scheme: "stuck", Identifier: "a"}, {Scheme: "stuck", Identifier: "b"}, {Scheme: "stuck", Identifier: "c"}}
+	fetchAll(registry, opts, targets)
+
+	if emitted != 3 {
+		t.Errorf("got %d results emitted, want 3", emitted)
+	}
+	if n := queries.Load(); n != 1 {
+		t.Errorf("got %d OSV queries, want the streamed results batched into 1", n)
+	}
+}
+
+func TestFetchAllEmitCached(t *testing.T) {
+	p := stuckProvider{unblock: make(chan struct{})}
+	var once sync.Once
+	unblock := func() { once.Do(func() { close(p.unblock) }) }
+	defer unblock()
+	registry := provider.NewRegistry()
+	registry.Register(cache.NewProvider(p, readOnlyStore(), false))
+
+	// The slow target only finishes once the fast one has been emitted,
+	// which a cache layer waiting for the whole scheme would never do.
+	var emitted []provider.Result
+	opts := fetchOptions{
+		timeout: 5 * time.Second,
+		emit: func(_ int, r provider.Result) {
+			emitted = append(emitted, r)
+			if r.Target == "stuck:fast" {
+				unblock()
+			}
+		},
+	}
+	targets := []provider.Target{{Scheme: "stuck", Identifier: "slow"}, {Scheme: "stuck", Identifier: "fast"}}
+	fetchAll(registry, opts, targets)
+
+	if len(emitted) != 2 || emitted[0].Target != "stuck:fast" || emitted[0].Error != "" || emitted[1].Error != "" {
+		t.Errorf("each target should be emitted as soon as it is fetched, got %+v", emitted)
+	}
+}
+
+func TestRunInvalidTimeout(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	err := Run([]string{"--timeout", "0s", "unknown:x"}, &stdout, &stderr)
+	if err == nil || !strings.Contains(err.Error(), "--timeout") {
+		t.Errorf("got %v, want a --timeout error", err)
+	}
+}
+
+func TestRunHistoryNoArgs(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if err := Run([]string{"history"}, &stdout, &stderr); err == nil {
+		t.Fatal("expected error for no target")
+	}
+	if !strings.Contains(stderr.String(), "Usage: repiq history") {
+		t.Errorf("expected history usage in stderr, got: %q", stderr.String())
+	}
+}
+
+func TestRunHistoryInvalidSince(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	err := Run([]string{"history", "--since", "soon", "npm:react"}, &stdout, &stderr)
+	if err == nil || !strings.Contains(err.Error(), "invalid --since") {
+		t.Fatalf("expected --since error, got %v", err)
+	}
+}
+
+func TestRunHistory(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	var stdout, stderr bytes.Buffer
+	if err := Run([]string{"history", "npm:react"}, &stdout, &stderr); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(stderr.String(), "no snapshots of npm:react") {
+		t.Errorf("expected empty-history note, got: %q", stderr.String())
+	}
+
+	recordHistory(&stderr, []provider.Result{
+		{Target: "npm:react", NPM: &provider.NPMMetrics{WeeklyDownloads: 1234}},
+	})
+	stdout.Reset()
+	if err := Run([]string{"history", "--csv", "npm:react"}, &stdout, &stderr); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(stdout.String(), "date,weekly_downloads,") || !strings.Contains(stdout.String(), ",1234,") {
+		t.Errorf("unexpected CSV output: %q", stdout.String())
+	}
+}
+
+func TestRunDiffArgs(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if err := Run([]string{"diff", "only-one.json"}, &stdout, &stderr); err == nil {
+		t.Fatal("expected error for a single file")
+	}
+	if !strings.Contains(stderr.String(), "Usage: repiq diff") {
+		t.Errorf("expected diff usage in stderr, got: %q", stderr.String())
+	}
+}
+
+func TestRunDiff(t *testing.T) {
+	dir := t.TempDir()
+	before := filepath.Join(dir, "before.json")
+	after := filepath.Join(dir, "after.ndjson")
+	if err := os.WriteFile(before, []byte(`[{"target":"npm:react","npm":{"license":"MIT"}}]`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(after, []byte(`{"target":"npm:react","npm":{"license":"ISC"}}`+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if err := Run([]string{"diff", before, after}, &stdout, &stderr); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(stdout.String(), "| npm:react | license | MIT | ISC |") {
+		t.Errorf("unexpected output:\n%s", stdout.String())
+	}
+}
+
+func TestWriteHTTPStats(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	hosts := []httpclient.HostStats{
+		{
+			Host:     "api.github.com",
+			Requests: 12,
+			Retries:  1,
+			Waited:   2100 * time.Millisecond,
+			Quotas: map[string]httpclient.Quota{
+				"core":   {Limit: 5000, Remaining: 4988, Reset: now.Add(52 * time.Minute)},
+				"search": {Limit: 30, Remaining: 28, Reset: now.Add(40 * time.Second)},
+			},
+		},
+		{Host: "registry.npmjs.org", Requests: 3},
+	}
+	var buf bytes.Buffer
+	writeHTTPStats(&buf, hosts, now)
+	want := "api.github.com: 12 requests; 1 retries (waited 2.1s); core 4988/5000 remaining, resets in 52m0s; search 28/30 remaining, resets in 40s\n" +
+		"registry.npmjs.org: 3 requests\n"
+	if buf.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
+
+// writeConfig points REPIQ_CONFIG at a user file with content.
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("REPIQ_CONFIG", path)
+	return path
+}
+
+func TestRunConfigTargets(t *testing.T) {
+	writeConfig(t, `targets = ["unknown:configured"]`)
+	var stdout, stderr bytes.Buffer
+	err := Run([]string{}, &stdout, &stderr)
+	if err == nil || !strings.Contains(err.Error(), `"unknown:configured"`) {
+		t.Errorf("got %v, want the configured target to be used", err)
+	}
+}
+
+func TestRunInvalidConfig(t *testing.T) {
+	path := writeConfig(t, `format = "yaml"`)
+	var stdout, stderr bytes.Buffer
+	err := Run([]string{"npm:react"}, &stdout, &stderr)
+	if err == nil || !strings.Contains(err.Error(), path) {
+		t.Errorf("got %v, want an error naming %s", err, path)
+	}
+}
+
+func TestRunConfigShow(t *testing.T) {
+	path := writeConfig(t, "concurrency = 3\n")
+	t.Setenv("REPIQ_FORMAT", "ndjson")
+	var stdout, stderr bytes.Buffer
+	if err := Run([]string{"config", "show"}, &stdout, &stderr); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := stdout.String()
+	for _, want := range []string{
+		"| concurrency | 3 | " + path + " |",
+		"| format | ndjson | $REPIQ_FORMAT |",
+		"| cache.ttl | 24h0m0s | default |",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestRunConfigNoSubcommand(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if err := Run([]string{"config"}, &stdout, &stderr); err == nil {
+		t.Error("expected error without a subcommand")
+	}
+}
+
+func TestNewRegistryUnsupportedRegistry(t *testing.T) {
+	cfg := config.Default()
+	cfg.Registries["github"] = config.Registry{URL: "https://ghe.example.com"}
+	if _, err := newRegistry(cfg, true, newTestTransport()); err == nil || !strings.Contains(err.Error(), `"github"`) {
+		t.Errorf("got %v, want an unsupported scheme error", err)
+	}
+}
+
+func TestNewRegistryHeaders(t *testing.T) {
+	cfg := config.Default()
+	cfg.Registries["npm"] = config.Registry{
+		URL:     "https://npm.example.com/repository/npm/",
+		Headers: map[string]string{"authorization": "Basic dXNlcjpwYXNz"},
+	}
+	cfg.Registries["go"] = config.Registry{
+		URL:     "https://athens.example.com|https://proxy.golang.org",
+		Headers: map[string]string{"X-Api-Key": "key"},
+	}
+	cfg.Tokens["pypi.example.com"] = "secret"
+	transport := newTestTransport()
+	if _, err := newRegistry(cfg, true, transport); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if httpclient.Default.Headers != nil {
+		t.Error("the shared default transport must not be changed")
+	}
+
+	h := transport.Headers
+	if got := h["npm.example.com"].Get("Authorization"); got != "Basic dXNlcjpwYXNz" {
+		t.Errorf("npm: got %q", got)
+	}
+	if got := h["athens.example.com"].Get("X-Api-Key"); got != "key" {
+		t.Errorf("go: got %q", got)
+	}
+	if _, ok := h["proxy.golang.org"]; ok {
+		t.Error("headers must not be sent to the public fallback proxy")
+	}
+	if got := h["pypi.example.com"].Get("Authorization"); got != "Bearer secret" {
+		t.Errorf("token: got %q", got)
+	}
+}
+
+func TestNewRegistryInvalidRegistries(t *testing.T) {
+	tests := []struct {
+		scheme string
+		r      config.Registry
+		want   string
+	}{
+		{"crates", config.Registry{URL: "https://crates.example.com", StatsURL: "https://stats.example.com"}, "crates has no stats_url"},
+		{"npm", config.Registry{Headers: map[string]string{"Authorization": "x"}}, "npm has headers but no url"},
+	}
+	for _, tt := range tests {
+		cfg := config.Default()
+		cfg.Registries[tt.scheme] = tt.r
+		if _, err := newRegistry(cfg, true, newTestTransport()); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: got %v, want %q", tt.scheme, err, tt.want)
+		}
+	}
+}
+
+// fetchRecordingHeader fetches id from the scheme's provider, configured to
+// use a test server, and returns the values of header sent to it.
+func fetchRecordingHeader(t *testing.T, scheme, id, header string) []string {
+	t.Helper()
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	var mu sync.Mutex
+	var got []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		got = append(got, r.Header.Get(header))
+		mu.Unlock()
+		http.NotFound(w, r)
+	}))
+	defer srv.Close()
+
+	cfg := config.Default()
+	cfg.Registries[scheme] = config.Registry{URL: srv.URL}
+	transport := newTestTransport()
+	registry, err := newRegistry(cfg, true, transport)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	p, ok := registry.Lookup(scheme)
+	if !ok {
+		t.Fatalf("no %s provider", scheme)
+	}
+	if _, err := p.Fetch(context.Background(), id); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) == 0 {
+		t.Fatal("expected a request to the test server")
+	}
+	if hosts := transport.Stats.Hosts(); len(hosts) == 0 {
+		t.Error("requests should go through the transport given to newRegistry")
+	}
+	return got
+}
+
+// newTestTransport returns a transport with the default limits.
+func newTestTransport() *httpclient.Transport {
+	return httpclient.NewTransport(httpclient.DefaultConcurrency, httpclient.DefaultRequestTimeout)
+}
+
+func TestNewRegistryGitLabToken(t *testing.T) {
+	t.Setenv("GITLAB_TOKEN", "public-token")
+	for _, got := range fetchRecordingHeader(t, "gitlab", "group/project", "PRIVATE-TOKEN") {
+		if got != "" {
+			t.Errorf("a configured registry must not receive GITLAB_TOKEN: got %q", got)
+		}
+	}
+}
+
+func TestNewRegistryGiteaToken(t *testing.T) {
+	t.Setenv("CODEBERG_TOKEN", "codeberg-token")
+	t.Setenv("GITEA_TOKEN", "gitea-token")
+	for _, scheme := range []string{"codeberg", "gitea"} {
+		for _, got := range fetchRecordingHeader(t, scheme, "owner/repo", "Authorization") {
+			if got != "" {
+				t.Errorf("%s: a configured registry must not receive an unscoped token: got %q", scheme, got)
+			}
+		}
+	}
+}
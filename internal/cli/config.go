@@ -0,0 +1,61 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/yutakobayashidev/repiq/internal/format"
+)
+
+// runConfig prints the effective configuration.
+func runConfig(args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("repiq config", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	jsonFlag := fs.Bool("json", false, "output as JSON")
+	fs.Bool("markdown", false, "output as Markdown table (default)")
+
+	fs.Usage = func() {
+		_, _ = io.WriteString(stderr, `Usage: repiq config show [flags]
+
+Print every setting with its effective value and where it comes from:
+the user file (~/.config/repiq/config.toml, or $REPIQ_CONFIG), the nearest
+.repiq.toml, a REPIQ_* environment variable, or the built-in default.
+Command-line flags override all of them. Tokens are masked.
+
+Examples:
+  repiq config show
+  repiq config show --json
+
+Flags:
+`)
+		fs.PrintDefaults()
+	}
+
+	if len(args) == 0 || args[0] != "show" {
+		fs.Usage()
+		return fmt.Errorf("expected a subcommand: show")
+	}
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		fs.Usage()
+		return fmt.Errorf("unexpected arguments: %v", fs.Args())
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	formatter := format.ConfigMarkdown
+	if *jsonFlag {
+		formatter = format.ConfigJSON
+	}
+	if err := formatter(stdout, cfg.Settings()); err != nil {
+		return fmt.Errorf("formatting output: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/yutakobayashidev/repiq/internal/diff"
+	"github.com/yutakobayashidev/repiq/internal/format"
+)
+
+// runDiff compares two saved result sets.
+func runDiff(args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("repiq diff", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	jsonFlag := fs.Bool("json", false, "output as JSON")
+	fs.Bool("markdown", false, "output as Markdown table (default)")
+
+	fs.Usage = func() {
+		_, _ = io.WriteString(stderr, `Usage: repiq diff [flags] <old.json> <new.json>
+
+Compare two result sets saved with --json or --ndjson. Targets are matched
+by name; added and removed targets are listed, followed by every field whose
+value changed, with numeric deltas and major/minor/patch version bumps.
+
+Examples:
+  repiq --json npm:react npm:vue > before.json
+  repiq diff before.json after.json
+  repiq diff --json before.ndjson after.ndjson
+
+Flags:
+`)
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return fmt.Errorf("expected two result files")
+	}
+	older, err := diff.Load(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	newer, err := diff.Load(fs.Arg(1))
+	if err != nil {
+		return err
+	}
+
+	formatter := format.DiffMarkdown
+	if *jsonFlag {
+		formatter = format.DiffJSON
+	}
+	if err := formatter(stdout, diff.Compare(older, newer)); err != nil {
+		return fmt.Errorf("formatting output: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/yutakobayashidev/repiq/internal/format"
+	"github.com/yutakobayashidev/repiq/internal/history"
+	"github.com/yutakobayashidev/repiq/internal/provider"
+)
+
+// runHistory reports how a target's metrics changed across the snapshots
+// recorded with --record.
+func runHistory(args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("repiq history", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	since := fs.String("since", "90d", "show snapshots since a duration ago (90d, 12w, 6m, 1y) or a date (2025-01-31)")
+	jsonFlag := fs.Bool("json", false, "output as JSON")
+	csvFlag := fs.Bool("csv", false, "output as CSV")
+	fs.Bool("markdown", false, "output as Markdown table (default)")
+
+	fs.Usage = func() {
+		_, _ = io.WriteString(stderr, `Usage: repiq history [flags] <scheme>:<identifier>
+
+Show how a target's metrics changed over time. Snapshots are recorded, at
+most one per target per day, by passing --record to repiq, repiq scan, or
+repiq check, e.g. from a daily cron job.
+
+Examples:
+  repiq --record github:facebook/react npm:react
+  repiq history github:facebook/react
+  repiq history --since 6m --csv npm:react
+
+Flags:
+`)
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return fmt.Errorf("expected exactly one target")
+	}
+	target, err := provider.ParseTarget(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	from, err := history.ParseSince(*since, time.Now())
+	if err != nil {
+		return err
+	}
+
+	dir, err := history.DefaultDir()
+	if err != nil {
+		return err
+	}
+	snaps, err := history.NewStore(dir).Load(target.String(), from)
+	if err != nil {
+		return fmt.Errorf("reading history: %w", err)
+	}
+	if len(snaps) == 0 {
+		_, _ = fmt.Fprintf(stderr, "no snapshots of %s since %s; record some with repiq --record %s\n",
+			target, from.Format(time.DateOnly), target)
+		return nil
+	}
+
+	formatter := format.HistoryMarkdown
+	if *csvFlag {
+		formatter = format.HistoryCSV
+	}
+	if *jsonFlag {
+		formatter = format.HistoryJSON
+	}
+	if err := formatter(stdout, history.NewTrend(target.String(), snaps)); err != nil {
+		return fmt.Errorf("formatting output: %w", err)
+	}
+	return nil
+}
+
+// recordHistory appends a snapshot of results to the history store. A
+// failure to record does not fail the run, which already has its results.
+func recordHistory(stderr io.Writer, results []provider.Result) {
+	dir, err := history.DefaultDir()
+	if err == nil {
+		err = history.NewStore(dir).Record(results, time.Now())
+	}
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "warning: recording history: %v\n", err)
+	}
+}
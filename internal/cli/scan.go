@@ -0,0 +1,136 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/yutakobayashidev/repiq/internal/lockfile"
+	"github.com/yutakobayashidev/repiq/internal/manifest"
+	"github.com/yutakobayashidev/repiq/internal/provider"
+)
+
+// runScan reads package manifests and lockfiles and fetches metrics for
+// every dependency they declare or resolve.
+func runScan(args []string, stdout, stderr io.Writer) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	fs := flag.NewFlagSet("repiq scan", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	out := addOutputFlags(fs, cfg)
+
+	fs.Usage = func() {
+		_, _ = io.WriteString(stderr, `Usage: repiq scan [flags] <manifest|lockfile> [...]
+
+Fetch metrics for the dependencies declared in package manifests, or for
+the full resolved dependency set (direct and transitive) in lockfiles.
+
+Supported manifests:
+  package.json       npm
+  go.mod             go
+  Cargo.toml         crates
+  requirements*.txt  pypi
+  pyproject.toml     pypi
+
+Supported lockfiles:
+  package-lock.json, pnpm-lock.yaml, yarn.lock   npm
+  go.sum                                         go
+  Cargo.lock                                     crates
+  poetry.lock, uv.lock                           pypi
+
+Examples:
+  repiq scan package.json
+  repiq scan --json go.mod Cargo.toml pyproject.toml
+  repiq scan package-lock.json
+  repiq scan --with-source package.json
+
+Flags:
+`)
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := out.validate(); err != nil {
+		return err
+	}
+
+	paths := fs.Args()
+	if len(paths) == 0 {
+		fs.Usage()
+		return fmt.Errorf("no manifests specified")
+	}
+
+	targets, deps, err := collectTargets(paths)
+	if err != nil {
+		return err
+	}
+
+	if len(targets) == 0 {
+		_, _ = fmt.Fprintln(stderr, "no dependencies found")
+		return nil
+	}
+
+	registry, err := out.registry()
+	if err != nil {
+		return err
+	}
+	opts, formatter := out.output(stdout)
+	if emit := opts.emit; emit != nil {
+		opts.emit = func(i int, r provider.Result) {
+			r.Dependency = deps[targets[i]]
+			emit(i, r)
+		}
+	}
+	results := fetchAll(registry, opts, targets)
+	for i, t := range targets {
+		results[i].Dependency = deps[t]
+	}
+	out.finish(stderr, results)
+	return report(stdout, formatter, results)
+}
+
+// collectTargets reads every manifest and lockfile in paths and returns the
+// targets they name, without duplicates. Lockfile entries carry how the
+// package is reached; when a target appears in several files, a direct
+// relation wins over a transitive one.
+func collectTargets(paths []string) ([]provider.Target, map[provider.Target]*provider.DependencyInfo, error) {
+	var targets []provider.Target
+	deps := make(map[provider.Target]*provider.DependencyInfo)
+	seen := make(map[provider.Target]bool)
+	add := func(t provider.Target, info *provider.DependencyInfo) {
+		if !seen[t] {
+			seen[t] = true
+			targets = append(targets, t)
+		}
+		if info != nil && (deps[t] == nil || (info.Direct && !deps[t].Direct)) {
+			deps[t] = info
+		}
+	}
+
+	for _, path := range paths {
+		if lockfile.Supported(path) {
+			pkgs, err := lockfile.Parse(path)
+			if err != nil {
+				return nil, nil, err
+			}
+			for _, p := range pkgs {
+				add(p.Target, &provider.DependencyInfo{Direct: p.Direct, Path: p.Path})
+			}
+			continue
+		}
+		found, err := manifest.Parse(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, t := range found {
+			add(t, nil)
+		}
+	}
+	return targets, deps, nil
+}
@@ -0,0 +1,53 @@
+package cli
+
+import (
+	"context"
+
+	"github.com/yutakobayashidev/repiq/internal/provider"
+)
+
+// attachSources nests the GitHub metrics of each registry package's source
+// repository in its result. Repositories already fetched as github: targets
+// in this run are reused; every other repository is fetched once, however
+// many packages share it, in a single batch.
+func attachSources(ctx context.Context, registry *provider.Registry, results []provider.Result) {
+	gh, ok := registry.Lookup("github")
+	if !ok {
+		return
+	}
+
+	fetched := make(map[string]*provider.Result)
+	for i := range results {
+		if results[i].GitHub != nil {
+			if t, err := provider.ParseTarget(results[i].Target); err == nil {
+				fetched[t.Identifier] = &results[i]
+			}
+		}
+	}
+
+	repos := make(map[int]string)
+	var missing []string
+	for i, r := range results {
+		_, metrics := r.Metrics()
+		url, _ := metrics["source_repo"].(string)
+		repo, ok := provider.GitHubRepo(url)
+		if !ok {
+			continue
+		}
+		repos[i] = repo
+		if _, ok := fetched[repo]; !ok {
+			fetched[repo] = nil
+			missing = append(missing, repo)
+		}
+	}
+
+	for j, res := range provider.FetchMany(ctx, gh, missing) {
+		fetched[missing[j]] = &res
+	}
+
+	for i, repo := range repos {
+		src := *fetched[repo]
+		src.Dependency = nil
+		results[i].Source = &src
+	}
+}
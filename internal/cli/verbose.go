@@ -0,0 +1,40 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/yutakobayashidev/repiq/internal/httpclient"
+)
+
+// writeHTTPStats writes one line per API host contacted: the number of
+// requests and retries, and the remaining quota the API reported.
+func writeHTTPStats(w io.Writer, hosts []httpclient.HostStats, now time.Time) {
+	for _, h := range hosts {
+		parts := []string{fmt.Sprintf("%d requests", h.Requests)}
+		if h.Retries > 0 {
+			parts = append(parts, fmt.Sprintf("%d retries (waited %s)", h.Retries, h.Waited.Round(100*time.Millisecond)))
+		}
+
+		resources := make([]string, 0, len(h.Quotas))
+		for r := range h.Quotas {
+			resources = append(resources, r)
+		}
+		sort.Strings(resources)
+		for _, r := range resources {
+			q := h.Quotas[r]
+			quota := fmt.Sprintf("%d/%d remaining", q.Remaining, q.Limit)
+			if r != "" {
+				quota = r + " " + quota
+			}
+			if !q.Reset.IsZero() {
+				quota += fmt.Sprintf(", resets in %s", max(q.Reset.Sub(now), 0).Round(time.Second))
+			}
+			parts = append(parts, quota)
+		}
+		_, _ = fmt.Fprintf(w, "%s: %s\n", h.Host, strings.Join(parts, "; "))
+	}
+}
@@ -0,0 +1,519 @@
+// Package config loads repiq's layered configuration: built-in defaults,
+// then the user file, then the project file, then REPIQ_* environment
+// variables. Command-line flags override the result.
+//
+// Both files are TOML:
+//
+//	format = "json"
+//	concurrency = 8
+//	timeout = "2m"
+//	request_timeout = "30s"
+//	targets = ["github:facebook/react", "npm:react"]
+//
+//	[cache]
+//	ttl = "24h"
+//
+//	[cache.schemes]
+//	npm = "6h"
+//
+//	[registries]
+//	pypi = "https://pypi.example.com"
+//
+//	[registries.npm]
+//	url = "https://npm.example.com"
+//	stats_url = "https://api.npmjs.org"
+//	headers = { Authorization = "Basic ..." }
+//
+//	[tokens]
+//	"api.github.com" = "ghp_..."
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yutakobayashidev/repiq/internal/httpclient"
+	"github.com/yutakobayashidev/repiq/internal/toml"
+)
+
+// ProjectFile is the name of the project file, looked up in the working
+// directory and its parents.
+const ProjectFile = ".repiq.toml"
+
+// Defaults of the settings that have no flag of their own elsewhere.
+const (
+	DefaultFormat   = "markdown"
+	DefaultTimeout  = 30 * time.Second
+	DefaultCacheTTL = 24 * time.Hour
+)
+
+// SourceDefault is the source of a setting no layer sets.
+const SourceDefault = "default"
+
+// Config is the effective configuration.
+type Config struct {
+	// Format is the output format when no format flag is given: markdown,
+	// json or ndjson.
+	Format         string
+	Concurrency    int
+	Timeout        time.Duration
+	RequestTimeout time.Duration
+	// CacheTTL is how long cached results are served; SchemeTTL overrides
+	// it per scheme.
+	CacheTTL  time.Duration
+	SchemeTTL map[string]time.Duration
+	// Registries maps a scheme to its registry API.
+	Registries map[string]Registry
+	// Tokens maps an API host to the token sent to it.
+	Tokens map[string]string
+	// Targets are fetched when repiq is run without targets.
+	Targets []string
+
+	// sources maps each setting's key, as in Settings, to the file or
+	// environment variable that set it.
+	sources map[string]string
+	// userPath is the user file, when Load read one.
+	userPath string
+}
+
+// Default returns the built-in configuration.
+func Default() *Config {
+	return &Config{
+		Format:         DefaultFormat,
+		Concurrency:    httpclient.DefaultConcurrency,
+		Timeout:        DefaultTimeout,
+		RequestTimeout: httpclient.DefaultRequestTimeout,
+		CacheTTL:       DefaultCacheTTL,
+		SchemeTTL:      make(map[string]time.Duration),
+		Registries:     make(map[string]Registry),
+		Tokens:         make(map[string]string),
+		sources:        make(map[string]string),
+	}
+}
+
+// Registry is a registry API endpoint.
+type Registry struct {
+	// URL is the base URL of the registry API.
+	URL string
+	// StatsURL is the base URL of the download statistics API that goes
+	// with the registry, for schemes that have one.
+	StatsURL string
+	// Headers are sent with every request to the host of URL, e.g. to
+	// authenticate with a private registry.
+	Headers map[string]string
+}
+
+// TTL returns the cache TTL of scheme.
+func (c *Config) TTL(scheme string) time.Duration {
+	if ttl, ok := c.SchemeTTL[scheme]; ok {
+		return ttl
+	}
+	return c.CacheTTL
+}
+
+// Load returns the configuration layered from the user file, the project
+// file found from cwd, and the environment read with getenv. Missing files
+// are skipped.
+func Load(cwd string, getenv func(string) string) (*Config, error) {
+	c := Default()
+	var paths []string
+	userPath, err := UserPath(getenv)
+	if err == nil {
+		paths = append(paths, userPath)
+	}
+	if path, ok := FindProject(cwd); ok {
+		paths = append(paths, path)
+	}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err := c.merge(data, path); err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", path, err)
+		}
+		if path == userPath {
+			c.userPath = path
+		}
+	}
+	if err := c.mergeEnv(getenv); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// UserTokenHosts returns the hosts given a token in the user file, which
+// unlike a project file cannot come with a cloned repository.
+func (c *Config) UserTokenHosts() []string {
+	var hosts []string
+	for host := range c.Tokens {
+		if c.userPath != "" && c.sources["tokens."+host] == c.userPath {
+			hosts = append(hosts, host)
+		}
+	}
+	sort.Strings(hosts)
+	return hosts
+}
+
+// UserPath returns the user file: $REPIQ_CONFIG, or config.toml in
+// $XDG_CONFIG_HOME/repiq or ~/.config/repiq.
+func UserPath(getenv func(string) string) (string, error) {
+	if path := getenv("REPIQ_CONFIG"); path != "" {
+		return path, nil
+	}
+	if dir := getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "repiq", "config.toml"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", "repiq", "config.toml"), nil
+}
+
+// FindProject returns the nearest ProjectFile in dir or its parents.
+func FindProject(dir string) (string, bool) {
+	for {
+		path := filepath.Join(dir, ProjectFile)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path, true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+// Parse returns the built-in configuration overridden by the TOML document
+// data.
+func Parse(data []byte) (*Config, error) {
+	c := Default()
+	if err := c.merge(data, "config"); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *Config) merge(data []byte, source string) error {
+	doc, err := toml.Parse(data)
+	if err != nil {
+		return err
+	}
+	for key, v := range doc {
+		var err error
+		switch key {
+		case "format":
+			err = c.set(key, source, v, c.setFormat)
+		case "concurrency":
+			err = c.set(key, source, v, c.setConcurrency)
+		case "timeout":
+			err = c.set(key, source, v, durationSetter(&c.Timeout))
+		case "request_timeout":
+			err = c.set(key, source, v, durationSetter(&c.RequestTimeout))
+		case "targets":
+			err = c.set(key, source, v, c.setTargets)
+		case "cache":
+			err = c.mergeCache(v, source)
+		case "registries":
+			err = c.mergeRegistries(v, source)
+		case "tokens":
+			err = c.mergeMap(key, v, source, func(host, token string) error {
+				c.Tokens[host] = token
+				return nil
+			})
+		default:
+			err = fmt.Errorf("unknown key")
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+func (c *Config) mergeRegistries(v any, source string) error {
+	table, ok := v.(map[string]any)
+	if !ok {
+		return fmt.Errorf("must be a table")
+	}
+	for scheme, raw := range table {
+		if err := c.mergeRegistry(scheme, raw, source); err != nil {
+			return fmt.Errorf("%s: %w", scheme, err)
+		}
+	}
+	return nil
+}
+
+// mergeRegistry merges a registry given as a URL or as a table of url,
+// stats_url and headers.
+func (c *Config) mergeRegistry(scheme string, v any, source string) error {
+	r := c.Registries[scheme]
+	prefix := "registries." + scheme
+	if url, ok := v.(string); ok {
+		v = map[string]any{"url": url}
+	}
+	table, ok := v.(map[string]any)
+	if !ok {
+		return fmt.Errorf("must be a URL or a table")
+	}
+	for key, v := range table {
+		var err error
+		switch key {
+		case "url":
+			err = c.set(prefix+".url", source, v, urlSetter(&r.URL))
+		case "stats_url":
+			err = c.set(prefix+".stats_url", source, v, urlSetter(&r.StatsURL))
+		case "headers":
+			headers := make(map[string]string, len(r.Headers))
+			for k, v := range r.Headers {
+				headers[k] = v
+			}
+			err = c.mergeMap(prefix+".headers", v, source, func(name, value string) error {
+				headers[name] = value
+				return nil
+			})
+			r.Headers = headers
+		default:
+			err = fmt.Errorf("unknown key")
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+	}
+	c.Registries[scheme] = r
+	return nil
+}
+
+func urlSetter(u *string) func(any) error {
+	return func(v any) error {
+		s, _ := v.(string)
+		if !strings.HasPrefix(s, "http://") && !strings.HasPrefix(s, "https://") {
+			return fmt.Errorf("must be an http or https URL")
+		}
+		*u = s
+		return nil
+	}
+}
+
+func (c *Config) mergeCache(v any, source string) error {
+	table, ok := v.(map[string]any)
+	if !ok {
+		return fmt.Errorf("must be a table")
+	}
+	for key, v := range table {
+		var err error
+		switch key {
+		case "ttl":
+			err = c.set("cache.ttl", source, v, durationSetter(&c.CacheTTL))
+		case "schemes":
+			err = c.mergeMap("cache.schemes", v, source, func(scheme, s string) error {
+				d, err := parseDuration(s)
+				if err != nil {
+					return err
+				}
+				c.SchemeTTL[scheme] = d
+				return nil
+			})
+		default:
+			err = fmt.Errorf("unknown key")
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// mergeMap applies set to every string value of the table v, recording
+// each under prefix.name.
+func (c *Config) mergeMap(prefix string, v any, source string, set func(name, value string) error) error {
+	table, ok := v.(map[string]any)
+	if !ok {
+		return fmt.Errorf("must be a table")
+	}
+	for name, raw := range table {
+		s, ok := raw.(string)
+		if !ok || s == "" {
+			return fmt.Errorf("%s: must be a non-empty string", name)
+		}
+		if err := set(name, s); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		c.sources[prefix+"."+name] = source
+	}
+	return nil
+}
+
+func (c *Config) set(key, source string, v any, setter func(any) error) error {
+	if err := setter(v); err != nil {
+		return err
+	}
+	c.sources[key] = source
+	return nil
+}
+
+func (c *Config) setFormat(v any) error {
+	s, _ := v.(string)
+	switch s {
+	case "markdown", "json", "ndjson":
+		c.Format = s
+		return nil
+	}
+	return fmt.Errorf("must be markdown, json or ndjson")
+}
+
+func (c *Config) setConcurrency(v any) error {
+	var n int64
+	switch x := v.(type) {
+	case int64:
+		n = x
+	case string:
+		var err error
+		if n, err = strconv.ParseInt(x, 10, 0); err != nil {
+			return fmt.Errorf("must be an integer")
+		}
+	default:
+		return fmt.Errorf("must be an integer")
+	}
+	if n < 1 {
+		return fmt.Errorf("must be at least 1")
+	}
+	c.Concurrency = int(n)
+	return nil
+}
+
+func (c *Config) setTargets(v any) error {
+	switch x := v.(type) {
+	case string:
+		c.Targets = strings.Fields(x)
+		return nil
+	case []any:
+		targets := make([]string, 0, len(x))
+		for _, item := range x {
+			s, ok := item.(string)
+			if !ok || s == "" {
+				return fmt.Errorf("must be a list of strings")
+			}
+			targets = append(targets, s)
+		}
+		c.Targets = targets
+		return nil
+	}
+	return fmt.Errorf("must be a list of strings")
+}
+
+func durationSetter(d *time.Duration) func(any) error {
+	return func(v any) error {
+		s, ok := v.(string)
+		if !ok {
+			return fmt.Errorf("must be a duration string such as \"30s\"")
+		}
+		parsed, err := parseDuration(s)
+		if err != nil {
+			return err
+		}
+		*d = parsed
+		return nil
+	}
+}
+
+func parseDuration(s string) (time.Duration, error) {
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("must be a positive duration such as \"30s\", got %q", s)
+	}
+	return d, nil
+}
+
+// envVars maps each environment variable to the setting it overrides.
+var envVars = []struct {
+	name, key string
+	set       func(c *Config) func(any) error
+}{
+	{"REPIQ_FORMAT", "format", func(c *Config) func(any) error { return c.setFormat }},
+	{"REPIQ_CONCURRENCY", "concurrency", func(c *Config) func(any) error { return c.setConcurrency }},
+	{"REPIQ_TIMEOUT", "timeout", func(c *Config) func(any) error { return durationSetter(&c.Timeout) }},
+	{"REPIQ_REQUEST_TIMEOUT", "request_timeout", func(c *Config) func(any) error { return durationSetter(&c.RequestTimeout) }},
+	{"REPIQ_CACHE_TTL", "cache.ttl", func(c *Config) func(any) error { return durationSetter(&c.CacheTTL) }},
+	{"REPIQ_TARGETS", "targets", func(c *Config) func(any) error { return c.setTargets }},
+}
+
+func (c *Config) mergeEnv(getenv func(string) string) error {
+	for _, e := range envVars {
+		v := getenv(e.name)
+		if v == "" {
+			continue
+		}
+		if err := c.set(e.key, "$"+e.name, v, e.set(c)); err != nil {
+			return fmt.Errorf("%s: %w", e.name, err)
+		}
+	}
+	return nil
+}
+
+// Setting is one effective setting, for display.
+type Setting struct {
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+	Source string `json:"source"`
+}
+
+// Settings lists every setting with its value and source, sorted by key.
+// Tokens and registry headers are masked.
+func (c *Config) Settings() []Setting {
+	values := map[string]string{
+		"format":          c.Format,
+		"concurrency":     strconv.Itoa(c.Concurrency),
+		"timeout":         c.Timeout.String(),
+		"request_timeout": c.RequestTimeout.String(),
+		"cache.ttl":       c.CacheTTL.String(),
+		"targets":         strings.Join(c.Targets, " "),
+	}
+	for scheme, ttl := range c.SchemeTTL {
+		values["cache.schemes."+scheme] = ttl.String()
+	}
+	for scheme, r := range c.Registries {
+		prefix := "registries." + scheme
+		if r.URL != "" {
+			values[prefix+".url"] = r.URL
+		}
+		if r.StatsURL != "" {
+			values[prefix+".stats_url"] = r.StatsURL
+		}
+		for name, value := range r.Headers {
+			values[prefix+".headers."+name] = mask(value)
+		}
+	}
+	for host, token := range c.Tokens {
+		values["tokens."+host] = mask(token)
+	}
+
+	settings := make([]Setting, 0, len(values))
+	for key, value := range values {
+		source, ok := c.sources[key]
+		if !ok {
+			source = SourceDefault
+		}
+		settings = append(settings, Setting{Key: key, Value: value, Source: source})
+	}
+	sort.Slice(settings, func(i, j int) bool { return settings[i].Key < settings[j].Key })
+	return settings
+}
+
+// mask hides all but the last four characters of a token.
+func mask(token string) string {
+	if len(token) <= 8 {
+		return "****"
+	}
+	return "****" + token[len(token)-4:]
+}
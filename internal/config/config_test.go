@@ -0,0 +1,174 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	c, err := Parse([]byte(`
+format = "json"
+concurrency = 4
+timeout = "2m"
+request_timeout = "45s"
+targets = ["github:facebook/react", "npm:react"]
+
+[cache]
+ttl = "12h"
+
+[cache.schemes]
+npm = "1h"
+
+[registries]
+pypi = "https://pypi.example.com"
+
+[registries.npm]
+url = "https://npm.example.com"
+stats_url = "https://downloads.example.com"
+headers = { Authorization = "Basic dXNlcjpwYXNz" }
+
+[tokens]
+"npm.example.com" = "secret-token"
+`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if c.Format != "json" || c.Concurrency != 4 || c.Timeout != 2*time.Minute || c.RequestTimeout != 45*time.Second {
+		t.Errorf("got %+v", c)
+	}
+	if len(c.Targets) != 2 || c.Targets[1] != "npm:react" {
+		t.Errorf("targets: got %v", c.Targets)
+	}
+	if c.TTL("npm") != time.Hour || c.TTL("pypi") != 12*time.Hour {
+		t.Errorf("ttl: got npm %v, pypi %v", c.TTL("npm"), c.TTL("pypi"))
+	}
+	npm := c.Registries["npm"]
+	if npm.URL != "https://npm.example.com" || npm.StatsURL != "https://downloads.example.com" || npm.Headers["Authorization"] != "Basic dXNlcjpwYXNz" {
+		t.Errorf("npm registry: got %+v", npm)
+	}
+	if c.Registries["pypi"].URL != "https://pypi.example.com" || c.Tokens["npm.example.com"] != "secret-token" {
+		t.Errorf("got registries %v, tokens %v", c.Registries, c.Tokens)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		doc, want string
+	}{
+		{`colour = "red"`, "colour: unknown key"},
+		{`format = "yaml"`, "format: must be markdown, json or ndjson"},
+		{`concurrency = 0`, "concurrency: must be at least 1"},
+		{`timeout = 30`, "timeout: must be a duration"},
+		{`timeout = "-1s"`, "timeout: must be a positive duration"},
+		{"[cache]\nttl = \"soon\"", "cache: ttl: must be a positive duration"},
+		{"[registries]\nnpm = \"npm.example.com\"", "registries: npm: url: must be an http or https URL"},
+		{"[tokens]\n\"example.com\" = 1", "tokens: example.com: must be a non-empty string"},
+		{"[registries.npm]\nmirror = \"https://npm.example.com\"", "registries: npm: mirror: unknown key"},
+		{"[registries]\nnpm = 1", "registries: npm: must be a URL or a table"},
+		{`targets = [1]`, "targets: must be a list of strings"},
+	}
+	for _, tt := range tests {
+		_, err := Parse([]byte(tt.doc))
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Parse(%q): got %v, want %q", tt.doc, err, tt.want)
+		}
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadLayers(t *testing.T) {
+	dir := t.TempDir()
+	userPath := filepath.Join(dir, "user", "config.toml")
+	writeFile(t, userPath, "format = \"json\"\nconcurrency = 4\n[cache]\nttl = \"1h\"\n[tokens]\n\"ghe.example.com\" = \"user-token\"\n")
+	projectPath := filepath.Join(dir, "project", ProjectFile)
+	writeFile(t, projectPath, "concurrency = 2\ntimeout = \"1m\"\n[tokens]\n\"evil.example.com\" = \"project-token\"\n")
+	cwd := filepath.Join(dir, "project", "sub", "dir")
+	if err := os.MkdirAll(cwd, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	env := map[string]string{
+		"REPIQ_CONFIG":  userPath,
+		"REPIQ_TIMEOUT": "90s",
+	}
+	c, err := Load(cwd, func(k string) string { return env[k] })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := map[string][2]string{
+		"format":          {"json", userPath},
+		"concurrency":     {"2", projectPath},
+		"timeout":         {"1m30s", "$REPIQ_TIMEOUT"},
+		"cache.ttl":       {"1h0m0s", userPath},
+		"request_timeout": {"20s", SourceDefault},
+	}
+	for _, s := range c.Settings() {
+		w, ok := want[s.Key]
+		if !ok {
+			continue
+		}
+		if s.Value != w[0] || s.Source != w[1] {
+			t.Errorf("%s: got %q from %q, want %q from %q", s.Key, s.Value, s.Source, w[0], w[1])
+		}
+		delete(want, s.Key)
+	}
+	if len(want) != 0 {
+		t.Errorf("settings missing: %v", want)
+	}
+	if hosts := c.UserTokenHosts(); len(hosts) != 1 || hosts[0] != "ghe.example.com" {
+		t.Errorf("user token hosts: got %v, want only the user file's", hosts)
+	}
+}
+
+func TestLoadNoFiles(t *testing.T) {
+	dir := t.TempDir()
+	env := map[string]string{"REPIQ_CONFIG": filepath.Join(dir, "missing.toml")}
+	c, err := Load(dir, func(k string) string { return env[k] })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if c.Format != DefaultFormat || c.CacheTTL != DefaultCacheTTL {
+		t.Errorf("got %+v, want the defaults", c)
+	}
+}
+
+func TestLoadInvalidEnv(t *testing.T) {
+	dir := t.TempDir()
+	env := map[string]string{
+		"REPIQ_CONFIG":      filepath.Join(dir, "missing.toml"),
+		"REPIQ_CONCURRENCY": "many",
+	}
+	_, err := Load(dir, func(k string) string { return env[k] })
+	if err == nil || !strings.Contains(err.Error(), "REPIQ_CONCURRENCY") {
+		t.Errorf("got %v, want a REPIQ_CONCURRENCY error", err)
+	}
+}
+
+func TestSettingsMasksTokens(t *testing.T) {
+	c, err := Parse([]byte("[tokens]\n\"api.github.com\" = \"ghp_abcdefghijklmnop\"\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, s := range c.Settings() {
+		if s.Key == "tokens.api.github.com" {
+			if s.Value != "****mnop" {
+				t.Errorf("got %q, want ****mnop", s.Value)
+			}
+			return
+		}
+	}
+	t.Error("token setting missing")
+}
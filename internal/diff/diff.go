@@ -0,0 +1,258 @@
+// Package diff compares two result sets, such as --json output saved from
+// two CI runs, and reports what changed per target.
+package diff
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/yutakobayashidev/repiq/internal/provider"
+)
+
+// Operations of a Change, named after JSON Patch.
+const (
+	OpAdd     = "add"
+	OpRemove  = "remove"
+	OpReplace = "replace"
+)
+
+// Change is a field whose value differs between the two runs.
+type Change struct {
+	Target string `json:"target"`
+	// Field is the metrics field name, prefixed with "source." for the
+	// nested source repository, or "error".
+	Field string `json:"field"`
+	Op    string `json:"op"`
+	Old   any    `json:"old,omitempty"`
+	New   any    `json:"new,omitempty"`
+	// Delta is New - Old for numeric fields.
+	Delta *float64 `json:"delta,omitempty"`
+	// Bump is "major", "minor" or "patch" when a version field changed.
+	Bump string `json:"bump,omitempty"`
+}
+
+// Report is the difference between two result sets.
+type Report struct {
+	Added   []string `json:"added"`
+	Removed []string `json:"removed"`
+	Changes []Change `json:"changes"`
+}
+
+// ChangedTargets returns the targets with at least one change, in report
+// order.
+func (r Report) ChangedTargets() []string {
+	var out []string
+	seen := make(map[string]bool)
+	for _, c := range r.Changes {
+		if !seen[c.Target] {
+			seen[c.Target] = true
+			out = append(out, c.Target)
+		}
+	}
+	return out
+}
+
+// Load reads results written by format.JSON or format.NDJSON.
+func Load(path string) ([]provider.Result, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	results, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return results, nil
+}
+
+// Parse decodes a JSON array of results or newline-delimited results.
+func Parse(data []byte) ([]provider.Result, error) {
+	trimmed := bytes.TrimSpace(data)
+	if bytes.HasPrefix(trimmed, []byte("[")) {
+		var results []provider.Result
+		if err := json.Unmarshal(trimmed, &results); err != nil {
+			return nil, err
+		}
+		return results, nil
+	}
+
+	var results []provider.Result
+	dec := json.NewDecoder(bytes.NewReader(trimmed))
+	for {
+		var r provider.Result
+		err := dec.Decode(&r)
+		if errors.Is(err, io.EOF) {
+			return results, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, r)
+	}
+}
+
+// Compare matches results by target and reports targets only in newer as
+// added, targets only in older as removed, and every field that changed
+// for targets in both. Changes follow the order of newer, then field name.
+func Compare(older, newer []provider.Result) Report {
+	rep := Report{Added: []string{}, Removed: []string{}, Changes: []Change{}}
+
+	oldByTarget := make(map[string]provider.Result, len(older))
+	for _, r := range older {
+		if _, ok := oldByTarget[r.Target]; !ok {
+			oldByTarget[r.Target] = r
+		}
+	}
+	inNew := make(map[string]bool, len(newer))
+	for _, r := range newer {
+		if inNew[r.Target] {
+			continue
+		}
+		inNew[r.Target] = true
+		o, ok := oldByTarget[r.Target]
+		if !ok {
+			rep.Added = append(rep.Added, r.Target)
+			continue
+		}
+		rep.Changes = append(rep.Changes, compareResult(r.Target, o, r)...)
+	}
+	for _, r := range older {
+		if !inNew[r.Target] {
+			inNew[r.Target] = true
+			rep.Removed = append(rep.Removed, r.Target)
+		}
+	}
+	return rep
+}
+
+func compareResult(target string, o, n provider.Result) []Change {
+	changes := compareMetrics(target, "", o, n)
+	if o.Source != nil || n.Source != nil {
+		var oldSrc, newSrc provider.Result
+		if o.Source != nil {
+			oldSrc = *o.Source
+		}
+		if n.Source != nil {
+			newSrc = *n.Source
+		}
+		changes = append(changes, compareMetrics(target, "source.", oldSrc, newSrc)...)
+	}
+	return changes
+}
+
+func compareMetrics(target, prefix string, o, n provider.Result) []Change {
+	_, oldFields := o.Metrics()
+	_, newFields := n.Metrics()
+
+	names := make(map[string]bool)
+	for f := range oldFields {
+		names[f] = true
+	}
+	for f := range newFields {
+		names[f] = true
+	}
+	sorted := make([]string, 0, len(names))
+	for f := range names {
+		sorted = append(sorted, f)
+	}
+	sort.Strings(sorted)
+
+	var changes []Change
+	for _, f := range sorted {
+		ov, inOld := oldFields[f]
+		nv, inNew := newFields[f]
+		if c, ok := compareField(target, prefix+f, ov, inOld, nv, inNew); ok {
+			changes = append(changes, c)
+		}
+	}
+	if c, ok := compareField(target, prefix+"error", o.Error, o.Error != "", n.Error, n.Error != ""); ok {
+		changes = append(changes, c)
+	}
+	return changes
+}
+
+func compareField(target, field string, ov any, inOld bool, nv any, inNew bool) (Change, bool) {
+	c := Change{Target: target, Field: field}
+	switch {
+	case !inOld && !inNew:
+		return c, false
+	case !inOld:
+		c.Op, c.New = OpAdd, value(nv)
+		return c, true
+	case !inNew:
+		c.Op, c.Old = OpRemove, value(ov)
+		return c, true
+	}
+
+	ov, nv = value(ov), value(nv)
+	if reflect.DeepEqual(ov, nv) {
+		return c, false
+	}
+	c.Op, c.Old, c.New = OpReplace, ov, nv
+	if a, ok := ov.(float64); ok {
+		if b, ok := nv.(float64); ok {
+			d := b - a
+			c.Delta = &d
+		}
+	}
+	if a, ok := ov.(string); ok && strings.HasSuffix(field, "version") {
+		if b, ok := nv.(string); ok {
+			c.Bump = bump(a, b)
+		}
+	}
+	return c, true
+}
+
+// value normalizes a metrics field for comparison and output: numbers
+// become float64 and string lists are joined.
+func value(v any) any {
+	switch x := v.(type) {
+	case int:
+		return float64(x)
+	case []string:
+		return strings.Join(x, ", ")
+	}
+	return v
+}
+
+// bump classifies a version change as "major", "minor" or "patch" by the
+// first dot-separated numeric component that differs. It returns "" when
+// either version is not numeric, such as a pseudo-version prefix.
+func bump(older, newer string) string {
+	a, okA := versionParts(older)
+	b, okB := versionParts(newer)
+	if !okA || !okB {
+		return ""
+	}
+	for i, name := range []string{"major", "minor", "patch"} {
+		if a[i] != b[i] {
+			return name
+		}
+	}
+	return ""
+}
+
+func versionParts(v string) ([3]int, bool) {
+	var parts [3]int
+	v = strings.TrimPrefix(v, "v")
+	v, _, _ = strings.Cut(v, "+")
+	v, _, _ = strings.Cut(v, "-")
+	for i, f := range strings.Split(v, ".") {
+		n, err := strconv.Atoi(f)
+		if err != nil {
+			return parts, false
+		}
+		if i < len(parts) {
+			parts[i] = n
+		}
+	}
+	return parts, true
+}
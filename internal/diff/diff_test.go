@@ -0,0 +1,137 @@
+package diff
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/yutakobayashidev/repiq/internal/provider"
+)
+
+func TestParse(t *testing.T) {
+	array := `[
+  {"target": "npm:react", "npm": {"weekly_downloads": 10}},
+  {"target": "npm:gone", "error": "npm registry: 404 Not Found"}
+]`
+	ndjson := `{"target":"npm:react","npm":{"weekly_downloads":10}}
+{"target":"npm:gone","error":"npm registry: 404 Not Found"}
+`
+	for name, data := range map[string]string{"json": array, "ndjson": ndjson} {
+		t.Run(name, func(t *testing.T) {
+			results, err := Parse([]byte(data))
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			if len(results) != 2 || results[0].NPM == nil || results[0].NPM.WeeklyDownloads != 10 || results[1].Error == "" {
+				t.Errorf("got %+v", results)
+			}
+		})
+	}
+
+	if _, err := Parse([]byte(`{"target": `)); err == nil {
+		t.Error("expected error for truncated input")
+	}
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "results.json")
+	if err := os.WriteFile(path, []byte(`[]`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if results, err := Load(path); err != nil || len(results) != 0 {
+		t.Errorf("got (%v, %v)", results, err)
+	}
+	if _, err := Load(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("expected error for missing file")
+	}
+}
+
+func ptr(f float64) *float64 { return &f }
+
+func TestCompare(t *testing.T) {
+	older := []provider.Result{
+		{Target: "npm:react", NPM: &provider.NPMMetrics{WeeklyDownloads: 100, LatestVersion: "18.3.1", License: "MIT"}},
+		{Target: "github:o/r", GitHub: &provider.GitHubMetrics{Stars: 10, License: "MIT"}},
+		{Target: "npm:left-pad", NPM: &provider.NPMMetrics{}},
+		{Target: "npm:flaky", Error: "npm registry: 500 Internal Server Error"},
+	}
+	newer := []provider.Result{
+		{Target: "npm:react", NPM: &provider.NPMMetrics{WeeklyDownloads: 150, LatestVersion: "19.0.0", License: "MIT"}},
+		{Target: "github:o/r", GitHub: &provider.GitHubMetrics{Stars: 10, License: "Apache-2.0"}},
+		{Target: "npm:flaky", NPM: &provider.NPMMetrics{}},
+		{Target: "npm:zod", NPM: &provider.NPMMetrics{}},
+	}
+	rep := Compare(older, newer)
+
+	if !reflect.DeepEqual(rep.Added, []string{"npm:zod"}) {
+		t.Errorf("added: got %v", rep.Added)
+	}
+	if !reflect.DeepEqual(rep.Removed, []string{"npm:left-pad"}) {
+		t.Errorf("removed: got %v", rep.Removed)
+	}
+
+	byKey := make(map[string]Change)
+	for _, c := range rep.Changes {
+		byKey[c.Target+" "+c.Field] = c
+	}
+
+	if c := byKey["npm:react weekly_downloads"]; c.Op != OpReplace || c.Delta == nil || *c.Delta != 50 {
+		t.Errorf("weekly_downloads: got %+v", c)
+	}
+	if c := byKey["npm:react latest_version"]; c.Bump != "major" || c.Old != "18.3.1" || c.New != "19.0.0" {
+		t.Errorf("latest_version: got %+v", c)
+	}
+	if _, ok := byKey["npm:react license"]; ok {
+		t.Error("unchanged fields should not be reported")
+	}
+	if c := byKey["github:o/r license"]; c.Old != "MIT" || c.New != "Apache-2.0" || c.Delta != nil {
+		t.Errorf("license: got %+v", c)
+	}
+	if c := byKey["npm:flaky error"]; c.Op != OpRemove {
+		t.Errorf("error: got %+v", c)
+	}
+	if c := byKey["npm:flaky weekly_downloads"]; c.Op != OpAdd || c.New != float64(0) {
+		t.Errorf("fields of a recovered target should be added, got %+v", c)
+	}
+	if got := rep.ChangedTargets(); !reflect.DeepEqual(got, []string{"npm:react", "github:o/r", "npm:flaky"}) {
+		t.Errorf("changed targets: got %v", got)
+	}
+}
+
+func TestCompareSource(t *testing.T) {
+	older := []provider.Result{{
+		Target: "npm:react",
+		NPM:    &provider.NPMMetrics{},
+		Source: &provider.Result{Target: "github:facebook/react", GitHub: &provider.GitHubMetrics{Stars: 1}},
+	}}
+	newer := []provider.Result{{
+		Target: "npm:react",
+		NPM:    &provider.NPMMetrics{},
+		Source: &provider.Result{Target: "github:facebook/react", GitHub: &provider.GitHubMetrics{Stars: 3}},
+	}}
+	rep := Compare(older, newer)
+	want := []Change{{Target: "npm:react", Field: "source.stars", Op: OpReplace, Old: float64(1), New: float64(3), Delta: ptr(2)}}
+	if !reflect.DeepEqual(rep.Changes, want) {
+		t.Errorf("got %+v", rep.Changes)
+	}
+}
+
+func TestBump(t *testing.T) {
+	tests := []struct {
+		older, newer, want string
+	}{
+		{"1.2.3", "2.0.0", "major"},
+		{"v0.3.5", "v0.4.0", "minor"},
+		{"4.17.20", "4.17.21", "patch"},
+		{"1.0.0-rc.1", "1.0.0", ""},
+		{"2024.1.1.1", "2024.1.1.2", ""},
+		{"1.0", "1.1", "minor"},
+		{"abc", "1.0.0", ""},
+	}
+	for _, tt := range tests {
+		if got := bump(tt.older, tt.newer); got != tt.want {
+			t.Errorf("bump(%q, %q) = %q, want %q", tt.older, tt.newer, got, tt.want)
+		}
+	}
+}
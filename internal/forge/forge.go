@@ -0,0 +1,188 @@
+// Package forge is the REST API layer shared by the GitLab and Gitea
+// providers: authenticated GETs, paginated lists and counts, and the
+// parallel fetch of the metrics that take a request of their own.
+package forge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var nextLinkRe = regexp.MustCompile(`rel="next"`)
+
+// Dialect describes how the API of one kind of forge authenticates and
+// paginates.
+type Dialect struct {
+	// TokenHeader carries the token, after TokenPrefix.
+	TokenHeader string
+	TokenPrefix string
+	// LimitParam is the query parameter setting the number of items per
+	// page, PageSize of them when listing.
+	LimitParam string
+	PageSize   int
+	// TotalHeader is the response header with the number of items of a
+	// list, when the forge reports it.
+	TotalHeader string
+	// MaxPages bounds the pages Count walks when TotalHeader is missing;
+	// zero means no bound.
+	MaxPages int
+}
+
+// API is the REST API of one forge instance.
+type API struct {
+	Dialect
+	BaseURL string
+	Token   string
+	Client  *http.Client
+}
+
+// Get decodes the JSON response of a GET request into v and returns its
+// headers.
+func (c API) Get(ctx context.Context, path string, query url.Values, v any) (http.Header, error) {
+	u := c.BaseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", u, nil)
+	if err != nil {
+		return nil, err
+	}
+	if c.Token != "" {
+		req.Header.Set(c.TokenHeader, c.TokenPrefix+c.Token)
+	}
+
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%d %s", resp.StatusCode, http.StatusText(resp.StatusCode))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return nil, fmt.Errorf("decoding response: %w", err)
+	}
+	return resp.Header, nil
+}
+
+// Count returns the number of items of a list endpoint from its
+// TotalHeader. Forges omit the header for some lists; those are counted
+// page by page, up to MaxPages pages.
+func (c API) Count(ctx context.Context, path string, query url.Values) (int, error) {
+	query.Set(c.LimitParam, "1")
+	var items []json.RawMessage
+	h, err := c.Get(ctx, path, query, &items)
+	if err != nil {
+		return 0, err
+	}
+	if total, err := strconv.Atoi(h.Get(c.TotalHeader)); err == nil {
+		return total, nil
+	}
+	if !hasNext(h) {
+		return len(items), nil
+	}
+
+	count := 0
+	_, err = c.List(ctx, path, query, c.MaxPages, func(items []json.RawMessage) error {
+		count += len(items)
+		return nil
+	})
+	return count, err
+}
+
+// List calls fn with every page of a list endpoint, up to maxPages pages
+// when maxPages is positive, and reports whether pages were left out. The
+// last page is the one that reaches the TotalHeader or announces no next
+// page, as instances may serve fewer items per page than requested.
+func (c API) List(ctx context.Context, path string, query url.Values, maxPages int, fn func([]json.RawMessage) error) (bool, error) {
+	query.Set(c.LimitParam, strconv.Itoa(c.PageSize))
+	seen := 0
+	for page := 1; ; page++ {
+		query.Set("page", strconv.Itoa(page))
+		var items []json.RawMessage
+		h, err := c.Get(ctx, path, query, &items)
+		if err != nil {
+			return false, err
+		}
+		if err := fn(items); err != nil {
+			return false, err
+		}
+		seen += len(items)
+		if total, err := strconv.Atoi(h.Get(c.TotalHeader)); err == nil && seen >= total {
+			return false, nil
+		}
+		if len(items) == 0 || !hasNext(h) {
+			return false, nil
+		}
+		if page == maxPages {
+			return true, nil
+		}
+	}
+}
+
+// hasNext reports whether h announces a next page, in a Link header or in
+// GitLab's X-Next-Page.
+func hasNext(h http.Header) bool {
+	return h.Get("X-Next-Page") != "" || nextLinkRe.MatchString(strings.Join(h.Values("Link"), ","))
+}
+
+// ClosedSince counts the issues closed after since, up to maxPages pages
+// when maxPages is positive. Forges can only filter on the update time, so
+// query must select the closed issues updated since then, which are
+// checked one by one.
+func ClosedSince(ctx context.Context, c API, path string, query url.Values, since time.Time, maxPages int) (int, error) {
+	count := 0
+	_, err := c.List(ctx, path, query, maxPages, func(items []json.RawMessage) error {
+		for _, item := range items {
+			var issue struct {
+				ClosedAt *time.Time `json:"closed_at"`
+			}
+			if err := json.Unmarshal(item, &issue); err != nil {
+				return fmt.Errorf("decoding response: %w", err)
+			}
+			if issue.ClosedAt != nil && issue.ClosedAt.After(since) {
+				count++
+			}
+		}
+		return nil
+	})
+	return count, err
+}
+
+// DaysSince returns the whole days elapsed since t, zero for times in the
+// future.
+func DaysSince(t time.Time) int {
+	return max(int(math.Floor(time.Since(t).Hours()/24)), 0)
+}
+
+// Parallel runs jobs concurrently and returns the messages of the errors
+// they return. Each job must set metrics of its own.
+func Parallel(ctx context.Context, jobs ...func(context.Context) error) []string {
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var errs []string
+
+	wg.Add(len(jobs))
+	for _, job := range jobs {
+		go func(job func(context.Context) error) {
+			defer wg.Done()
+			if err := job(ctx); err != nil {
+				mu.Lock()
+				errs = append(errs, err.Error())
+				mu.Unlock()
+			}
+		}(job)
+	}
+	wg.Wait()
+	return errs
+}
@@ -0,0 +1,164 @@
+package forge
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+)
+
+var testDialect = Dialect{
+	TokenHeader: "Authorization",
+	TokenPrefix: "token ",
+	LimitParam:  "limit",
+	PageSize:    10,
+	TotalHeader: "X-Total-Count",
+	MaxPages:    3,
+}
+
+// newAPI serves a list of n items in pages of 5, with a next link on every
+// page but the last and the total when withTotal is set.
+func newAPI(t *testing.T, n int, withTotal bool) (API, *int) {
+	t.Helper()
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("Authorization") != "token secret" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		size, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		size = min(size, 5)
+		first := (max(page, 1) - 1) * size
+		items := make([]int, max(min(size, n-first), 0))
+		if first+len(items) < n {
+			w.Header().Set("Link", `<`+r.URL.Path+`?page=`+strconv.Itoa(page+1)+`>; rel="next"`)
+		}
+		if withTotal {
+			w.Header().Set("X-Total-Count", strconv.Itoa(n))
+		}
+		_ = json.NewEncoder(w).Encode(items)
+	}))
+	t.Cleanup(srv.Close)
+	return API{Dialect: testDialect, BaseURL: srv.URL, Token: "secret", Client: srv.Client()}, &requests
+}
+
+func TestList(t *testing.T) {
+	tests := []struct {
+		name      string
+		n         int
+		withTotal bool
+		maxPages  int
+		want      []int
+		capped    bool
+	}{
+		{name: "follows next links", n: 12, want: []int{5, 5, 2}},
+		{name: "stops at the total", n: 10, withTotal: true, want: []int{5, 5}},
+		{name: "empty", n: 0, want: []int{0}},
+		{name: "bounded", n: 12, maxPages: 2, want: []int{5, 5}, capped: true},
+		{name: "bound not reached", n: 10, maxPages: 2, want: []int{5, 5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := newAPI(t, tt.n, tt.withTotal)
+			var pages []int
+			capped, err := c.List(context.Background(), "/items", url.Values{}, tt.maxPages, func(items []json.RawMessage) error {
+				pages = append(pages, len(items))
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !slices.Equal(pages, tt.want) || capped != tt.capped {
+				t.Errorf("got pages %v (capped %v), want %v (capped %v)", pages, capped, tt.want, tt.capped)
+			}
+		})
+	}
+}
+
+func TestCount(t *testing.T) {
+	tests := []struct {
+		name         string
+		n            int
+		withTotal    bool
+		want         int
+		wantRequests int
+	}{
+		{name: "total header", n: 42, withTotal: true, want: 42, wantRequests: 1},
+		{name: "single page", n: 1, want: 1, wantRequests: 1},
+		{name: "counted page by page", n: 12, want: 12, wantRequests: 4},
+		{name: "bounded by MaxPages", n: 100, want: 15, wantRequests: 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, requests := newAPI(t, tt.n, tt.withTotal)
+			got, err := c.Count(context.Background(), "/items", url.Values{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want || *requests != tt.wantRequests {
+				t.Errorf("got %d in %d requests, want %d in %d", got, *requests, tt.want, tt.wantRequests)
+			}
+		})
+	}
+}
+
+func TestGetError(t *testing.T) {
+	c, _ := newAPI(t, 1, false)
+	c.Token = ""
+	var v any
+	if _, err := c.Get(context.Background(), "/items", nil, &v); err == nil || err.Error() != "401 Unauthorized" {
+		t.Errorf("got %v, want 401 Unauthorized", err)
+	}
+}
+
+func TestClosedSince(t *testing.T) {
+	since := time.Now().AddDate(0, 0, -30)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode([]map[string]any{
+			{"closed_at": since.Add(24 * time.Hour)},
+			{"closed_at": since.Add(-24 * time.Hour)},
+			{"closed_at": nil},
+		})
+	}))
+	defer srv.Close()
+
+	c := API{Dialect: testDialect, BaseURL: srv.URL, Client: srv.Client()}
+	got, err := ClosedSince(context.Background(), c, "/issues", url.Values{}, since, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != 1 {
+		t.Errorf("got %d, want 1", got)
+	}
+}
+
+func TestDaysSince(t *testing.T) {
+	if got := DaysSince(time.Now().Add(-49 * time.Hour)); got != 2 {
+		t.Errorf("got %d, want 2", got)
+	}
+	if got := DaysSince(time.Now().Add(time.Hour)); got != 0 {
+		t.Errorf("future time: got %d, want 0", got)
+	}
+}
+
+func TestParallel(t *testing.T) {
+	var a, b int
+	errs := Parallel(context.Background(),
+		func(context.Context) error { a = 1; return nil },
+		func(context.Context) error { b = 2; return errors.New("b: boom") },
+	)
+	if a != 1 || b != 2 {
+		t.Errorf("jobs did not run: a=%d b=%d", a, b)
+	}
+	if !slices.Equal(errs, []string{"b: boom"}) {
+		t.Errorf("got errors %v", errs)
+	}
+}
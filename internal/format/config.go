@@ -0,0 +1,37 @@
+package format
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/yutakobayashidev/repiq/internal/config"
+)
+
+// ConfigJSON writes settings as a JSON array.
+func ConfigJSON(w io.Writer, settings []config.Setting) error {
+	if settings == nil {
+		settings = []config.Setting{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(settings)
+}
+
+// ConfigMarkdown writes settings as a table of keys, values and the file
+// or environment variable each value comes from.
+func ConfigMarkdown(w io.Writer, settings []config.Setting) error {
+	if _, err := fmt.Fprintln(w, "| key | value | source |"); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintln(w, "|---|---|---|"); err != nil {
+		return err
+	}
+	for _, s := range settings {
+		if _, err := fmt.Fprintf(w, "| %s | %s | %s |\n",
+			escapeMarkdown(s.Key), escapeMarkdown(s.Value), escapeMarkdown(s.Source)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package format
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/yutakobayashidev/repiq/internal/config"
+)
+
+func TestConfigMarkdown(t *testing.T) {
+	settings := []config.Setting{
+		{Key: "concurrency", Value: "8", Source: "/home/u/.config/repiq/config.toml"},
+		{Key: "format", Value: "markdown", Source: config.SourceDefault},
+	}
+	var buf bytes.Buffer
+	if err := ConfigMarkdown(&buf, settings); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := `| key | value | source |
+|---|---|---|
+| concurrency | 8 | /home/u/.config/repiq/config.toml |
+| format | markdown | default |
+`
+	if buf.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
+
+func TestConfigJSONEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	if err := ConfigJSON(&buf, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.TrimSpace(buf.String()) != "[]" {
+		t.Errorf("got %s, want []", buf.String())
+	}
+}
@@ -0,0 +1,98 @@
+package format
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/yutakobayashidev/repiq/internal/diff"
+)
+
+// DiffJSON writes a diff report as a JSON object.
+func DiffJSON(w io.Writer, report diff.Report) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report)
+}
+
+// DiffMarkdown writes a diff report as a table of added and removed
+// targets, a table of changed fields and a one-line summary.
+func DiffMarkdown(w io.Writer, report diff.Report) error {
+	needSep := false
+
+	if len(report.Added) > 0 || len(report.Removed) > 0 {
+		if _, err := fmt.Fprintln(w, "| target | change |"); err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintln(w, "|---|---|"); err != nil {
+			return err
+		}
+		for _, t := range report.Added {
+			if _, err := fmt.Fprintf(w, "| %s | added |\n", escapeMarkdown(t)); err != nil {
+				return err
+			}
+		}
+		for _, t := range report.Removed {
+			if _, err := fmt.Fprintf(w, "| %s | removed |\n", escapeMarkdown(t)); err != nil {
+				return err
+			}
+		}
+		needSep = true
+	}
+
+	if len(report.Changes) > 0 {
+		if needSep {
+			if _, err := fmt.Fprintln(w); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintln(w, "| target | field | old | new | delta |"); err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintln(w, "|---|---|---|---|---|"); err != nil {
+			return err
+		}
+		for _, c := range report.Changes {
+			delta := c.Bump
+			if c.Delta != nil {
+				delta = formatNumber(*c.Delta)
+				if *c.Delta > 0 {
+					delta = "+" + delta
+				}
+			}
+			if _, err := fmt.Fprintf(w, "| %s | %s | %s | %s | %s |\n",
+				escapeMarkdown(c.Target),
+				escapeMarkdown(c.Field),
+				escapeMarkdown(diffValue(c.Old)),
+				escapeMarkdown(diffValue(c.New)),
+				delta,
+			); err != nil {
+				return err
+			}
+		}
+		needSep = true
+	}
+
+	if needSep {
+		if _, err := fmt.Fprintln(w); err != nil {
+			return err
+		}
+	}
+	summary := fmt.Sprintf("%d added, %d removed, %d changed.",
+		len(report.Added), len(report.Removed), len(report.ChangedTargets()))
+	if !needSep {
+		summary = "No changes."
+	}
+	_, err := fmt.Fprintln(w, summary)
+	return err
+}
+
+func diffValue(v any) string {
+	switch x := v.(type) {
+	case nil:
+		return ""
+	case float64:
+		return formatNumber(x)
+	}
+	return fmt.Sprint(v)
+}
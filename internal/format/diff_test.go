@@ -0,0 +1,72 @@
+package format
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/yutakobayashidev/repiq/internal/diff"
+)
+
+func TestDiffMarkdown(t *testing.T) {
+	delta := 50.0
+	report := diff.Report{
+		Added:   []string{"npm:zod"},
+		Removed: []string{"npm:left-pad"},
+		Changes: []diff.Change{
+			{Target: "npm:react", Field: "weekly_downloads", Op: diff.OpReplace, Old: 100.0, New: 150.0, Delta: &delta},
+			{Target: "npm:react", Field: "latest_version", Op: diff.OpReplace, Old: "18.3.1", New: "19.0.0", Bump: "major"},
+			{Target: "github:o/r", Field: "license", Op: diff.OpReplace, Old: "MIT", New: "Apache-2.0"},
+			{Target: "npm:flaky", Field: "error", Op: diff.OpRemove, Old: "a | b"},
+		},
+	}
+	var buf bytes.Buffer
+	if err := DiffMarkdown(&buf, report); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := `| target | change |
+|---|---|
+| npm:zod | added |
+| npm:left-pad | removed |
+
+| target | field | old | new | delta |
+|---|---|---|---|---|
+| npm:react | weekly_downloads | 100 | 150 | +50 |
+| npm:react | latest_version | 18.3.1 | 19.0.0 | major |
+| github:o/r | license | MIT | Apache-2.0 |  |
+| npm:flaky | error | a \| b |  |  |
+
+1 added, 1 removed, 3 changed.
+`
+	if buf.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
+
+func TestDiffMarkdownNoChanges(t *testing.T) {
+	var buf bytes.Buffer
+	if err := DiffMarkdown(&buf, diff.Report{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if buf.String() != "No changes.\n" {
+		t.Errorf("got %q", buf.String())
+	}
+}
+
+func TestDiffJSON(t *testing.T) {
+	var buf bytes.Buffer
+	report := diff.Report{Added: []string{}, Removed: []string{}, Changes: []diff.Change{
+		{Target: "npm:react", Field: "license", Op: diff.OpReplace, Old: "MIT", New: "ISC"},
+	}}
+	if err := DiffJSON(&buf, report); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var got map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	changes, _ := got["changes"].([]any)
+	if len(changes) != 1 || changes[0].(map[string]any)["op"] != "replace" {
+		t.Errorf("got %v", got)
+	}
+}
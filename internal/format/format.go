@@ -14,6 +14,15 @@ func escapeMarkdown(s string) string {
 	return strings.ReplaceAll(s, "|", "\\|")
 }
 
+// giteaContributors renders a Gitea contributor count, marked with a "+"
+// when it is only a lower bound.
+func giteaContributors(g *provider.GiteaMetrics) string {
+	if g.ContributorsCapped {
+		return strconv.Itoa(g.Contributors) + "+"
+	}
+	return strconv.Itoa(g.Contributors)
+}
+
 // JSON writes results as a JSON array.
 func JSON(w io.Writer, results []provider.Result) error {
 	if results == nil {
@@ -38,15 +47,37 @@ func NDJSON(w io.Writer, results []provider.Result) error {
 // Markdown writes results as a Markdown table grouped by scheme.
 func Markdown(w io.Writer, results []provider.Result) error {
 	var ghResults []provider.Result
+	var gitlabResults []provider.Result
+	var giteaResults []provider.Result
 	var npmResults []provider.Result
 	var pypiResults []provider.Result
 	var cratesResults []provider.Result
 	var goResults []provider.Result
+	var gemResults []provider.Result
+	var mavenResults []provider.Result
+	var nugetResults []provider.Result
+	var packagistResults []provider.Result
+	var hexResults []provider.Result
+	var pubResults []provider.Result
+	var dockerResults []provider.Result
+	var scorecardResults []provider.Result
 	var errResults []provider.Result
+	var depResults []provider.Result
+	var warnResults []provider.Result
 	for _, r := range results {
+		if r.Dependency != nil {
+			depResults = append(depResults, r)
+		}
+		if len(r.Warnings) > 0 {
+			warnResults = append(warnResults, r)
+		}
 		switch {
 		case r.GitHub != nil:
 			ghResults = append(ghResults, r)
+		case r.GitLab != nil:
+			gitlabResults = append(gitlabResults, r)
+		case r.Gitea != nil:
+			giteaResults = append(giteaResults, r)
 		case r.NPM != nil:
 			npmResults = append(npmResults, r)
 		case r.PyPI != nil:
@@ -55,11 +86,46 @@ func Markdown(w io.Writer, results []provider.Result) error {
 			cratesResults = append(cratesResults, r)
 		case r.Go != nil:
 			goResults = append(goResults, r)
+		case r.Gem != nil:
+			gemResults = append(gemResults, r)
+		case r.Maven != nil:
+			mavenResults = append(mavenResults, r)
+		case r.NuGet != nil:
+			nugetResults = append(nugetResults, r)
+		case r.Packagist != nil:
+			packagistResults = append(packagistResults, r)
+		case r.Hex != nil:
+			hexResults = append(hexResults, r)
+		case r.Pub != nil:
+			pubResults = append(pubResults, r)
+		case r.Docker != nil:
+			dockerResults = append(dockerResults, r)
+		case r.Scorecard != nil:
+			scorecardResults = append(scorecardResults, r)
 		default:
 			errResults = append(errResults, r)
 		}
 	}
 
+	// Source repositories fetched with --with-source join the GitHub table,
+	// once each and only when not already listed as targets.
+	listed := make(map[string]bool)
+	for _, r := range results {
+		listed[r.Target] = true
+	}
+	for _, r := range results {
+		src := r.Source
+		if src == nil || listed[src.Target] {
+			continue
+		}
+		listed[src.Target] = true
+		if src.GitHub != nil {
+			ghResults = append(ghResults, *src)
+		} else {
+			errResults = append(errResults, *src)
+		}
+	}
+
 	needSep := false
 
 	if len(ghResults) > 0 {
@@ -90,21 +156,87 @@ func Markdown(w io.Writer, results []provider.Result) error {
 		needSep = true
 	}
 
+	if len(gitlabResults) > 0 {
+		if needSep {
+			if _, err := fmt.Fprintln(w); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintln(w, "| target | stars | forks | open_issues | contributors | release_count | last_commit_days | commits_30d | issues_closed_30d | license | error |"); err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintln(w, "|---|---|---|---|---|---|---|---|---|---|---|"); err != nil {
+			return err
+		}
+		for _, r := range gitlabResults {
+			g := r.GitLab
+			if _, err := fmt.Fprintf(w, "| %s | %s | %s | %s | %s | %s | %s | %s | %s | %s | %s |\n",
+				escapeMarkdown(r.Target),
+				strconv.Itoa(g.Stars),
+				strconv.Itoa(g.Forks),
+				strconv.Itoa(g.OpenIssues),
+				strconv.Itoa(g.Contributors),
+				strconv.Itoa(g.ReleaseCount),
+				strconv.Itoa(g.LastCommitDays),
+				strconv.Itoa(g.Commits30d),
+				strconv.Itoa(g.IssuesClosed30d),
+				escapeMarkdown(g.License),
+				escapeMarkdown(r.Error),
+			); err != nil {
+				return err
+			}
+		}
+		needSep = true
+	}
+
+	if len(giteaResults) > 0 {
+		if needSep {
+			if _, err := fmt.Fprintln(w); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintln(w, "| target | stars | forks | open_issues | contributors | release_count | last_commit_days | commits_30d | issues_closed_30d | license | error |"); err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintln(w, "|---|---|---|---|---|---|---|---|---|---|---|"); err != nil {
+			return err
+		}
+		for _, r := range giteaResults {
+			g := r.Gitea
+			if _, err := fmt.Fprintf(w, "| %s | %s | %s | %s | %s | %s | %s | %s | %s | %s | %s |\n",
+				escapeMarkdown(r.Target),
+				strconv.Itoa(g.Stars),
+				strconv.Itoa(g.Forks),
+				strconv.Itoa(g.OpenIssues),
+				giteaContributors(g),
+				strconv.Itoa(g.ReleaseCount),
+				strconv.Itoa(g.LastCommitDays),
+				strconv.Itoa(g.Commits30d),
+				strconv.Itoa(g.IssuesClosed30d),
+				escapeMarkdown(g.License),
+				escapeMarkdown(r.Error),
+			); err != nil {
+				return err
+			}
+		}
+		needSep = true
+	}
+
 	if len(npmResults) > 0 {
 		if needSep {
 			if _, err := fmt.Fprintln(w); err != nil {
 				return err
 			}
 		}
-		if _, err := fmt.Fprintln(w, "| target | weekly_downloads | monthly_downloads | latest_version | last_publish_days | dependencies_count | license | error |"); err != nil {
+		if _, err := fmt.Fprintln(w, "| target | weekly_downloads | monthly_downloads | latest_version | last_publish_days | dependencies_count | license | source_repo | open_vulns | max_severity | advisory_ids | error |"); err != nil {
 			return err
 		}
-		if _, err := fmt.Fprintln(w, "|---|---|---|---|---|---|---|---|"); err != nil {
+		if _, err := fmt.Fprintln(w, "|---|---|---|---|---|---|---|---|---|---|---|---|"); err != nil {
 			return err
 		}
 		for _, r := range npmResults {
 			n := r.NPM
-			if _, err := fmt.Fprintf(w, "| %s | %s | %s | %s | %s | %s | %s | %s |\n",
+			if _, err := fmt.Fprintf(w, "| %s | %s | %s | %s | %s | %s | %s | %s | %s | %s | %s | %s |\n",
 				escapeMarkdown(r.Target),
 				strconv.Itoa(n.WeeklyDownloads),
 				strconv.Itoa(n.MonthlyDownloads),
@@ -112,6 +244,10 @@ func Markdown(w io.Writer, results []provider.Result) error {
 				strconv.Itoa(n.LastPublishDays),
 				strconv.Itoa(n.DependenciesCount),
 				escapeMarkdown(n.License),
+				escapeMarkdown(n.SourceRepo),
+				strconv.Itoa(n.OpenVulns),
+				escapeMarkdown(n.MaxSeverity),
+				escapeMarkdown(strings.Join(n.AdvisoryIDs, ", ")),
 				escapeMarkdown(r.Error),
 			); err != nil {
 				return err
@@ -126,15 +262,15 @@ func Markdown(w io.Writer, results []provider.Result) error {
 				return err
 			}
 		}
-		if _, err := fmt.Fprintln(w, "| target | weekly_downloads | monthly_downloads | latest_version | last_publish_days | dependencies_count | license | requires_python | error |"); err != nil {
+		if _, err := fmt.Fprintln(w, "| target | weekly_downloads | monthly_downloads | latest_version | last_publish_days | dependencies_count | license | requires_python | source_repo | open_vulns | max_severity | advisory_ids | error |"); err != nil {
 			return err
 		}
-		if _, err := fmt.Fprintln(w, "|---|---|---|---|---|---|---|---|---|"); err != nil {
+		if _, err := fmt.Fprintln(w, "|---|---|---|---|---|---|---|---|---|---|---|---|---|"); err != nil {
 			return err
 		}
 		for _, r := range pypiResults {
 			p := r.PyPI
-			if _, err := fmt.Fprintf(w, "| %s | %s | %s | %s | %s | %s | %s | %s | %s |\n",
+			if _, err := fmt.Fprintf(w, "| %s | %s | %s | %s | %s | %s | %s | %s | %s | %s | %s | %s | %s |\n",
 				escapeMarkdown(r.Target),
 				strconv.Itoa(p.WeeklyDownloads),
 				strconv.Itoa(p.MonthlyDownloads),
@@ -143,6 +279,10 @@ func Markdown(w io.Writer, results []provider.Result) error {
 				strconv.Itoa(p.DependenciesCount),
 				escapeMarkdown(p.License),
 				escapeMarkdown(p.RequiresPython),
+				escapeMarkdown(p.SourceRepo),
+				strconv.Itoa(p.OpenVulns),
+				escapeMarkdown(p.MaxSeverity),
+				escapeMarkdown(strings.Join(p.AdvisoryIDs, ", ")),
 				escapeMarkdown(r.Error),
 			); err != nil {
 				return err
@@ -157,15 +297,15 @@ func Markdown(w io.Writer, results []provider.Result) error {
 				return err
 			}
 		}
-		if _, err := fmt.Fprintln(w, "| target | downloads | recent_downloads | latest_version | last_publish_days | dependencies_count | license | reverse_dependencies | error |"); err != nil {
+		if _, err := fmt.Fprintln(w, "| target | downloads | recent_downloads | latest_version | last_publish_days | dependencies_count | license | reverse_dependencies | source_repo | open_vulns | max_severity | advisory_ids | error |"); err != nil {
 			return err
 		}
-		if _, err := fmt.Fprintln(w, "|---|---|---|---|---|---|---|---|---|"); err != nil {
+		if _, err := fmt.Fprintln(w, "|---|---|---|---|---|---|---|---|---|---|---|---|---|"); err != nil {
 			return err
 		}
 		for _, r := range cratesResults {
 			c := r.Crates
-			if _, err := fmt.Fprintf(w, "| %s | %s | %s | %s | %s | %s | %s | %s | %s |\n",
+			if _, err := fmt.Fprintf(w, "| %s | %s | %s | %s | %s | %s | %s | %s | %s | %s | %s | %s | %s |\n",
 				escapeMarkdown(r.Target),
 				strconv.Itoa(c.Downloads),
 				strconv.Itoa(c.RecentDownloads),
@@ -174,6 +314,10 @@ func Markdown(w io.Writer, results []provider.Result) error {
 				strconv.Itoa(c.DependenciesCount),
 				escapeMarkdown(c.License),
 				strconv.Itoa(c.ReverseDependencies),
+				escapeMarkdown(c.SourceRepo),
+				strconv.Itoa(c.OpenVulns),
+				escapeMarkdown(c.MaxSeverity),
+				escapeMarkdown(strings.Join(c.AdvisoryIDs, ", ")),
 				escapeMarkdown(r.Error),
 			); err != nil {
 				return err
@@ -188,20 +332,312 @@ func Markdown(w io.Writer, results []provider.Result) error {
 				return err
 			}
 		}
-		if _, err := fmt.Fprintln(w, "| target | latest_version | last_publish_days | dependencies_count | license | error |"); err != nil {
+		if _, err := fmt.Fprintln(w, "| target | latest_version | last_publish_days | dependencies_count | license | source_repo | open_vulns | max_severity | advisory_ids | error |"); err != nil {
 			return err
 		}
-		if _, err := fmt.Fprintln(w, "|---|---|---|---|---|---|"); err != nil {
+		if _, err := fmt.Fprintln(w, "|---|---|---|---|---|---|---|---|---|---|"); err != nil {
 			return err
 		}
 		for _, r := range goResults {
 			g := r.Go
-			if _, err := fmt.Fprintf(w, "| %s | %s | %s | %s | %s | %s |\n",
+			if _, err := fmt.Fprintf(w, "| %s | %s | %s | %s | %s | %s | %s | %s | %s | %s |\n",
+				escapeMarkdown(r.Target),
+				escapeMarkdown(g.LatestVersion),
+				strconv.Itoa(g.LastPublishDays),
+				strconv.Itoa(g.DependenciesCount),
+				escapeMarkdown(g.License),
+				escapeMarkdown(g.SourceRepo),
+				strconv.Itoa(g.OpenVulns),
+				escapeMarkdown(g.MaxSeverity),
+				escapeMarkdown(strings.Join(g.AdvisoryIDs, ", ")),
+				escapeMarkdown(r.Error),
+			); err != nil {
+				return err
+			}
+		}
+		needSep = true
+	}
+
+	if len(gemResults) > 0 {
+		if needSep {
+			if _, err := fmt.Fprintln(w); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintln(w, "| target | downloads | version_downloads | latest_version | last_publish_days | dependencies_count | license | required_ruby_version | reverse_dependencies | source_repo | open_vulns | max_severity | advisory_ids | error |"); err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintln(w, "|---|---|---|---|---|---|---|---|---|---|---|---|---|---|"); err != nil {
+			return err
+		}
+		for _, r := range gemResults {
+			g := r.Gem
+			if _, err := fmt.Fprintf(w, "| %s | %s | %s | %s | %s | %s | %s | %s | %s | %s | %s | %s | %s | %s |\n",
 				escapeMarkdown(r.Target),
+				strconv.Itoa(g.Downloads),
+				strconv.Itoa(g.VersionDownloads),
 				escapeMarkdown(g.LatestVersion),
 				strconv.Itoa(g.LastPublishDays),
 				strconv.Itoa(g.DependenciesCount),
 				escapeMarkdown(g.License),
+				escapeMarkdown(g.RequiredRubyVersion),
+				strconv.Itoa(g.ReverseDependencies),
+				escapeMarkdown(g.SourceRepo),
+				strconv.Itoa(g.OpenVulns),
+				escapeMarkdown(g.MaxSeverity),
+				escapeMarkdown(strings.Join(g.AdvisoryIDs, ", ")),
+				escapeMarkdown(r.Error),
+			); err != nil {
+				return err
+			}
+		}
+		needSep = true
+	}
+
+	if len(mavenResults) > 0 {
+		if needSep {
+			if _, err := fmt.Fprintln(w); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintln(w, "| target | latest_version | last_publish_days | dependencies_count | license | parent | source_repo | open_vulns | max_severity | advisory_ids | error |"); err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintln(w, "|---|---|---|---|---|---|---|---|---|---|---|"); err != nil {
+			return err
+		}
+		for _, r := range mavenResults {
+			m := r.Maven
+			if _, err := fmt.Fprintf(w, "| %s | %s | %s | %s | %s | %s | %s | %s | %s | %s | %s |\n",
+				escapeMarkdown(r.Target),
+				escapeMarkdown(m.LatestVersion),
+				strconv.Itoa(m.LastPublishDays),
+				strconv.Itoa(m.DependenciesCount),
+				escapeMarkdown(m.License),
+				escapeMarkdown(m.Parent),
+				escapeMarkdown(m.SourceRepo),
+				strconv.Itoa(m.OpenVulns),
+				escapeMarkdown(m.MaxSeverity),
+				escapeMarkdown(strings.Join(m.AdvisoryIDs, ", ")),
+				escapeMarkdown(r.Error),
+			); err != nil {
+				return err
+			}
+		}
+		needSep = true
+	}
+
+	if len(nugetResults) > 0 {
+		if needSep {
+			if _, err := fmt.Fprintln(w); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintln(w, "| target | total_downloads | latest_version | last_publish_days | dependencies_count | dependencies_framework | target_frameworks | license | deprecation | listed | source_repo | open_vulns | max_severity | advisory_ids | error |"); err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintln(w, "|---|---|---|---|---|---|---|---|---|---|---|---|---|---|---|"); err != nil {
+			return err
+		}
+		for _, r := range nugetResults {
+			n := r.NuGet
+			if _, err := fmt.Fprintf(w, "| %s | %s | %s | %s | %s | %s | %s | %s | %s | %s | %s | %s | %s | %s | %s |\n",
+				escapeMarkdown(r.Target),
+				strconv.Itoa(n.TotalDownloads),
+				escapeMarkdown(n.LatestVersion),
+				strconv.Itoa(n.LastPublishDays),
+				strconv.Itoa(n.DependenciesCount),
+				escapeMarkdown(n.DependenciesFramework),
+				escapeMarkdown(strings.Join(n.TargetFrameworks, ", ")),
+				escapeMarkdown(n.License),
+				escapeMarkdown(n.Deprecation),
+				strconv.FormatBool(n.Listed),
+				escapeMarkdown(n.SourceRepo),
+				strconv.Itoa(n.OpenVulns),
+				escapeMarkdown(n.MaxSeverity),
+				escapeMarkdown(strings.Join(n.AdvisoryIDs, ", ")),
+				escapeMarkdown(r.Error),
+			); err != nil {
+				return err
+			}
+		}
+		needSep = true
+	}
+
+	if len(packagistResults) > 0 {
+		if needSep {
+			if _, err := fmt.Fprintln(w); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintln(w, "| target | total_downloads | monthly_downloads | daily_downloads | favers | latest_version | last_publish_days | dependencies_count | license | abandoned | replacement | source_repo | open_vulns | max_severity | advisory_ids | error |"); err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintln(w, "|---|---|---|---|---|---|---|---|---|---|---|---|---|---|---|---|"); err != nil {
+			return err
+		}
+		for _, r := range packagistResults {
+			m := r.Packagist
+			if _, err := fmt.Fprintf(w, "| %s | %s | %s | %s | %s | %s | %s | %s | %s | %s | %s | %s | %s | %s | %s | %s |\n",
+				escapeMarkdown(r.Target),
+				strconv.Itoa(m.TotalDownloads),
+				strconv.Itoa(m.MonthlyDownloads),
+				strconv.Itoa(m.DailyDownloads),
+				strconv.Itoa(m.Favers),
+				escapeMarkdown(m.LatestVersion),
+				strconv.Itoa(m.LastPublishDays),
+				strconv.Itoa(m.DependenciesCount),
+				escapeMarkdown(m.License),
+				strconv.FormatBool(m.Abandoned),
+				escapeMarkdown(m.Replacement),
+				escapeMarkdown(m.SourceRepo),
+				strconv.Itoa(m.OpenVulns),
+				escapeMarkdown(m.MaxSeverity),
+				escapeMarkdown(strings.Join(m.AdvisoryIDs, ", ")),
+				escapeMarkdown(r.Error),
+			); err != nil {
+				return err
+			}
+		}
+		needSep = true
+	}
+
+	if len(hexResults) > 0 {
+		if needSep {
+			if _, err := fmt.Fprintln(w); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintln(w, "| target | downloads | weekly_downloads | recent_downloads | latest_version | last_publish_days | dependencies_count | license | retired | retirement_reason | source_repo | open_vulns | max_severity | advisory_ids | error |"); err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintln(w, "|---|---|---|---|---|---|---|---|---|---|---|---|---|---|---|"); err != nil {
+			return err
+		}
+		for _, r := range hexResults {
+			h := r.Hex
+			if _, err := fmt.Fprintf(w, "| %s | %s | %s | %s | %s | %s | %s | %s | %s | %s | %s | %s | %s | %s | %s |\n",
+				escapeMarkdown(r.Target),
+				strconv.Itoa(h.Downloads),
+				strconv.Itoa(h.WeeklyDownloads),
+				strconv.Itoa(h.RecentDownloads),
+				escapeMarkdown(h.LatestVersion),
+				strconv.Itoa(h.LastPublishDays),
+				strconv.Itoa(h.DependenciesCount),
+				escapeMarkdown(h.License),
+				strconv.FormatBool(h.Retired),
+				escapeMarkdown(h.RetirementReason),
+				escapeMarkdown(h.SourceRepo),
+				strconv.Itoa(h.OpenVulns),
+				escapeMarkdown(h.MaxSeverity),
+				escapeMarkdown(strings.Join(h.AdvisoryIDs, ", ")),
+				escapeMarkdown(r.Error),
+			); err != nil {
+				return err
+			}
+		}
+		needSep = true
+	}
+
+	if len(pubResults) > 0 {
+		if needSep {
+			if _, err := fmt.Fprintln(w); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintln(w, "| target | likes | pub_points | popularity | monthly_downloads | latest_version | last_publish_days | dependencies_count | sdk_constraint | platforms | discontinued | replaced_by | source_repo | open_vulns | max_severity | advisory_ids | error |"); err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintln(w, "|---|---|---|---|---|---|---|---|---|---|---|---|---|---|---|---|---|"); err != nil {
+			return err
+		}
+		for _, r := range pubResults {
+			m := r.Pub
+			if _, err := fmt.Fprintf(w, "| %s | %s | %s | %s | %s | %s | %s | %s | %s | %s | %s | %s | %s | %s | %s | %s | %s |\n",
+				escapeMarkdown(r.Target),
+				strconv.Itoa(m.Likes),
+				strconv.Itoa(m.PubPoints),
+				strconv.Itoa(m.Popularity),
+				strconv.Itoa(m.MonthlyDownloads),
+				escapeMarkdown(m.LatestVersion),
+				strconv.Itoa(m.LastPublishDays),
+				strconv.Itoa(m.DependenciesCount),
+				escapeMarkdown(m.SDKConstraint),
+				escapeMarkdown(strings.Join(m.Platforms, ", ")),
+				strconv.FormatBool(m.Discontinued),
+				escapeMarkdown(m.ReplacedBy),
+				escapeMarkdown(m.SourceRepo),
+				strconv.Itoa(m.OpenVulns),
+				escapeMarkdown(m.MaxSeverity),
+				escapeMarkdown(strings.Join(m.AdvisoryIDs, ", ")),
+				escapeMarkdown(r.Error),
+			); err != nil {
+				return err
+			}
+		}
+		needSep = true
+	}
+
+	if len(dockerResults) > 0 {
+		if needSep {
+			if _, err := fmt.Fprintln(w); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintln(w, "| target | pull_count | star_count | last_pushed_days | tag_count | official | verified | architectures | error |"); err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintln(w, "|---|---|---|---|---|---|---|---|---|"); err != nil {
+			return err
+		}
+		for _, r := range dockerResults {
+			d := r.Docker
+			if _, err := fmt.Fprintf(w, "| %s | %s | %s | %s | %s | %s | %s | %s | %s |\n",
+				escapeMarkdown(r.Target),
+				strconv.Itoa(d.PullCount),
+				strconv.Itoa(d.StarCount),
+				strconv.Itoa(d.LastPushedDays),
+				strconv.Itoa(d.TagCount),
+				strconv.FormatBool(d.Official),
+				strconv.FormatBool(d.Verified),
+				escapeMarkdown(strings.Join(d.Architectures, ", ")),
+				escapeMarkdown(r.Error),
+			); err != nil {
+				return err
+			}
+		}
+		needSep = true
+	}
+
+	if len(scorecardResults) > 0 {
+		if needSep {
+			if _, err := fmt.Fprintln(w); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintln(w, "| target | score | date | binary_artifacts | branch_protection | cii_best_practices | ci_tests | code_review | contributors | dangerous_workflow | dependency_update_tool | fuzzing | license | maintained | packaging | pinned_dependencies | sast | security_policy | signed_releases | token_permissions | vulnerabilities | error |"); err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintln(w, "|---|---|---|---|---|---|---|---|---|---|---|---|---|---|---|---|---|---|---|---|---|---|"); err != nil {
+			return err
+		}
+		for _, r := range scorecardResults {
+			s := r.Scorecard
+			checks := []int{
+				s.BinaryArtifacts, s.BranchProtection, s.CIIBestPractices, s.CITests,
+				s.CodeReview, s.Contributors, s.DangerousWorkflow, s.DependencyUpdateTool,
+				s.Fuzzing, s.License, s.Maintained, s.Packaging, s.PinnedDependencies,
+				s.SAST, s.SecurityPolicy, s.SignedReleases, s.TokenPermissions, s.Vulnerabilities,
+			}
+			cells := make([]string, len(checks))
+			for i, c := range checks {
+				cells[i] = strconv.Itoa(c)
+			}
+			if _, err := fmt.Fprintf(w, "| %s | %s | %s | %s | %s |\n",
+				escapeMarkdown(r.Target),
+				strconv.FormatFloat(s.Score, 'f', -1, 64),
+				escapeMarkdown(s.Date),
+				strings.Join(cells, " | "),
 				escapeMarkdown(r.Error),
 			); err != nil {
 				return err
@@ -227,6 +663,54 @@ func Markdown(w io.Writer, results []provider.Result) error {
 				return err
 			}
 		}
+		needSep = true
+	}
+
+	if len(warnResults) > 0 {
+		if needSep {
+			if _, err := fmt.Fprintln(w); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintln(w, "| target | warning |"); err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintln(w, "|---|---|"); err != nil {
+			return err
+		}
+		for _, r := range warnResults {
+			if _, err := fmt.Fprintf(w, "| %s | %s |\n", escapeMarkdown(r.Target), escapeMarkdown(strings.Join(r.Warnings, "; "))); err != nil {
+				return err
+			}
+		}
+		needSep = true
+	}
+
+	if len(depResults) > 0 {
+		if needSep {
+			if _, err := fmt.Fprintln(w); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintln(w, "| target | relation | path |"); err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintln(w, "|---|---|---|"); err != nil {
+			return err
+		}
+		for _, r := range depResults {
+			relation := "transitive"
+			if r.Dependency.Direct {
+				relation = "direct"
+			}
+			if _, err := fmt.Fprintf(w, "| %s | %s | %s |\n",
+				escapeMarkdown(r.Target),
+				relation,
+				escapeMarkdown(strings.Join(r.Dependency.Path, " > ")),
+			); err != nil {
+				return err
+			}
+		}
 	}
 
 	return nil
@@ -396,3 +396,399 @@ func TestNDJSONEmpty(t *testing.T) {
 		t.Errorf("expected empty output, got %q", buf.String())
 	}
 }
+
+func TestMarkdownDependencies(t *testing.T) {
+	var buf bytes.Buffer
+	results := []provider.Result{
+		{
+			Target:     "npm:express",
+			NPM:        &provider.NPMMetrics{LatestVersion: "4.19.0"},
+			Dependency: &provider.DependencyInfo{Direct: true, Path: []string{"express"}},
+		},
+		{
+			Target:     "npm:debug",
+			NPM:        &provider.NPMMetrics{LatestVersion: "2.6.9"},
+			Dependency: &provider.DependencyInfo{Path: []string{"express", "body-parser", "debug"}},
+		},
+	}
+	if err := Markdown(&buf, results); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	output := buf.String()
+	for _, want := range []string{
+		"| target | relation | path |",
+		"| npm:express | direct | express |",
+		"| npm:debug | transitive | express > body-parser > debug |",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected %q in output, got:\n%s", want, output)
+		}
+	}
+}
+
+func TestMarkdownWarnings(t *testing.T) {
+	var buf bytes.Buffer
+	results := []provider.Result{
+		{
+			Target:   "npm:express",
+			NPM:      &provider.NPMMetrics{LatestVersion: "4.19.0"},
+			Warnings: []string{"osv: 503 Service Unavailable"},
+		},
+	}
+	if err := Markdown(&buf, results); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "| npm:express | osv: 503 Service Unavailable |"; !strings.Contains(buf.String(), want) {
+		t.Errorf("expected %q in output, got:\n%s", want, buf.String())
+	}
+}
+
+func TestMarkdownNoDependencyTable(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Markdown(&buf, sampleResults()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Contains(buf.String(), "| relation |") {
+		t.Errorf("dependency table should be omitted without lockfile results, got:\n%s", buf.String())
+	}
+}
+
+func TestMarkdownGitLab(t *testing.T) {
+	var buf bytes.Buffer
+	results := []provider.Result{
+		{
+			Target: "gitlab:gitlab-org/gitlab-runner",
+			GitLab: &provider.GitLabMetrics{
+				Stars:           2500,
+				Forks:           4000,
+				OpenIssues:      3000,
+				Contributors:    900,
+				ReleaseCount:    400,
+				LastCommitDays:  1,
+				Commits30d:      150,
+				IssuesClosed30d: 90,
+				License:         "MIT",
+			},
+		},
+		{Target: "github:facebook/react", GitHub: &provider.GitHubMetrics{Stars: 1}},
+	}
+	if err := Markdown(&buf, results); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	output := buf.String()
+	want := "| gitlab:gitlab-org/gitlab-runner | 2500 | 4000 | 3000 | 900 | 400 | 1 | 150 | 90 | MIT |  |"
+	if !strings.Contains(output, want) {
+		t.Errorf("expected %q in output, got:\n%s", want, output)
+	}
+	if tables := strings.Split(strings.TrimSpace(output), "\n\n"); len(tables) != 2 {
+		t.Errorf("expected GitHub and GitLab tables, got:\n%s", output)
+	}
+}
+
+func TestMarkdownGitea(t *testing.T) {
+	var buf bytes.Buffer
+	results := []provider.Result{
+		{
+			Target: "codeberg:forgejo/forgejo",
+			Gitea: &provider.GiteaMetrics{
+				Stars:           1200,
+				Forks:           300,
+				OpenIssues:      900,
+				Contributors:    250,
+				ReleaseCount:    80,
+				LastCommitDays:  0,
+				Commits30d:      400,
+				IssuesClosed30d: 120,
+				License:         "GPL-3.0-or-later",
+			},
+			Error: "contributors: 500 Internal Server Error",
+		},
+		{
+			Target: "codeberg:big/repo",
+			Gitea:  &provider.GiteaMetrics{Contributors: 900, ContributorsCapped: true},
+		},
+	}
+	if err := Markdown(&buf, results); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, want := range []string{
+		"| codeberg:forgejo/forgejo | 1200 | 300 | 900 | 250 | 80 | 0 | 400 | 120 | GPL-3.0-or-later | contributors: 500 Internal Server Error |",
+		"| codeberg:big/repo | 0 | 0 | 0 | 900+ |",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("expected %q in output, got:\n%s", want, buf.String())
+		}
+	}
+}
+
+func TestMarkdownGem(t *testing.T) {
+	var buf bytes.Buffer
+	results := []provider.Result{
+		{
+			Target: "gem:rails",
+			Gem: &provider.GemMetrics{
+				Downloads:           600000000,
+				VersionDownloads:    3000000,
+				LatestVersion:       "8.0.2",
+				LastPublishDays:     10,
+				DependenciesCount:   13,
+				License:             "MIT",
+				RequiredRubyVersion: ">= 3.2.0",
+				ReverseDependencies: 9000,
+				SourceRepo:          "https://github.com/rails/rails",
+			},
+		},
+	}
+	if err := Markdown(&buf, results); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	output := buf.String()
+	for _, want := range []string{
+		"| target | downloads | version_downloads | latest_version |",
+		"| required_ruby_version | reverse_dependencies |",
+		"| gem:rails | 600000000 | 3000000 | 8.0.2 | 10 | 13 | MIT | >= 3.2.0 | 9000 | https://github.com/rails/rails | 0 |  |  |  |",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected %q in output, got:\n%s", want, output)
+		}
+	}
+}
+
+func TestMarkdownMaven(t *testing.T) {
+	var buf bytes.Buffer
+	results := []provider.Result{
+		{
+			Target: "maven:com.google.guava:guava",
+			Maven: &provider.MavenMetrics{
+				LatestVersion:     "33.4.8-jre",
+				LastPublishDays:   20,
+				DependenciesCount: 3,
+				License:           "Apache-2.0",
+				Parent:            "com.google.guava:guava-parent:33.4.8-jre",
+				SourceRepo:        "https://github.com/google/guava",
+				VulnMetrics:       provider.VulnMetrics{OpenVulns: 1, MaxSeverity: "MEDIUM", AdvisoryIDs: []string{"GHSA-7g45-4rm6-3mm3"}},
+			},
+		},
+	}
+	if err := Markdown(&buf, results); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := "| maven:com.google.guava:guava | 33.4.8-jre | 20 | 3 | Apache-2.0 | com.google.guava:guava-parent:33.4.8-jre | https://github.com/google/guava | 1 | MEDIUM | GHSA-7g45-4rm6-3mm3 |  |"
+	if !strings.Contains(buf.String(), want) {
+		t.Errorf("expected %q in output, got:\n%s", want, buf.String())
+	}
+}
+
+func TestMarkdownNuGet(t *testing.T) {
+	var buf bytes.Buffer
+	results := []provider.Result{
+		{
+			Target: "nuget:Newtonsoft.Json",
+			NuGet: &provider.NuGetMetrics{
+				TotalDownloads:        5000000000,
+				LatestVersion:         "13.0.3",
+				LastPublishDays:       900,
+				DependenciesCount:     0,
+				DependenciesFramework: ".NETStandard1.0",
+				TargetFrameworks:      []string{".NETFramework2.0", ".NETStandard1.0"},
+				License:               "MIT",
+				Listed:                true,
+				SourceRepo:            "https://github.com/JamesNK/Newtonsoft.Json",
+			},
+		},
+	}
+	if err := Markdown(&buf, results); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := "| nuget:Newtonsoft.Json | 5000000000 | 13.0.3 | 900 | 0 | .NETStandard1.0 | .NETFramework2.0, .NETStandard1.0 | MIT |  | true | https://github.com/JamesNK/Newtonsoft.Json | 0 |  |  |  |"
+	if !strings.Contains(buf.String(), want) {
+		t.Errorf("expected %q in output, got:\n%s", want, buf.String())
+	}
+}
+
+func TestMarkdownPackagist(t *testing.T) {
+	var buf bytes.Buffer
+	results := []provider.Result{
+		{
+			Target: "packagist:swiftmailer/swiftmailer",
+			Packagist: &provider.PackagistMetrics{
+				TotalDownloads:    300000000,
+				MonthlyDownloads:  2000000,
+				DailyDownloads:    60000,
+				Favers:            9500,
+				LatestVersion:     "v6.3.0",
+				LastPublishDays:   1200,
+				DependenciesCount: 2,
+				License:           "MIT",
+				Abandoned:         true,
+				Replacement:       "symfony/mailer",
+				SourceRepo:        "https://github.com/swiftmailer/swiftmailer",
+			},
+			Error: "stats: timeout",
+		},
+	}
+	if err := Markdown(&buf, results); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := "| packagist:swiftmailer/swiftmailer | 300000000 | 2000000 | 60000 | 9500 | v6.3.0 | 1200 | 2 | MIT | true | symfony/mailer | https://github.com/swiftmailer/swiftmailer | 0 |  |  | stats: timeout |"
+	if !strings.Contains(buf.String(), want) {
+		t.Errorf("expected %q in output, got:\n%s", want, buf.String())
+	}
+}
+
+func TestMarkdownHex(t *testing.T) {
+	var buf bytes.Buffer
+	results := []provider.Result{
+		{
+			Target: "hex:phoenix",
+			Hex: &provider.HexMetrics{
+				Downloads:         120000000,
+				WeeklyDownloads:   250000,
+				RecentDownloads:   3200000,
+				LatestVersion:     "1.7.14",
+				LastPublishDays:   40,
+				DependenciesCount: 7,
+				License:           "MIT",
+				SourceRepo:        "https://github.com/phoenixframework/phoenix",
+			},
+		},
+	}
+	if err := Markdown(&buf, results); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := "| hex:phoenix | 120000000 | 250000 | 3200000 | 1.7.14 | 40 | 7 | MIT | false |  | https://github.com/phoenixframework/phoenix | 0 |  |  |  |"
+	if !strings.Contains(buf.String(), want) {
+		t.Errorf("expected %q in output, got:\n%s", want, buf.String())
+	}
+}
+
+func TestMarkdownPub(t *testing.T) {
+	var buf bytes.Buffer
+	results := []provider.Result{
+		{
+			Target: "pub:http",
+			Pub: &provider.PubMetrics{
+				Likes:             8000,
+				PubPoints:         160,
+				Popularity:        100,
+				MonthlyDownloads:  6000000,
+				LatestVersion:     "1.4.0",
+				LastPublishDays:   60,
+				DependenciesCount: 3,
+				SDKConstraint:     "^3.4.0",
+				Platforms:         []string{"android", "ios", "web"},
+				SourceRepo:        "https://github.com/dart-lang/http",
+			},
+		},
+	}
+	if err := Markdown(&buf, results); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := "| pub:http | 8000 | 160 | 100 | 6000000 | 1.4.0 | 60 | 3 | ^3.4.0 | android, ios, web | false |  | https://github.com/dart-lang/http | 0 |  |  |  |"
+	if !strings.Contains(buf.String(), want) {
+		t.Errorf("expected %q in output, got:\n%s", want, buf.String())
+	}
+}
+
+func TestMarkdownDocker(t *testing.T) {
+	var buf bytes.Buffer
+	results := []provider.Result{
+		{
+			Target: "docker:nginx",
+			Docker: &provider.DockerMetrics{
+				PullCount:      1000000000,
+				StarCount:      20000,
+				LastPushedDays: 3,
+				TagCount:       1000,
+				Official:       true,
+				Architectures:  []string{"linux/amd64", "linux/arm/v7", "linux/arm64/v8"},
+			},
+		},
+	}
+	if err := Markdown(&buf, results); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := "| docker:nginx | 1000000000 | 20000 | 3 | 1000 | true | false | linux/amd64, linux/arm/v7, linux/arm64/v8 |  |"
+	if !strings.Contains(buf.String(), want) {
+		t.Errorf("expected %q in output, got:\n%s", want, buf.String())
+	}
+}
+
+func TestMarkdownScorecard(t *testing.T) {
+	var buf bytes.Buffer
+	results := []provider.Result{
+		{
+			Target: "scorecard:github.com/ossf/scorecard",
+			Scorecard: &provider.ScorecardMetrics{
+				Score:            8.2,
+				Date:             "2025-06-02",
+				BranchProtection: -1,
+				CodeReview:       9,
+				Maintained:       10,
+			},
+		},
+	}
+	if err := Markdown(&buf, results); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	output := buf.String()
+	for _, want := range []string{
+		"| target | score | date |",
+		"| code_review |",
+		"| maintained |",
+		"| pinned_dependencies |",
+		"| error |",
+		"| scorecard:github.com/ossf/scorecard | 8.2 | 2025-06-02 | 0 | -1 | 0 | 0 | 9 |",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected %q in output, got:\n%s", want, output)
+		}
+	}
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if got, want := strings.Count(lines[2], "|"), strings.Count(lines[0], "|"); got != want {
+		t.Errorf("row has %d cell separators, header has %d", got, want)
+	}
+}
+
+func TestMarkdownSources(t *testing.T) {
+	var buf bytes.Buffer
+	react := &provider.Result{Target: "github:facebook/react", GitHub: &provider.GitHubMetrics{Stars: 230000}}
+	results := []provider.Result{
+		{Target: "npm:react", NPM: &provider.NPMMetrics{SourceRepo: "https://github.com/facebook/react"}, Source: react},
+		{Target: "npm:react-dom", NPM: &provider.NPMMetrics{SourceRepo: "https://github.com/facebook/react"}, Source: react},
+		{
+			Target: "go:example.com/gone",
+			Go:     &provider.GoMetrics{SourceRepo: "https://github.com/gone/repo"},
+			Source: &provider.Result{Target: "github:gone/repo", Error: "GitHub API: 404 Not Found"},
+		},
+	}
+	if err := Markdown(&buf, results); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	output := buf.String()
+	for _, want := range []string{
+		"| github:facebook/react | 230000 |",
+		"| source_repo |",
+		"| https://github.com/facebook/react |",
+		"| github:gone/repo | GitHub API: 404 Not Found |",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected %q in output, got:\n%s", want, output)
+		}
+	}
+	if n := strings.Count(output, "| github:facebook/react |"); n != 1 {
+		t.Errorf("shared source repository listed %d times, want 1", n)
+	}
+}
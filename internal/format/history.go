@@ -0,0 +1,83 @@
+package format
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/yutakobayashidev/repiq/internal/history"
+)
+
+// HistoryJSON writes a trend as a JSON object.
+func HistoryJSON(w io.Writer, trend history.Trend) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(trend)
+}
+
+// HistoryCSV writes a trend as CSV with one row per snapshot.
+func HistoryCSV(w io.Writer, trend history.Trend) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(append([]string{"date"}, trend.Fields...)); err != nil {
+		return err
+	}
+	for _, p := range trend.Points {
+		if err := cw.Write(historyRow(p, trend.Fields)); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// HistoryMarkdown writes a trend as a table with one row per snapshot and a
+// final row with the change between the first and last snapshot.
+func HistoryMarkdown(w io.Writer, trend history.Trend) error {
+	if _, err := fmt.Fprintf(w, "| date | %s |\n", strings.Join(trend.Fields, " | ")); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "|---%s|\n", strings.Repeat("|---", len(trend.Fields))); err != nil {
+		return err
+	}
+	for _, p := range trend.Points {
+		if _, err := fmt.Fprintf(w, "| %s |\n", strings.Join(historyRow(p, trend.Fields), " | ")); err != nil {
+			return err
+		}
+	}
+	if len(trend.Points) < 2 {
+		return nil
+	}
+
+	cells := make([]string, len(trend.Fields))
+	for i, f := range trend.Fields {
+		if d, ok := trend.Change[f]; ok {
+			cells[i] = formatNumber(d)
+			if d > 0 {
+				cells[i] = "+" + cells[i]
+			}
+		}
+	}
+	_, err := fmt.Fprintf(w, "| change | %s |\n", strings.Join(cells, " | "))
+	return err
+}
+
+func historyRow(p history.Point, fields []string) []string {
+	row := make([]string, 0, len(fields)+1)
+	row = append(row, p.Date)
+	for _, f := range fields {
+		v, ok := p.Values[f]
+		if !ok {
+			row = append(row, "")
+			continue
+		}
+		row = append(row, formatNumber(v))
+	}
+	return row
+}
+
+func formatNumber(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
@@ -0,0 +1,75 @@
+package format
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/yutakobayashidev/repiq/internal/history"
+)
+
+func testTrend() history.Trend {
+	return history.Trend{
+		Target: "github:o/r",
+		Fields: []string{"stars", "last_commit_days"},
+		Points: []history.Point{
+			{Date: "2025-06-01", Values: map[string]float64{"stars": 100, "last_commit_days": 2}},
+			{Date: "2025-06-08", Values: map[string]float64{"stars": 130, "last_commit_days": 1}},
+		},
+		Change: map[string]float64{"stars": 30, "last_commit_days": -1},
+	}
+}
+
+func TestHistoryMarkdown(t *testing.T) {
+	var buf bytes.Buffer
+	if err := HistoryMarkdown(&buf, testTrend()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := `| date | stars | last_commit_days |
+|---|---|---|
+| 2025-06-01 | 100 | 2 |
+| 2025-06-08 | 130 | 1 |
+| change | +30 | -1 |
+`
+	if buf.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
+
+func TestHistoryMarkdownSinglePoint(t *testing.T) {
+	trend := testTrend()
+	trend.Points = trend.Points[:1]
+	var buf bytes.Buffer
+	if err := HistoryMarkdown(&buf, trend); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Contains(buf.String(), "change") {
+		t.Errorf("a single snapshot has no change row, got:\n%s", buf.String())
+	}
+}
+
+func TestHistoryCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := HistoryCSV(&buf, testTrend()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "date,stars,last_commit_days\n2025-06-01,100,2\n2025-06-08,130,1\n"
+	if buf.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
+
+func TestHistoryJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := HistoryJSON(&buf, testTrend()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var got history.Trend
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if got.Target != "github:o/r" || len(got.Points) != 2 || got.Change["stars"] != 30 {
+		t.Errorf("got %+v", got)
+	}
+}
@@ -0,0 +1,80 @@
+package format
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/yutakobayashidev/repiq/internal/policy"
+)
+
+// PolicyJSON writes a policy report as a JSON object.
+func PolicyJSON(w io.Writer, report policy.Report) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(struct {
+		Passed bool `json:"passed"`
+		policy.Report
+	}{report.Passed(), report})
+}
+
+// PolicyMarkdown writes a policy report as a table of violations, followed
+// by any targets that could not be fetched and a one-line summary.
+func PolicyMarkdown(w io.Writer, report policy.Report) error {
+	needSep := false
+
+	if len(report.Violations) > 0 {
+		if _, err := fmt.Fprintln(w, "| target | field | rule | actual |"); err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintln(w, "|---|---|---|---|"); err != nil {
+			return err
+		}
+		for _, v := range report.Violations {
+			if _, err := fmt.Fprintf(w, "| %s | %s | %s | %s |\n",
+				escapeMarkdown(v.Target),
+				escapeMarkdown(v.Field),
+				escapeMarkdown(v.Rule),
+				escapeMarkdown(fmt.Sprint(v.Actual)),
+			); err != nil {
+				return err
+			}
+		}
+		needSep = true
+	}
+
+	if len(report.Errors) > 0 {
+		if needSep {
+			if _, err := fmt.Fprintln(w); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintln(w, "| target | error |"); err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintln(w, "|---|---|"); err != nil {
+			return err
+		}
+		for _, e := range report.Errors {
+			if _, err := fmt.Fprintf(w, "| %s | %s |\n", escapeMarkdown(e.Target), escapeMarkdown(e.Error)); err != nil {
+				return err
+			}
+		}
+		needSep = true
+	}
+
+	if needSep {
+		if _, err := fmt.Fprintln(w); err != nil {
+			return err
+		}
+	}
+	summary := fmt.Sprintf("%d of %d targets violate the policy.", len(report.ViolatingTargets()), report.Targets)
+	if report.Passed() {
+		summary = fmt.Sprintf("All %d targets pass the policy.", report.Targets)
+	} else if len(report.Errors) > 0 {
+		summary = fmt.Sprintf("%d of %d targets violate the policy; %d could not be fetched.",
+			len(report.ViolatingTargets()), report.Targets, len(report.Errors))
+	}
+	_, err := fmt.Fprintln(w, summary)
+	return err
+}
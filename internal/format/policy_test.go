@@ -0,0 +1,77 @@
+package format
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/yutakobayashidev/repiq/internal/policy"
+)
+
+var sampleReport = policy.Report{
+	Targets: 3,
+	Violations: []policy.Violation{
+		{Target: "github:old/abandoned", Field: "last_commit_days", Rule: "max 365", Actual: 900},
+		{Target: "github:old/abandoned", Field: "license", Rule: "allow MIT, Apache-2.0", Actual: "GPL-3.0"},
+	},
+	Errors: []policy.TargetError{
+		{Target: "npm:missing", Error: "npm registry: 404 Not Found"},
+	},
+}
+
+func TestPolicyMarkdown(t *testing.T) {
+	var buf bytes.Buffer
+	if err := PolicyMarkdown(&buf, sampleReport); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	output := buf.String()
+	for _, want := range []string{
+		"| target | field | rule | actual |",
+		"| github:old/abandoned | last_commit_days | max 365 | 900 |",
+		"| github:old/abandoned | license | allow MIT, Apache-2.0 | GPL-3.0 |",
+		"| npm:missing | npm registry: 404 Not Found |",
+		"1 of 3 targets violate the policy; 1 could not be fetched.",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected %q in output, got:\n%s", want, output)
+		}
+	}
+}
+
+func TestPolicyMarkdownPassed(t *testing.T) {
+	var buf bytes.Buffer
+	if err := PolicyMarkdown(&buf, policy.Report{Targets: 2}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := buf.String(); got != "All 2 targets pass the policy.\n" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestPolicyJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := PolicyJSON(&buf, sampleReport); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var got struct {
+		Passed     bool               `json:"passed"`
+		Targets    int                `json:"targets"`
+		Violations []policy.Violation `json:"violations"`
+		Errors     []policy.TargetError
+	}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, buf.String())
+	}
+	if got.Passed {
+		t.Error("expected passed=false")
+	}
+	if got.Targets != 3 || len(got.Violations) != 2 || len(got.Errors) != 1 {
+		t.Errorf("got %+v", got)
+	}
+	if got.Violations[0].Actual != float64(900) {
+		t.Errorf("actual = %#v, want 900", got.Violations[0].Actual)
+	}
+}
@@ -0,0 +1,47 @@
+package format
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+
+	"github.com/yutakobayashidev/repiq/internal/provider"
+)
+
+// Stream writes results as newline-delimited JSON one at a time, as soon as
+// each is available. Every line carries the result's position among the
+// targets as "index", so readers can restore the input order. A Stream is
+// safe for concurrent use.
+type Stream struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+	err error
+}
+
+// NewStream returns a Stream writing to w.
+func NewStream(w io.Writer) *Stream {
+	return &Stream{enc: json.NewEncoder(w)}
+}
+
+type indexedResult struct {
+	Index int `json:"index"`
+	provider.Result
+}
+
+// Write writes r, the result of the target at index. After a failed write,
+// later writes are skipped; Err reports the failure.
+func (s *Stream) Write(index int, r provider.Result) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return
+	}
+	s.err = s.enc.Encode(indexedResult{Index: index, Result: r})
+}
+
+// Err returns the first write error, if any.
+func (s *Stream) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
@@ -0,0 +1,51 @@
+package format
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/yutakobayashidev/repiq/internal/provider"
+)
+
+func TestStream(t *testing.T) {
+	var buf bytes.Buffer
+	s := NewStream(&buf)
+	s.Write(1, provider.Result{Target: "npm:react", NPM: &provider.NPMMetrics{WeeklyDownloads: 10}})
+	s.Write(0, provider.Result{Target: "github:facebook/react", Error: "GitHub API: 404 Not Found"})
+	if err := s.Err(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2:\n%s", len(lines), buf.String())
+	}
+	if !strings.HasPrefix(lines[0], `{"index":1,"target":"npm:react",`) || !strings.Contains(lines[0], `"weekly_downloads":10`) {
+		t.Errorf("line 1: got %s", lines[0])
+	}
+	if !strings.HasPrefix(lines[1], `{"index":0,"target":"github:facebook/react",`) {
+		t.Errorf("line 2: got %s", lines[1])
+	}
+}
+
+type failingWriter struct{ writes int }
+
+func (w *failingWriter) Write([]byte) (int, error) {
+	w.writes++
+	return 0, errors.New("broken pipe")
+}
+
+func TestStreamWriteError(t *testing.T) {
+	w := &failingWriter{}
+	s := NewStream(w)
+	s.Write(0, provider.Result{Target: "npm:a"})
+	s.Write(1, provider.Result{Target: "npm:b"})
+	if s.Err() == nil {
+		t.Error("expected the write error")
+	}
+	if w.writes != 1 {
+		t.Errorf("got %d writes, want 1 after the first failure", w.writes)
+	}
+}
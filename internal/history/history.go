@@ -0,0 +1,148 @@
+// Package history keeps an append-only record of fetched metrics so that
+// changes can be reported over time. Unlike the cache, which overwrites an
+// entry once it expires, history keeps at most one snapshot per target per
+// day and never rewrites past snapshots.
+package history
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/yutakobayashidev/repiq/internal/provider"
+)
+
+// dateLayout is the layout of Snapshot.Date.
+const dateLayout = "2006-01-02"
+
+// Snapshot is one day's metrics for a target.
+type Snapshot struct {
+	Date       string          `json:"date"`
+	RecordedAt time.Time       `json:"recorded_at"`
+	Result     provider.Result `json:"result"`
+}
+
+// Store is a directory of JSON Lines files, one per target.
+type Store struct {
+	dir string
+}
+
+// NewStore creates a Store that keeps snapshots in dir.
+func NewStore(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+// DefaultDir returns the directory history is kept in:
+// $XDG_DATA_HOME/repiq/history, or ~/.local/share/repiq/history.
+func DefaultDir() (string, error) {
+	if dir := os.Getenv("XDG_DATA_HOME"); dir != "" {
+		return filepath.Join(dir, "repiq", "history"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".local", "share", "repiq", "history"), nil
+}
+
+// path returns the file path for a target using SHA-256 hash.
+func (s *Store) path(target string) string {
+	h := sha256.Sum256([]byte(target))
+	return filepath.Join(s.dir, hex.EncodeToString(h[:])+".jsonl")
+}
+
+// Record appends a snapshot of every result that carries metrics, including
+// source repositories nested with --with-source. Partial results, which
+// carry an error or warning next to their metrics, are skipped as the
+// cache skips them: their missing fields would read as drops. Targets that already
+// have a snapshot for the day of now are skipped too.
+func (s *Store) Record(results []provider.Result, now time.Time) error {
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return err
+	}
+
+	date := now.Format(dateLayout)
+	var errs []error
+	for _, r := range results {
+		for _, res := range []*provider.Result{&r, r.Source} {
+			if res == nil {
+				continue
+			}
+			if _, metrics := res.Metrics(); metrics == nil || res.Error != "" || len(res.Warnings) > 0 {
+				continue
+			}
+			snap := *res
+			snap.Source = nil
+			snap.Dependency = nil
+			if err := s.append(Snapshot{Date: date, RecordedAt: now, Result: snap}); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", res.Target, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (s *Store) append(snap Snapshot) error {
+	existing, err := s.Load(snap.Result.Target, time.Time{})
+	if err != nil {
+		return err
+	}
+	if n := len(existing); n > 0 && existing[n-1].Date == snap.Date {
+		return nil
+	}
+
+	data, err := json.Marshal(snap)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(s.path(snap.Result.Target), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Load returns the snapshots of target recorded on or after since, oldest
+// first. A target without history has no snapshots. Lines that cannot be
+// decoded, such as one cut short by an interrupted write, are skipped.
+func (s *Store) Load(target string, since time.Time) ([]Snapshot, error) {
+	f, err := os.Open(s.path(target))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	var snaps []Snapshot
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		var snap Snapshot
+		if err := json.Unmarshal(scanner.Bytes(), &snap); err != nil {
+			continue
+		}
+		if snap.Result.Target != target || snap.RecordedAt.Before(since) {
+			continue
+		}
+		snaps = append(snaps, snap)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	sort.SliceStable(snaps, func(i, j int) bool { return snaps[i].Date < snaps[j].Date })
+	return snaps, nil
+}
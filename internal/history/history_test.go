@@ -0,0 +1,156 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/yutakobayashidev/repiq/internal/provider"
+)
+
+func day(n int) time.Time {
+	return time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC).AddDate(0, 0, n)
+}
+
+func TestRecordAndLoad(t *testing.T) {
+	store := NewStore(t.TempDir())
+
+	for i, stars := range []int{100, 110, 125} {
+		results := []provider.Result{{Target: "github:o/r", GitHub: &provider.GitHubMetrics{Stars: stars}}}
+		if err := store.Record(results, day(i)); err != nil {
+			t.Fatalf("Record: %v", err)
+		}
+	}
+
+	snaps, err := store.Load("github:o/r", time.Time{})
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(snaps) != 3 {
+		t.Fatalf("got %d snapshots, want 3", len(snaps))
+	}
+	if snaps[0].Date != "2025-06-01" || snaps[2].Result.GitHub.Stars != 125 {
+		t.Errorf("unexpected snapshots: %+v", snaps)
+	}
+
+	since, err := store.Load("github:o/r", day(1))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(since) != 2 || since[0].Date != "2025-06-02" {
+		t.Errorf("since filter: got %+v", since)
+	}
+}
+
+func TestRecordOncePerDay(t *testing.T) {
+	store := NewStore(t.TempDir())
+	for _, stars := range []int{100, 200} {
+		results := []provider.Result{{Target: "github:o/r", GitHub: &provider.GitHubMetrics{Stars: stars}}}
+		if err := store.Record(results, day(0)); err != nil {
+			t.Fatalf("Record: %v", err)
+		}
+	}
+
+	snaps, _ := store.Load("github:o/r", time.Time{})
+	if len(snaps) != 1 {
+		t.Fatalf("got %d snapshots, want 1", len(snaps))
+	}
+	if snaps[0].Result.GitHub.Stars != 100 {
+		t.Errorf("first snapshot of the day should be kept, got %d stars", snaps[0].Result.GitHub.Stars)
+	}
+}
+
+func TestRecordSkipsFailuresAndKeepsSources(t *testing.T) {
+	store := NewStore(t.TempDir())
+	results := []provider.Result{
+		{Target: "npm:missing", Error: "npm registry: 404 Not Found"},
+		{
+			Target:     "npm:react",
+			NPM:        &provider.NPMMetrics{WeeklyDownloads: 1000},
+			Dependency: &provider.DependencyInfo{Direct: true},
+			Source:     &provider.Result{Target: "github:facebook/react", GitHub: &provider.GitHubMetrics{Stars: 1}},
+		},
+	}
+	if err := store.Record(results, day(0)); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+
+	if snaps, _ := store.Load("npm:missing", time.Time{}); len(snaps) != 0 {
+		t.Errorf("failed targets should not be recorded, got %+v", snaps)
+	}
+	snaps, _ := store.Load("npm:react", time.Time{})
+	if len(snaps) != 1 || snaps[0].Result.Source != nil || snaps[0].Result.Dependency != nil {
+		t.Errorf("npm:react: got %+v", snaps)
+	}
+	if snaps, _ := store.Load("github:facebook/react", time.Time{}); len(snaps) != 1 {
+		t.Errorf("nested source should be recorded as its own target, got %d snapshots", len(snaps))
+	}
+}
+
+func TestRecordSkipsPartialFailures(t *testing.T) {
+	store := NewStore(t.TempDir())
+	partial := provider.Result{
+		Target: "npm:react",
+		NPM:    &provider.NPMMetrics{LatestVersion: "19.1.0"},
+		Error:  "downloads: npm downloads API: 503 Service Unavailable",
+	}
+	if err := store.Record([]provider.Result{partial}, day(0)); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+	if snaps, _ := store.Load("npm:react", time.Time{}); len(snaps) != 0 {
+		t.Fatalf("partial results should not be recorded, got %+v", snaps)
+	}
+
+	// A complete result later the same day is still recorded.
+	complete := provider.Result{Target: "npm:react", NPM: &provider.NPMMetrics{WeeklyDownloads: 1000}}
+	if err := store.Record([]provider.Result{complete}, day(0).Add(time.Hour)); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+	if snaps, _ := store.Load("npm:react", time.Time{}); len(snaps) != 1 || snaps[0].Result.NPM.WeeklyDownloads != 1000 {
+		t.Errorf("got %+v", snaps)
+	}
+}
+
+func TestLoadSkipsTruncatedLine(t *testing.T) {
+	dir := t.TempDir()
+	store := NewStore(dir)
+	results := []provider.Result{{Target: "npm:react", NPM: &provider.NPMMetrics{}}}
+	if err := store.Record(results, day(0)); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+
+	f, err := os.OpenFile(store.path("npm:react"), os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = f.WriteString(`{"date":"2025-06-02","result":{"tar`)
+	_ = f.Close()
+
+	snaps, err := store.Load("npm:react", time.Time{})
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(snaps) != 1 {
+		t.Errorf("got %d snapshots, want 1", len(snaps))
+	}
+}
+
+func TestLoadMissing(t *testing.T) {
+	store := NewStore(filepath.Join(t.TempDir(), "nope"))
+	snaps, err := store.Load("npm:react", time.Time{})
+	if err != nil || snaps != nil {
+		t.Errorf("got (%v, %v), want no snapshots and no error", snaps, err)
+	}
+}
+
+func TestDefaultDir(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", "/data")
+	dir, err := DefaultDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dir != filepath.Join("/data", "repiq", "history") {
+		t.Errorf("got %q", dir)
+	}
+}
@@ -0,0 +1,117 @@
+package history
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// trendFields are the metrics reported over time for each metrics block,
+// chosen to show whether a project is gaining adoption or going quiet.
+var trendFields = map[string][]string{
+	"github":    {"stars", "forks", "open_issues", "contributors", "commits_30d", "last_commit_days"},
+	"gitlab":    {"stars", "forks", "open_issues", "contributors", "commits_30d", "last_commit_days"},
+	"gitea":     {"stars", "forks", "open_issues", "contributors", "commits_30d", "last_commit_days"},
+	"npm":       {"weekly_downloads", "monthly_downloads", "dependencies_count", "last_publish_days", "open_vulns"},
+	"pypi":      {"weekly_downloads", "monthly_downloads", "dependencies_count", "last_publish_days", "open_vulns"},
+	"crates":    {"downloads", "recent_downloads", "reverse_dependencies", "last_publish_days", "open_vulns"},
+	"gem":       {"downloads", "version_downloads", "reverse_dependencies", "last_publish_days", "open_vulns"},
+	"maven":     {"dependencies_count", "last_publish_days", "open_vulns"},
+	"nuget":     {"total_downloads", "dependencies_count", "last_publish_days", "open_vulns"},
+	"packagist": {"monthly_downloads", "favers", "last_publish_days", "open_vulns"},
+	"hex":       {"downloads", "weekly_downloads", "recent_downloads", "last_publish_days", "open_vulns"},
+	"pub":       {"likes", "pub_points", "monthly_downloads", "last_publish_days", "open_vulns"},
+	"docker":    {"pull_count", "star_count", "tag_count", "last_pushed_days"},
+	"go":        {"dependencies_count", "last_publish_days", "open_vulns"},
+	"scorecard": {"score", "maintained", "vulnerabilities"},
+}
+
+// Point is the value of every trend field on one day.
+type Point struct {
+	Date   string             `json:"date"`
+	Values map[string]float64 `json:"values"`
+}
+
+// Trend is how a target's metrics changed across its snapshots.
+type Trend struct {
+	Target string   `json:"target"`
+	Fields []string `json:"fields"`
+	Points []Point  `json:"points"`
+	// Change is the difference between the last and first point.
+	Change map[string]float64 `json:"change"`
+}
+
+// NewTrend builds the trend of target from its snapshots, oldest first.
+func NewTrend(target string, snaps []Snapshot) Trend {
+	t := Trend{Target: target, Points: []Point{}, Change: map[string]float64{}}
+	for _, snap := range snaps {
+		key, metrics := snap.Result.Metrics()
+		if metrics == nil {
+			continue
+		}
+		if t.Fields == nil {
+			t.Fields = trendFields[key]
+		}
+		p := Point{Date: snap.Date, Values: make(map[string]float64, len(t.Fields))}
+		for _, f := range t.Fields {
+			if v, ok := number(metrics[f]); ok {
+				p.Values[f] = v
+			}
+		}
+		t.Points = append(t.Points, p)
+	}
+	if t.Fields == nil {
+		t.Fields = []string{}
+	}
+
+	if n := len(t.Points); n > 0 {
+		first, last := t.Points[0], t.Points[n-1]
+		for _, f := range t.Fields {
+			a, okA := first.Values[f]
+			b, okB := last.Values[f]
+			if okA && okB {
+				t.Change[f] = b - a
+			}
+		}
+	}
+	return t
+}
+
+func number(v any) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+var sinceRe = regexp.MustCompile(`^(\d+)([dwmy])$`)
+
+// ParseSince resolves a --since value relative to now. It accepts a number
+// of days, weeks, months or years ("90d", "12w", "6m", "1y") or a date
+// ("2025-01-31").
+func ParseSince(s string, now time.Time) (time.Time, error) {
+	if m := sinceRe.FindStringSubmatch(s); m != nil {
+		n, err := strconv.Atoi(m[1])
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid --since %q: %w", s, err)
+		}
+		switch m[2] {
+		case "d":
+			return now.AddDate(0, 0, -n), nil
+		case "w":
+			return now.AddDate(0, 0, -7*n), nil
+		case "m":
+			return now.AddDate(0, -n, 0), nil
+		default:
+			return now.AddDate(-n, 0, 0), nil
+		}
+	}
+	if t, err := time.ParseInLocation(dateLayout, s, now.Location()); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid --since %q: want a duration like 90d, 12w, 6m, 1y or a date like 2025-01-31", s)
+}
@@ -0,0 +1,66 @@
+package history
+
+import (
+	"testing"
+	"time"
+
+	"github.com/yutakobayashidev/repiq/internal/provider"
+)
+
+func TestNewTrend(t *testing.T) {
+	snaps := []Snapshot{
+		{Date: "2025-06-01", Result: provider.Result{Target: "npm:react", NPM: &provider.NPMMetrics{WeeklyDownloads: 1000, LastPublishDays: 10}}},
+		{Date: "2025-06-08", Result: provider.Result{Target: "npm:react", NPM: &provider.NPMMetrics{WeeklyDownloads: 800, LastPublishDays: 17}}},
+	}
+	tr := NewTrend("npm:react", snaps)
+
+	if len(tr.Fields) == 0 || tr.Fields[0] != "weekly_downloads" {
+		t.Errorf("fields: got %v", tr.Fields)
+	}
+	if len(tr.Points) != 2 {
+		t.Fatalf("got %d points, want 2", len(tr.Points))
+	}
+	if tr.Points[1].Values["last_publish_days"] != 17 {
+		t.Errorf("points: got %+v", tr.Points[1])
+	}
+	if tr.Change["weekly_downloads"] != -200 || tr.Change["last_publish_days"] != 7 {
+		t.Errorf("change: got %v", tr.Change)
+	}
+}
+
+func TestNewTrendEmpty(t *testing.T) {
+	tr := NewTrend("npm:react", nil)
+	if tr.Points == nil || tr.Fields == nil || tr.Change == nil {
+		t.Errorf("empty trend should have non-nil slices and maps: %+v", tr)
+	}
+}
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2025, 6, 30, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		in   string
+		want time.Time
+	}{
+		{"90d", now.AddDate(0, 0, -90)},
+		{"2w", now.AddDate(0, 0, -14)},
+		{"6m", now.AddDate(0, -6, 0)},
+		{"1y", now.AddDate(-1, 0, 0)},
+		{"2025-01-31", time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		got, err := ParseSince(tt.in, now)
+		if err != nil {
+			t.Errorf("%s: %v", tt.in, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.in, got, tt.want)
+		}
+	}
+
+	for _, in := range []string{"", "90", "d", "-1d", "3h", "2025-13-01"} {
+		if _, err := ParseSince(in, now); err == nil {
+			t.Errorf("%q: expected error", in)
+		}
+	}
+}
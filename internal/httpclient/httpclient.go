@@ -0,0 +1,235 @@
+// Package httpclient is the HTTP layer shared by every provider. It retries
+// idempotent requests that hit a rate limit or a transient server error,
+// waiting for the limit to reset when that fits in the request's deadline,
+// and tracks the remaining quota each API reports.
+package httpclient
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// maxRetries is the number of times a request is retried.
+	maxRetries = 3
+	// baseBackoff is the first retry delay when the response does not say
+	// how long to wait. It doubles with every retry.
+	baseBackoff = 500 * time.Millisecond
+	// maxWait caps a single wait for requests without a deadline.
+	maxWait = time.Minute
+	// DefaultRequestTimeout is the Timeout of Default.
+	DefaultRequestTimeout = 20 * time.Second
+)
+
+// Default is the transport used by clients returned by New, and by every
+// provider unless given another.
+var Default = NewTransport(DefaultConcurrency, DefaultRequestTimeout)
+
+// NewTransport returns a Transport over http.DefaultTransport with its own
+// Stats, that sends at most concurrency requests at once, DefaultPerHost
+// of them to any one host, and limits each attempt to timeout.
+func NewTransport(concurrency int, timeout time.Duration) *Transport {
+	return &Transport{
+		Base:    http.DefaultTransport,
+		Stats:   NewStats(),
+		Limiter: NewLimiter(concurrency, DefaultPerHost),
+		Timeout: timeout,
+	}
+}
+
+// New returns an HTTP client that uses Default.
+func New() *http.Client {
+	return &http.Client{Transport: Default}
+}
+
+// Transport is an http.RoundTripper that retries GET and HEAD requests on
+// 429, on 403 responses that report an exhausted rate limit, and on 5xx
+// gateway errors, with exponential backoff and jitter. Retry-After and
+// X-RateLimit-Reset take precedence over the backoff; a retry that could
+// not complete before the request context's deadline is not attempted.
+// Requests wait for a free slot in Limiter before being sent.
+type Transport struct {
+	Base    http.RoundTripper
+	Stats   *Stats
+	Limiter *Limiter
+	// Timeout limits each attempt, from when it is sent until its response
+	// body is read; zero means no limit. Time spent waiting for a Limiter
+	// slot or between retries does not count.
+	Timeout time.Duration
+	// Headers maps a host to headers added to every request to it, such
+	// as credentials for a private registry. Headers a request already
+	// carries are kept.
+	Headers map[string]http.Header
+	// sleep waits for d or until ctx is done; overridden in tests.
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	retryable := req.Method == http.MethodGet || req.Method == http.MethodHead
+	if headers := t.Headers[req.URL.Host]; len(headers) > 0 {
+		req = req.Clone(req.Context())
+		for name, values := range headers {
+			if req.Header.Get(name) == "" {
+				req.Header[name] = values
+			}
+		}
+	}
+
+	for attempt := 0; ; attempt++ {
+		resp, err := t.send(base, req)
+		if err == nil {
+			t.Stats.observe(req.URL.Host, resp)
+		} else {
+			t.Stats.observe(req.URL.Host, nil)
+		}
+		if !retryable || attempt == maxRetries || req.Context().Err() != nil {
+			return resp, err
+		}
+
+		var wait time.Duration
+		switch {
+		case err != nil:
+			wait = backoff(attempt)
+		case shouldRetry(resp):
+			wait = retryDelay(resp, attempt, time.Now())
+		default:
+			return resp, nil
+		}
+		if !fits(req.Context(), wait) {
+			return resp, err
+		}
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+			_ = resp.Body.Close()
+		}
+
+		t.Stats.retried(req.URL.Host, wait)
+		if err := t.wait(req.Context(), wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// send sends req once it holds a slot in t.Limiter, within t.Timeout.
+func (t *Transport) send(base http.RoundTripper, req *http.Request) (*http.Response, error) {
+	parent := req.Context()
+	release, err := t.Limiter.acquire(parent, req.URL.Host)
+	if err != nil {
+		return nil, err
+	}
+	if t.Timeout > 0 {
+		ctx, cancel := context.WithTimeout(parent, t.Timeout)
+		req = req.WithContext(ctx)
+		freeSlot := release
+		release = func() {
+			freeSlot()
+			cancel()
+		}
+	}
+	resp, err := base.RoundTrip(req)
+	if err != nil {
+		release()
+		// Only this attempt timed out: say so rather than reporting a bare
+		// "context deadline exceeded", and let RoundTrip retry it.
+		if parent.Err() == nil && req.Context().Err() == context.DeadlineExceeded {
+			return nil, fmt.Errorf("no response within %s", t.Timeout)
+		}
+		return nil, err
+	}
+	resp.Body = &releaseBody{ReadCloser: resp.Body, release: release}
+	return resp, nil
+}
+
+func (t *Transport) wait(ctx context.Context, d time.Duration) error {
+	if t.sleep != nil {
+		return t.sleep(ctx, d)
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// shouldRetry reports whether resp is a rate limit or a transient error.
+func shouldRetry(resp *http.Response) bool {
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	case http.StatusForbidden:
+		// GitHub reports both primary and secondary rate limits as 403.
+		return resp.Header.Get("X-RateLimit-Remaining") == "0" || resp.Header.Get("Retry-After") != ""
+	}
+	return false
+}
+
+// retryDelay is how long to wait before retrying resp: Retry-After, then
+// the rate limit reset when the quota is exhausted, then backoff.
+func retryDelay(resp *http.Response, attempt int, now time.Time) time.Duration {
+	if d, ok := parseRetryAfter(resp.Header.Get("Retry-After"), now); ok {
+		return d
+	}
+	if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+		if reset, ok := parseReset(resp.Header.Get("X-RateLimit-Reset"), now); ok {
+			// Allow for clock skew between us and the API.
+			return max(reset.Sub(now), 0) + time.Second
+		}
+	}
+	return backoff(attempt)
+}
+
+// backoff returns a jittered exponential delay: between half and all of
+// baseBackoff * 2^attempt.
+func backoff(attempt int) time.Duration {
+	d := baseBackoff << attempt
+	return d/2 + rand.N(d/2+1)
+}
+
+// fits reports whether waiting d still leaves time for another attempt
+// before ctx's deadline.
+func fits(ctx context.Context, d time.Duration) bool {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return d <= maxWait
+	}
+	return time.Now().Add(d).Before(deadline)
+}
+
+// parseRetryAfter parses a Retry-After value in seconds or as an HTTP date.
+func parseRetryAfter(v string, now time.Time) (time.Duration, bool) {
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return max(t.Sub(now), 0), true
+	}
+	return 0, false
+}
+
+// parseReset parses X-RateLimit-Reset, which is a Unix time on GitHub and
+// a number of seconds from now on some other APIs.
+func parseReset(v string, now time.Time) (time.Time, bool) {
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || n < 0 {
+		return time.Time{}, false
+	}
+	if n < 1_000_000_000 {
+		return now.Add(time.Duration(n) * time.Second), true
+	}
+	return time.Unix(n, 0), true
+}
@@ -0,0 +1,292 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTestTransport returns a Transport that records waits instead of
+// sleeping.
+func newTestTransport(waits *[]time.Duration) *Transport {
+	return &Transport{
+		Base:  http.DefaultTransport,
+		Stats: NewStats(),
+		sleep: func(_ context.Context, d time.Duration) error {
+			*waits = append(*waits, d)
+			return nil
+		},
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.Header().Set("Retry-After", "2")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	var waits []time.Duration
+	client := &http.Client{Transport: newTestTransport(&waits)}
+	resp, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("got %d, want 200 after retry", resp.StatusCode)
+	}
+	if len(waits) != 1 || waits[0] != 2*time.Second {
+		t.Errorf("waits: got %v, want [2s]", waits)
+	}
+}
+
+func TestRateLimitReset(t *testing.T) {
+	var calls int32
+	reset := time.Now().Add(10 * time.Second).Unix()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("X-RateLimit-Limit", "60")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset, 10))
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Header().Set("X-RateLimit-Remaining", "59")
+	}))
+	defer srv.Close()
+
+	var waits []time.Duration
+	tr := newTestTransport(&waits)
+	resp, err := (&http.Client{Transport: tr}).Get(srv.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("got %d, want 200 after waiting for reset", resp.StatusCode)
+	}
+	if len(waits) != 1 || waits[0] < 9*time.Second || waits[0] > 12*time.Second {
+		t.Errorf("waits: got %v, want about 11s", waits)
+	}
+
+	hosts := tr.Stats.Hosts()
+	if len(hosts) != 1 {
+		t.Fatalf("got %d hosts, want 1", len(hosts))
+	}
+	h := hosts[0]
+	if h.Requests != 2 || h.Retries != 1 || h.Waited != waits[0] {
+		t.Errorf("stats: got %+v", h)
+	}
+	if q := h.Quotas[""]; q.Limit != 60 || q.Remaining != 59 || q.Reset.Unix() != reset {
+		t.Errorf("quota: got %+v", q)
+	}
+}
+
+func TestPlainForbiddenNotRetried(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer srv.Close()
+
+	var waits []time.Duration
+	resp, err := (&http.Client{Transport: newTestTransport(&waits)}).Get(srv.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_ = resp.Body.Close()
+	if calls != 1 || resp.StatusCode != http.StatusForbidden {
+		t.Errorf("got %d calls and status %d, want 1 and 403", calls, resp.StatusCode)
+	}
+}
+
+func TestServerErrorBackoff(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	var waits []time.Duration
+	resp, err := (&http.Client{Transport: newTestTransport(&waits)}).Get(srv.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_ = resp.Body.Close()
+	if calls != maxRetries+1 {
+		t.Errorf("got %d calls, want %d", calls, maxRetries+1)
+	}
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("got %d, want the last 503", resp.StatusCode)
+	}
+	for i, w := range waits {
+		d := baseBackoff << i
+		if w < d/2 || w > d {
+			t.Errorf("wait %d: got %v, want between %v and %v", i, w, d/2, d)
+		}
+	}
+}
+
+func TestPostNotRetried(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	var waits []time.Duration
+	resp, err := (&http.Client{Transport: newTestTransport(&waits)}).Post(srv.URL, "application/json", strings.NewReader("{}"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_ = resp.Body.Close()
+	if calls != 1 {
+		t.Errorf("got %d calls, want 1", calls)
+	}
+}
+
+func TestWaitBeyondDeadline(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Header().Set("Retry-After", "3600")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, "GET", srv.URL, nil)
+
+	var waits []time.Duration
+	resp, err := (&http.Client{Transport: newTestTransport(&waits)}).Do(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_ = resp.Body.Close()
+	if calls != 1 || len(waits) != 0 {
+		t.Errorf("a reset past the deadline should fail fast, got %d calls and waits %v", calls, waits)
+	}
+	if resp.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("got %d, want 429", resp.StatusCode)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		in   string
+		want time.Duration
+		ok   bool
+	}{
+		{"120", 2 * time.Minute, true},
+		{"Sun, 01 Jun 2025 12:00:30 GMT", 30 * time.Second, true},
+		{"", 0, false},
+		{"soon", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseRetryAfter(tt.in, now)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("parseRetryAfter(%q) = (%v, %v), want (%v, %v)", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestParseReset(t *testing.T) {
+	now := time.Unix(1_750_000_000, 0)
+	if got, _ := parseReset("1750000060", now); !got.Equal(now.Add(time.Minute)) {
+		t.Errorf("epoch: got %v", got)
+	}
+	if got, _ := parseReset("60", now); !got.Equal(now.Add(time.Minute)) {
+		t.Errorf("delta seconds: got %v", got)
+	}
+	if _, ok := parseReset("", now); ok {
+		t.Error("empty value should not parse")
+	}
+}
+
+func TestRequestTimeoutRetried(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			<-r.Context().Done()
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	var waits []time.Duration
+	tr := newTestTransport(&waits)
+	tr.Timeout = 50 * time.Millisecond
+	resp, err := (&http.Client{Transport: tr}).Get(srv.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_ = resp.Body.Close()
+	if calls != 2 || len(waits) != 1 {
+		t.Errorf("a timed out attempt should be retried, got %d calls and waits %v", calls, waits)
+	}
+}
+
+func TestRequestTimeoutError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, "POST", srv.URL, nil)
+	tr := &Transport{Base: http.DefaultTransport, Timeout: 50 * time.Millisecond}
+	_, err := (&http.Client{Transport: tr}).Do(req)
+	if err == nil || !strings.Contains(err.Error(), "no response within 50ms") {
+		t.Errorf("got %v, want a request timeout error", err)
+	}
+}
+
+func TestHeaders(t *testing.T) {
+	var got []string
+	srv := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		got = append(got, r.Header.Get("Authorization"))
+	}))
+	defer srv.Close()
+
+	host := strings.TrimPrefix(srv.URL, "http://")
+	tr := &Transport{Base: http.DefaultTransport, Headers: map[string]http.Header{
+		host: {"Authorization": {"Bearer secret"}},
+	}}
+	client := &http.Client{Transport: tr}
+
+	resp, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_ = resp.Body.Close()
+
+	req, _ := http.NewRequest("GET", srv.URL, nil)
+	req.Header.Set("Authorization", "token mine")
+	resp, err = client.Do(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_ = resp.Body.Close()
+
+	if len(got) != 2 || got[0] != "Bearer secret" || got[1] != "token mine" {
+		t.Errorf("got Authorization headers %q", got)
+	}
+}
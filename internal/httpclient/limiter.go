@@ -0,0 +1,93 @@
+package httpclient
+
+import (
+	"context"
+	"io"
+	"sync"
+)
+
+// Default limits for the scheduler of Default.
+const (
+	DefaultConcurrency = 16
+	DefaultPerHost     = 8
+)
+
+// Limiter bounds the number of requests in flight, overall and per host. A
+// request holds its slots from when it is sent until its response body is
+// closed or read to the end. A nil *Limiter imposes no limit.
+type Limiter struct {
+	total   chan struct{}
+	perHost int
+
+	mu    sync.Mutex
+	hosts map[string]chan struct{}
+}
+
+// NewLimiter returns a Limiter allowing total requests at once, and at most
+// perHost of them to the same host. Values below 1 are treated as 1.
+func NewLimiter(total, perHost int) *Limiter {
+	total = max(total, 1)
+	perHost = min(max(perHost, 1), total)
+	return &Limiter{
+		total:   make(chan struct{}, total),
+		perHost: perHost,
+		hosts:   make(map[string]chan struct{}),
+	}
+}
+
+// acquire blocks until a slot for host is free, and returns the function
+// that frees it.
+func (l *Limiter) acquire(ctx context.Context, host string) (release func(), err error) {
+	if l == nil {
+		return func() {}, nil
+	}
+	l.mu.Lock()
+	hostSem, ok := l.hosts[host]
+	if !ok {
+		hostSem = make(chan struct{}, l.perHost)
+		l.hosts[host] = hostSem
+	}
+	l.mu.Unlock()
+
+	// Take the host slot first so that requests queued for a busy host do
+	// not hold overall slots other hosts could use.
+	select {
+	case hostSem <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	select {
+	case l.total <- struct{}{}:
+	case <-ctx.Done():
+		<-hostSem
+		return nil, ctx.Err()
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			<-l.total
+			<-hostSem
+		})
+	}, nil
+}
+
+// releaseBody frees its slots when the body is closed or fully read.
+type releaseBody struct {
+	io.ReadCloser
+	release func()
+}
+
+func (b *releaseBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err == io.EOF {
+		b.release()
+	}
+	return n, err
+}
+
+func (b *releaseBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.release()
+	return err
+}
@@ -0,0 +1,139 @@
+package httpclient
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// countingServer records the most requests it served at once.
+func countingServer(t *testing.T) (*httptest.Server, *int32) {
+	t.Helper()
+	var inFlight, peak int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		atomic.AddInt32(&inFlight, -1)
+		_, _ = w.Write([]byte("ok"))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &peak
+}
+
+func getAll(t *testing.T, client *http.Client, urls []string) {
+	t.Helper()
+	var wg sync.WaitGroup
+	for _, u := range urls {
+		wg.Add(1)
+		go func(u string) {
+			defer wg.Done()
+			resp, err := client.Get(u)
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+				return
+			}
+			_, _ = io.ReadAll(resp.Body)
+			_ = resp.Body.Close()
+		}(u)
+	}
+	wg.Wait()
+}
+
+func TestLimiterPerHost(t *testing.T) {
+	srv, peak := countingServer(t)
+	tr := &Transport{Base: http.DefaultTransport, Limiter: NewLimiter(10, 2)}
+
+	urls := make([]string, 8)
+	for i := range urls {
+		urls[i] = srv.URL
+	}
+	getAll(t, &http.Client{Transport: tr}, urls)
+
+	if *peak > 2 {
+		t.Errorf("got %d requests in flight to one host, want at most 2", *peak)
+	}
+}
+
+func TestLimiterTotal(t *testing.T) {
+	a, peakA := countingServer(t)
+	b, peakB := countingServer(t)
+	tr := &Transport{Base: http.DefaultTransport, Limiter: NewLimiter(1, 8)}
+
+	var urls []string
+	for range 4 {
+		urls = append(urls, a.URL, b.URL)
+	}
+	getAll(t, &http.Client{Transport: tr}, urls)
+
+	// With a single slot, the two hosts never see a request at once, so
+	// neither sees more than one.
+	if *peakA > 1 || *peakB > 1 {
+		t.Errorf("peaks: got %d and %d, want at most 1 each", *peakA, *peakB)
+	}
+}
+
+func TestLimiterReleasesOnEOF(t *testing.T) {
+	srv, _ := countingServer(t)
+	tr := &Transport{Base: http.DefaultTransport, Limiter: NewLimiter(1, 1)}
+	client := &http.Client{Transport: tr}
+
+	// Reading the body to the end frees the slot even if it is never
+	// closed, so the second request does not block.
+	resp, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.ReadAll(resp.Body)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, "GET", srv.URL, nil)
+	resp2, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("second request: %v", err)
+	}
+	_ = resp2.Body.Close()
+}
+
+func TestLimiterContextCanceled(t *testing.T) {
+	l := NewLimiter(1, 1)
+	release, err := l.acquire(context.Background(), "example.com")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := l.acquire(ctx, "example.com"); err == nil {
+		t.Error("expected an error while the only slot is held")
+	}
+
+	release()
+	release()
+	if r, err := l.acquire(context.Background(), "example.com"); err != nil {
+		t.Errorf("slot not freed: %v", err)
+	} else {
+		r()
+	}
+}
+
+func TestNilLimiter(t *testing.T) {
+	var l *Limiter
+	release, err := l.acquire(context.Background(), "example.com")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	release()
+}
@@ -0,0 +1,102 @@
+package httpclient
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// HostStats is the traffic to one API host, and its quota when the API
+// reports one in X-RateLimit-* headers.
+type HostStats struct {
+	Host     string
+	Requests int
+	Retries  int
+	Waited   time.Duration
+	// Quotas is keyed by X-RateLimit-Resource ("core", "search", ...), or
+	// "" when the API has a single quota.
+	Quotas map[string]Quota
+}
+
+// Quota is the last rate limit state an API reported.
+type Quota struct {
+	Limit     int
+	Remaining int
+	Reset     time.Time
+}
+
+// Stats collects HostStats. The zero value is not usable; a nil *Stats
+// records nothing.
+type Stats struct {
+	mu    sync.Mutex
+	hosts map[string]*HostStats
+}
+
+// NewStats returns an empty Stats.
+func NewStats() *Stats {
+	return &Stats{hosts: make(map[string]*HostStats)}
+}
+
+// Hosts returns a copy of the stats of every host contacted, sorted by host.
+func (s *Stats) Hosts() []HostStats {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]HostStats, 0, len(s.hosts))
+	for _, h := range s.hosts {
+		c := *h
+		c.Quotas = make(map[string]Quota, len(h.Quotas))
+		for k, v := range h.Quotas {
+			c.Quotas[k] = v
+		}
+		out = append(out, c)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Host < out[j].Host })
+	return out
+}
+
+func (s *Stats) host(name string) *HostStats {
+	h, ok := s.hosts[name]
+	if !ok {
+		h = &HostStats{Host: name, Quotas: make(map[string]Quota)}
+		s.hosts[name] = h
+	}
+	return h
+}
+
+// observe records a request to host and the quota in resp, if any.
+func (s *Stats) observe(host string, resp *http.Response) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	h := s.host(host)
+	h.Requests++
+	if resp == nil {
+		return
+	}
+	remaining, err := strconv.Atoi(resp.Header.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return
+	}
+	q := Quota{Remaining: remaining}
+	q.Limit, _ = strconv.Atoi(resp.Header.Get("X-RateLimit-Limit"))
+	q.Reset, _ = parseReset(resp.Header.Get("X-RateLimit-Reset"), time.Now())
+	h.Quotas[resp.Header.Get("X-RateLimit-Resource")] = q
+}
+
+func (s *Stats) retried(host string, waited time.Duration) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	h := s.host(host)
+	h.Retries++
+	h.Waited += waited
+}
@@ -0,0 +1,51 @@
+package lockfile
+
+import (
+	"strings"
+
+	"github.com/yutakobayashidev/repiq/internal/toml"
+)
+
+// parseCargoLock handles Cargo.lock. Packages without a source are the
+// workspace's own crates; their dependencies are the direct ones. Crates
+// from git sources are walked but not reported.
+func parseCargoLock(_ string, data []byte) ([]Package, error) {
+	doc, err := toml.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+
+	g := newGraph("crates", nil)
+	var roots []string
+	pkgs, _ := doc["package"].([]any)
+	for _, p := range pkgs {
+		pkg, _ := p.(map[string]any)
+		name, _ := pkg["name"].(string)
+		if name == "" {
+			continue
+		}
+		g.addNode(name)
+
+		source, _ := pkg["source"].(string)
+		switch {
+		case source == "":
+			roots = append(roots, name)
+		case !strings.HasPrefix(source, "registry+") && !strings.HasPrefix(source, "sparse+"):
+			g.exclude(name)
+		}
+
+		deps, _ := pkg["dependencies"].([]any)
+		for _, d := range deps {
+			// "name", "name version" or "name version (source)"
+			if s, ok := d.(string); ok {
+				if fields := strings.Fields(s); len(fields) > 0 {
+					g.addEdge(name, fields[0])
+				}
+			}
+		}
+	}
+	for _, name := range roots {
+		g.removeRoot(name)
+	}
+	return g.resolve(), nil
+}
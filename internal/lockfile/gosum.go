@@ -0,0 +1,31 @@
+package lockfile
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+)
+
+// parseGoSum handles go.sum. Only modules whose source was downloaded are
+// reported (entries that merely pin a go.mod file are skipped). go.sum
+// records no dependency graph, so direct modules come from the sibling
+// go.mod and transitive modules have no path.
+func parseGoSum(path string, data []byte) ([]Package, error) {
+	g := newGraph("go", nil)
+	g.inferRoots = false
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 3 || strings.HasSuffix(fields[1], "/go.mod") {
+			continue
+		}
+		g.addNode(fields[0])
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	g.directsFromManifest(path, "go.mod")
+	return g.resolve(), nil
+}
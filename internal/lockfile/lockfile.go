@@ -0,0 +1,248 @@
+// Package lockfile reads resolved dependency sets from lockfiles
+// (package-lock.json, pnpm-lock.yaml, yarn.lock, Cargo.lock, go.sum,
+// poetry.lock, uv.lock) and reports every package, direct or transitive,
+// together with the shortest path through which it is pulled in.
+package lockfile
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/yutakobayashidev/repiq/internal/manifest"
+	"github.com/yutakobayashidev/repiq/internal/provider"
+)
+
+// Package is a resolved dependency found in a lockfile.
+type Package struct {
+	Target provider.Target
+	// Direct is true when the project itself depends on the package.
+	Direct bool
+	// Path lists package names from a direct dependency down to (and
+	// including) this package. It is empty when the lockfile records no
+	// dependency graph (go.sum).
+	Path []string
+}
+
+// Supported reports whether path names a lockfile format Parse understands.
+func Supported(path string) bool {
+	return parserFor(filepath.Base(path)) != nil
+}
+
+// Parse reads the lockfile at path and returns every resolved package,
+// de-duplicated by name. Direct dependencies come first, followed by
+// transitive ones, each group sorted by name.
+//
+// Formats that do not record the project's own requirements (yarn.lock,
+// go.sum, poetry.lock) consult the sibling manifest (package.json, go.mod,
+// pyproject.toml) when present.
+func Parse(path string) ([]Package, error) {
+	parse := parserFor(filepath.Base(path))
+	if parse == nil {
+		return nil, fmt.Errorf("unsupported lockfile %q", path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	pkgs, err := parse(path, data)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return pkgs, nil
+}
+
+type parseFunc func(path string, data []byte) ([]Package, error)
+
+func parserFor(base string) parseFunc {
+	switch base {
+	case "package-lock.json", "npm-shrinkwrap.json":
+		return parsePackageLock
+	case "pnpm-lock.yaml":
+		return parsePnpmLock
+	case "yarn.lock":
+		return parseYarnLock
+	case "Cargo.lock":
+		return parseCargoLock
+	case "go.sum":
+		return parseGoSum
+	case "poetry.lock":
+		return parsePoetryLock
+	case "uv.lock":
+		return parseUVLock
+	}
+	return nil
+}
+
+// graph is a dependency graph keyed by (normalized) package name.
+type graph struct {
+	scheme    string
+	normalize func(string) string
+	names     map[string]string          // key -> display name
+	edges     map[string]map[string]bool // key -> dependency keys
+	direct    map[string]bool
+	// excluded nodes take part in path finding but are not reported
+	// (e.g. git or path dependencies that no registry serves).
+	excluded map[string]bool
+	// inferRoots treats packages without dependents as direct when the
+	// lockfile and manifest name no direct dependencies.
+	inferRoots bool
+}
+
+func newGraph(scheme string, normalize func(string) string) *graph {
+	if normalize == nil {
+		normalize = func(s string) string { return s }
+	}
+	return &graph{
+		scheme:     scheme,
+		normalize:  normalize,
+		names:      make(map[string]string),
+		edges:      make(map[string]map[string]bool),
+		direct:     make(map[string]bool),
+		excluded:   make(map[string]bool),
+		inferRoots: true,
+	}
+}
+
+func (g *graph) addNode(name string) string {
+	key := g.normalize(name)
+	if _, ok := g.names[key]; !ok {
+		g.names[key] = name
+	}
+	return key
+}
+
+// declare adds a node using name as its display name, overriding any
+// spelling seen earlier in a dependency reference.
+func (g *graph) declare(name string) {
+	g.names[g.normalize(name)] = name
+}
+
+func (g *graph) addEdge(from, to string) {
+	f, t := g.addNode(from), g.addNode(to)
+	if g.edges[f] == nil {
+		g.edges[f] = make(map[string]bool)
+	}
+	g.edges[f][t] = true
+}
+
+func (g *graph) addDirect(name string) {
+	g.direct[g.addNode(name)] = true
+}
+
+func (g *graph) exclude(name string) {
+	g.excluded[g.addNode(name)] = true
+}
+
+// removeRoot drops a project root (e.g. a workspace member) from the graph
+// and promotes its dependencies to direct ones.
+func (g *graph) removeRoot(name string) {
+	key := g.normalize(name)
+	for dep := range g.edges[key] {
+		if dep != key {
+			g.direct[dep] = true
+		}
+	}
+	delete(g.names, key)
+	delete(g.edges, key)
+	delete(g.direct, key)
+	for _, deps := range g.edges {
+		delete(deps, key)
+	}
+}
+
+// directsFromManifest marks the dependencies declared by a sibling manifest
+// as direct. It reports false if no manifest was found.
+func (g *graph) directsFromManifest(lockPath, manifestName string) bool {
+	path := filepath.Join(filepath.Dir(lockPath), manifestName)
+	if _, err := os.Stat(path); err != nil {
+		return false
+	}
+	targets, err := manifest.Parse(path)
+	if err != nil {
+		return false
+	}
+	for _, t := range targets {
+		if _, ok := g.names[g.normalize(t.Identifier)]; ok {
+			g.addDirect(t.Identifier)
+		}
+	}
+	return true
+}
+
+// resolve walks the graph breadth-first from the direct dependencies. When
+// no direct dependencies are known and inferRoots is set, packages nothing
+// else depends on are treated as direct.
+func (g *graph) resolve() []Package {
+	if len(g.direct) == 0 && g.inferRoots {
+		incoming := make(map[string]bool)
+		for from, deps := range g.edges {
+			for to := range deps {
+				if to != from {
+					incoming[to] = true
+				}
+			}
+		}
+		for key := range g.names {
+			if !incoming[key] {
+				g.direct[key] = true
+			}
+		}
+	}
+
+	paths := make(map[string][]string)
+	queue := sortedKeys(g.direct)
+	for _, key := range queue {
+		paths[key] = []string{g.names[key]}
+	}
+	for len(queue) > 0 {
+		key := queue[0]
+		queue = queue[1:]
+		for _, dep := range sortedKeys(g.edges[key]) {
+			if _, seen := paths[dep]; seen {
+				continue
+			}
+			p := append(append([]string{}, paths[key]...), g.names[dep])
+			paths[dep] = p
+			queue = append(queue, dep)
+		}
+	}
+
+	keys := sortedKeys(g.names)
+	sort.SliceStable(keys, func(i, j int) bool {
+		return g.direct[keys[i]] && !g.direct[keys[j]]
+	})
+	pkgs := make([]Package, 0, len(keys))
+	for _, key := range keys {
+		if g.excluded[key] {
+			continue
+		}
+		pkgs = append(pkgs, Package{
+			Target: provider.Target{Scheme: g.scheme, Identifier: g.names[key]},
+			Direct: g.direct[key],
+			Path:   paths[key],
+		})
+	}
+	return pkgs
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// splitNameVersion splits "name@version" where name may be scoped
+// ("@scope/name@1.0.0"). The version part may itself contain '@'
+// (e.g. yarn's "pkg@patch:pkg@npm%3A1.0.0").
+func splitNameVersion(s string) (name, version string) {
+	if i := strings.Index(s[min(1, len(s)):], "@"); i >= 0 {
+		return s[:i+1], s[i+2:]
+	}
+	return s, ""
+}
@@ -0,0 +1,415 @@
+package lockfile
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write %s: %v", name, err)
+	}
+	return path
+}
+
+// summary renders packages as "name direct|a > b" lines for comparison.
+func summary(pkgs []Package) []string {
+	lines := make([]string, len(pkgs))
+	for i, p := range pkgs {
+		rel := "transitive"
+		if p.Direct {
+			rel = "direct"
+		}
+		lines[i] = p.Target.String() + " " + rel + " " + strings.Join(p.Path, " > ")
+	}
+	return lines
+}
+
+func assertSummary(t *testing.T, pkgs []Package, want []string) {
+	t.Helper()
+	if got := summary(pkgs); !reflect.DeepEqual(got, want) {
+		t.Errorf("got:\n  %s\nwant:\n  %s", strings.Join(got, "\n  "), strings.Join(want, "\n  "))
+	}
+}
+
+func TestParsePackageLockV3(t *testing.T) {
+	path := writeFile(t, t.TempDir(), "package-lock.json", `{
+  "lockfileVersion": 3,
+  "packages": {
+    "": {"name": "app", "dependencies": {"express": "^4"}, "devDependencies": {"typescript": "^5"}},
+    "packages/lib": {"name": "lib", "dependencies": {"ms": "^2"}},
+    "node_modules/lib": {"resolved": "packages/lib", "link": true},
+    "node_modules/express": {"version": "4.19.0", "dependencies": {"body-parser": "1.20.2", "debug": "2.6.9"}},
+    "node_modules/body-parser": {"version": "1.20.2", "dependencies": {"debug": "2.6.9", "bytes": "3.1.2"}},
+    "node_modules/debug": {"version": "2.6.9", "dependencies": {"ms": "2.0.0"}},
+    "node_modules/debug/node_modules/ms": {"version": "2.0.0"},
+    "node_modules/ms": {"version": "2.1.3"},
+    "node_modules/bytes": {"version": "3.1.2"},
+    "node_modules/typescript": {"version": "5.4.0", "dev": true}
+  }
+}`)
+	pkgs, err := Parse(path)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	assertSummary(t, pkgs, []string{
+		"npm:express direct express",
+		"npm:ms direct ms",
+		"npm:typescript direct typescript",
+		"npm:body-parser transitive express > body-parser",
+		"npm:bytes transitive express > body-parser > bytes",
+		"npm:debug transitive express > debug",
+	})
+}
+
+func TestParsePackageLockV1UsesManifest(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "package.json", `{"dependencies": {"a": "^1"}}`)
+	path := writeFile(t, dir, "package-lock.json", `{
+  "lockfileVersion": 1,
+  "dependencies": {
+    "a": {"version": "1.0.0", "requires": {"b": "^1"}},
+    "b": {"version": "1.0.0", "dependencies": {"c": {"version": "1.0.0"}}, "requires": {"c": "^1"}}
+  }
+}`)
+	pkgs, err := Parse(path)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	assertSummary(t, pkgs, []string{
+		"npm:a direct a",
+		"npm:b transitive a > b",
+		"npm:c transitive a > b > c",
+	})
+}
+
+func TestParsePnpmLockV9(t *testing.T) {
+	path := writeFile(t, t.TempDir(), "pnpm-lock.yaml", `lockfileVersion: '9.0'
+
+importers:
+
+  .:
+    dependencies:
+      react-dom:
+        specifier: ^18.2.0
+        version: 18.2.0(react@18.2.0)
+      local:
+        specifier: link:../local
+        version: link:../local
+
+packages:
+
+  '@types/prop-types@15.7.0':
+    resolution: {integrity: sha512-x}
+
+  react-dom@18.2.0:
+    resolution: {integrity: sha512-y}
+
+snapshots:
+
+  '@types/prop-types@15.7.0': {}
+
+  react-dom@18.2.0(react@18.2.0):
+    dependencies:
+      react: 18.2.0
+      scheduler: 0.23.0
+
+  react@18.2.0:
+    dependencies:
+      loose-envify: 1.4.0
+
+  scheduler@0.23.0:
+    dependencies:
+      loose-envify: 1.4.0
+`)
+	pkgs, err := Parse(path)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	assertSummary(t, pkgs, []string{
+		"npm:react-dom direct react-dom",
+		"npm:@types/prop-types transitive ",
+		"npm:loose-envify transitive react-dom > react > loose-envify",
+		"npm:react transitive react-dom > react",
+		"npm:scheduler transitive react-dom > scheduler",
+	})
+}
+
+func TestParsePnpmLockV5(t *testing.T) {
+	path := writeFile(t, t.TempDir(), "pnpm-lock.yaml", `lockfileVersion: 5.4
+
+specifiers:
+  '@scope/pkg': ^1.0.0
+
+dependencies:
+  '@scope/pkg': 1.0.0_peer@2.0.0
+
+packages:
+
+  /@scope/pkg/1.0.0_peer@2.0.0:
+    dependencies:
+      peer: 2.0.0
+
+  /peer/2.0.0:
+    dev: false
+`)
+	pkgs, err := Parse(path)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	assertSummary(t, pkgs, []string{
+		"npm:@scope/pkg direct @scope/pkg",
+		"npm:peer transitive @scope/pkg > peer",
+	})
+}
+
+func TestParseYarnClassic(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "package.json", `{"dependencies": {"chalk": "^4"}}`)
+	path := writeFile(t, dir, "yarn.lock", `# THIS IS AN AUTOGENERATED FILE. DO NOT EDIT THIS FILE DIRECTLY.
+# yarn lockfile v1
+
+
+"@babel/code-frame@^7.0.0":
+  version "7.24.0"
+  dependencies:
+    "@babel/highlight" "^7.24.0"
+
+"@babel/highlight@^7.24.0":
+  version "7.24.0"
+  dependencies:
+    chalk "^2.4.2"
+
+chalk@^2.4.2, chalk@^4:
+  version "4.1.2"
+  resolved "https://registry.yarnpkg.com/chalk/-/chalk-4.1.2.tgz"
+  dependencies:
+    supports-color "^7.1.0"
+
+supports-color@^7.1.0:
+  version "7.2.0"
+`)
+	pkgs, err := Parse(path)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	assertSummary(t, pkgs, []string{
+		"npm:chalk direct chalk",
+		"npm:@babel/code-frame transitive ",
+		"npm:@babel/highlight transitive ",
+		"npm:supports-color transitive chalk > supports-color",
+	})
+}
+
+func TestParseYarnBerry(t *testing.T) {
+	path := writeFile(t, t.TempDir(), "yarn.lock", `__metadata:
+  version: 8
+  cacheKey: 10c0
+
+"app@workspace:.":
+  version: 0.0.0-use.local
+  resolution: "app@workspace:."
+  dependencies:
+    lodash: "npm:^4.17.21"
+  languageName: unknown
+  linkType: soft
+
+"lodash@npm:^4.17.21":
+  version: 4.17.21
+  resolution: "lodash@npm:4.17.21"
+  languageName: node
+  linkType: hard
+`)
+	pkgs, err := Parse(path)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	assertSummary(t, pkgs, []string{"npm:lodash direct lodash"})
+}
+
+func TestParseCargoLock(t *testing.T) {
+	path := writeFile(t, t.TempDir(), "Cargo.lock", `version = 3
+
+[[package]]
+name = "app"
+version = "0.1.0"
+dependencies = [
+ "forked",
+ "serde",
+]
+
+[[package]]
+name = "forked"
+version = "0.1.0"
+source = "git+https://github.com/me/forked#abc"
+dependencies = ["itoa"]
+
+[[package]]
+name = "itoa"
+version = "1.0.11"
+source = "registry+https://github.com/rust-lang/crates.io-index"
+
+[[package]]
+name = "serde"
+version = "1.0.200"
+source = "registry+https://github.com/rust-lang/crates.io-index"
+dependencies = [
+ "serde_derive 1.0.200 (registry+https://github.com/rust-lang/crates.io-index)",
+]
+
+[[package]]
+name = "serde_derive"
+version = "1.0.200"
+source = "registry+https://github.com/rust-lang/crates.io-index"
+`)
+	pkgs, err := Parse(path)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	assertSummary(t, pkgs, []string{
+		"crates:serde direct serde",
+		"crates:itoa transitive forked > itoa",
+		"crates:serde_derive transitive serde > serde_derive",
+	})
+}
+
+func TestParseGoSum(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "go.mod", `module example.com/app
+
+require (
+	github.com/google/go-github/v68 v68.0.0
+	github.com/google/go-querystring v1.1.0 // indirect
+)
+`)
+	path := writeFile(t, dir, "go.sum", `github.com/google/go-cmp v0.6.0/go.mod h1:abc=
+github.com/google/go-github/v68 v68.0.0 h1:def=
+github.com/google/go-github/v68 v68.0.0/go.mod h1:ghi=
+github.com/google/go-querystring v1.1.0 h1:jkl=
+github.com/google/go-querystring v1.1.0/go.mod h1:mno=
+`)
+	pkgs, err := Parse(path)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	assertSummary(t, pkgs, []string{
+		"go:github.com/google/go-github/v68 direct github.com/google/go-github/v68",
+		"go:github.com/google/go-querystring transitive ",
+	})
+}
+
+func TestParsePoetryLock(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "pyproject.toml", `[tool.poetry.dependencies]
+python = "^3.11"
+requests = "^2.31"
+`)
+	path := writeFile(t, dir, "poetry.lock", `[[package]]
+name = "certifi"
+version = "2024.2.2"
+
+[[package]]
+name = "requests"
+version = "2.31.0"
+
+[package.dependencies]
+certifi = ">=2017.4.17"
+charset-normalizer = ">=2,<4"
+
+[[package]]
+name = "charset-normalizer"
+version = "3.3.2"
+
+[[package]]
+name = "mylib"
+version = "0.1.0"
+
+[package.source]
+type = "directory"
+url = "../mylib"
+`)
+	pkgs, err := Parse(path)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	assertSummary(t, pkgs, []string{
+		"pypi:requests direct requests",
+		"pypi:certifi transitive requests > certifi",
+		"pypi:charset-normalizer transitive requests > charset-normalizer",
+	})
+}
+
+func TestParseUVLock(t *testing.T) {
+	path := writeFile(t, t.TempDir(), "uv.lock", `version = 1
+requires-python = ">=3.12"
+
+[[package]]
+name = "app"
+version = "0.1.0"
+source = { editable = "." }
+dependencies = [
+    { name = "httpx" },
+]
+
+[package.dev-dependencies]
+dev = [
+    { name = "pytest" },
+]
+
+[[package]]
+name = "httpx"
+version = "0.27.0"
+source = { registry = "https://pypi.org/simple" }
+dependencies = [
+    { name = "anyio" },
+    { name = "Certifi" },
+]
+
+[[package]]
+name = "anyio"
+version = "4.3.0"
+source = { registry = "https://pypi.org/simple" }
+
+[[package]]
+name = "certifi"
+version = "2024.2.2"
+source = { registry = "https://pypi.org/simple" }
+
+[[package]]
+name = "pytest"
+version = "8.1.1"
+source = { registry = "https://pypi.org/simple" }
+`)
+	pkgs, err := Parse(path)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	assertSummary(t, pkgs, []string{
+		"pypi:httpx direct httpx",
+		"pypi:pytest direct pytest",
+		"pypi:anyio transitive httpx > anyio",
+		"pypi:certifi transitive httpx > certifi",
+	})
+}
+
+func TestParseUnsupported(t *testing.T) {
+	path := writeFile(t, t.TempDir(), "Gemfile.lock", "GEM\n")
+	if Supported(path) {
+		t.Error("Gemfile.lock should not be supported")
+	}
+	if _, err := Parse(path); err == nil {
+		t.Fatal("expected error for unsupported lockfile")
+	}
+}
+
+func TestParseMalformed(t *testing.T) {
+	path := writeFile(t, t.TempDir(), "package-lock.json", "{")
+	if _, err := Parse(path); err == nil {
+		t.Fatal("expected error for malformed lockfile")
+	}
+}
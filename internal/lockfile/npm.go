@@ -0,0 +1,248 @@
+package lockfile
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/yutakobayashidev/repiq/internal/yaml"
+)
+
+// parsePackageLock handles package-lock.json / npm-shrinkwrap.json. v2 and
+// v3 lockfiles use the flat "packages" map; v1 falls back to the nested
+// "dependencies" tree.
+func parsePackageLock(path string, data []byte) ([]Package, error) {
+	type lockEntry struct {
+		Link                 bool              `json:"link"`
+		Dependencies         map[string]string `json:"dependencies"`
+		DevDependencies      map[string]string `json:"devDependencies"`
+		OptionalDependencies map[string]string `json:"optionalDependencies"`
+		PeerDependencies     map[string]string `json:"peerDependencies"`
+	}
+	var lock struct {
+		Packages     map[string]lockEntry `json:"packages"`
+		Dependencies map[string]v1Entry   `json:"dependencies"`
+	}
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return nil, err
+	}
+
+	g := newGraph("npm", nil)
+	if len(lock.Packages) == 0 {
+		addV1Entries(g, lock.Dependencies)
+		g.directsFromManifest(path, "package.json")
+		return g.resolve(), nil
+	}
+
+	var links []string
+	for key, entry := range lock.Packages {
+		idx := strings.LastIndex(key, "node_modules/")
+		if idx < 0 {
+			// The root project ("") or a workspace member.
+			for _, deps := range []map[string]string{entry.Dependencies, entry.DevDependencies, entry.OptionalDependencies} {
+				for name := range deps {
+					g.addDirect(name)
+				}
+			}
+			continue
+		}
+		name := key[idx+len("node_modules/"):]
+		if entry.Link {
+			links = append(links, name)
+			continue
+		}
+		g.addNode(name)
+		for _, deps := range []map[string]string{entry.Dependencies, entry.OptionalDependencies, entry.PeerDependencies} {
+			for dep := range deps {
+				g.addEdge(name, dep)
+			}
+		}
+	}
+	for _, name := range links {
+		g.removeRoot(name)
+	}
+	return g.resolve(), nil
+}
+
+type v1Entry struct {
+	Requires     map[string]string  `json:"requires"`
+	Dependencies map[string]v1Entry `json:"dependencies"`
+}
+
+func addV1Entries(g *graph, entries map[string]v1Entry) {
+	for name, entry := range entries {
+		g.addNode(name)
+		for dep := range entry.Requires {
+			g.addEdge(name, dep)
+		}
+		addV1Entries(g, entry.Dependencies)
+	}
+}
+
+// parsePnpmLock handles pnpm-lock.yaml versions 5 through 9.
+func parsePnpmLock(_ string, data []byte) ([]Package, error) {
+	v, err := yaml.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	doc, ok := v.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("unexpected document type %T", v)
+	}
+
+	major := 0
+	if s := fmt.Sprint(doc["lockfileVersion"]); s != "" {
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			major = int(f)
+		}
+	}
+
+	g := newGraph("npm", nil)
+	addDirects := func(importer map[string]any) {
+		for _, field := range []string{"dependencies", "devDependencies", "optionalDependencies"} {
+			deps, _ := importer[field].(map[string]any)
+			for name, spec := range deps {
+				if !isPnpmLink(spec) {
+					g.addDirect(name)
+				}
+			}
+		}
+	}
+	if importers, ok := doc["importers"].(map[string]any); ok {
+		for _, imp := range importers {
+			if importer, ok := imp.(map[string]any); ok {
+				addDirects(importer)
+			}
+		}
+	} else {
+		addDirects(doc)
+	}
+
+	for _, section := range []string{"packages", "snapshots"} {
+		entries, _ := doc[section].(map[string]any)
+		for key, e := range entries {
+			name := pnpmPackageName(key, major)
+			g.addNode(name)
+			entry, _ := e.(map[string]any)
+			for _, field := range []string{"dependencies", "optionalDependencies"} {
+				deps, _ := entry[field].(map[string]any)
+				for dep, spec := range deps {
+					if !isPnpmLink(spec) {
+						g.addEdge(name, dep)
+					}
+				}
+			}
+		}
+	}
+	return g.resolve(), nil
+}
+
+func isPnpmLink(spec any) bool {
+	version := spec
+	if m, ok := spec.(map[string]any); ok {
+		version = m["version"]
+	}
+	s, _ := version.(string)
+	return strings.HasPrefix(s, "link:") || strings.HasPrefix(s, "file:")
+}
+
+// pnpmPackageName extracts the package name from a packages/snapshots key:
+// "/name/1.0.0_peer@2" (v5), "/name@1.0.0(peer@2)" (v6) or
+// "name@1.0.0(peer@2)" (v9).
+func pnpmPackageName(key string, major int) string {
+	key = strings.TrimPrefix(key, "/")
+	if i := strings.Index(key, "("); i >= 0 {
+		key = key[:i]
+	}
+	if major > 0 && major < 6 {
+		if i := strings.LastIndex(key, "/"); i > 0 {
+			return key[:i]
+		}
+		return key
+	}
+	name, _ := splitNameVersion(key)
+	return name
+}
+
+// parseYarnLock handles both the classic (v1) format and the YAML-based
+// Berry format. Direct dependencies come from the sibling package.json, or
+// from workspace entries in Berry lockfiles.
+func parseYarnLock(path string, data []byte) ([]Package, error) {
+	g := newGraph("npm", nil)
+	if bytes.Contains(data, []byte("__metadata:")) {
+		if err := addYarnBerry(g, data); err != nil {
+			return nil, err
+		}
+	} else if err := addYarnClassic(g, data); err != nil {
+		return nil, err
+	}
+	g.directsFromManifest(path, "package.json")
+	return g.resolve(), nil
+}
+
+func addYarnClassic(g *graph, data []byte) error {
+	var current string
+	inDeps := false
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		raw := scanner.Text()
+		trimmed := strings.TrimSpace(raw)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		indent := len(raw) - len(strings.TrimLeft(raw, " "))
+
+		switch {
+		case indent == 0:
+			// "name@range", "name@other-range":
+			first := strings.TrimSuffix(strings.SplitN(trimmed, ",", 2)[0], ":")
+			current, _ = splitNameVersion(strings.Trim(first, `"`))
+			g.addNode(current)
+			inDeps = false
+		case indent == 2:
+			inDeps = trimmed == "dependencies:" || trimmed == "optionalDependencies:"
+		case indent == 4 && inDeps && current != "":
+			fields := strings.Fields(trimmed)
+			if len(fields) < 2 {
+				return fmt.Errorf("malformed dependency line %q", trimmed)
+			}
+			g.addEdge(current, strings.Trim(fields[0], `"`))
+		}
+	}
+	return scanner.Err()
+}
+
+func addYarnBerry(g *graph, data []byte) error {
+	v, err := yaml.Parse(data)
+	if err != nil {
+		return err
+	}
+	doc, _ := v.(map[string]any)
+
+	var workspaces []string
+	for key, e := range doc {
+		if key == "__metadata" {
+			continue
+		}
+		first := strings.TrimSpace(strings.SplitN(key, ",", 2)[0])
+		name, version := splitNameVersion(first)
+		g.addNode(name)
+		if strings.HasPrefix(version, "workspace:") {
+			workspaces = append(workspaces, name)
+		}
+		entry, _ := e.(map[string]any)
+		for _, field := range []string{"dependencies", "optionalDependencies"} {
+			deps, _ := entry[field].(map[string]any)
+			for dep := range deps {
+				g.addEdge(name, dep)
+			}
+		}
+	}
+	for _, name := range workspaces {
+		g.removeRoot(name)
+	}
+	return nil
+}
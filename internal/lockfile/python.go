@@ -0,0 +1,94 @@
+package lockfile
+
+import (
+	"github.com/yutakobayashidev/repiq/internal/manifest"
+	"github.com/yutakobayashidev/repiq/internal/toml"
+)
+
+// parsePoetryLock handles poetry.lock. Direct dependencies come from the
+// sibling pyproject.toml. Packages installed from git, a directory, a file
+// or a URL are walked but not reported.
+func parsePoetryLock(path string, data []byte) ([]Package, error) {
+	doc, err := toml.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+
+	g := newGraph("pypi", manifest.NormalizePython)
+	pkgs, _ := doc["package"].([]any)
+	for _, p := range pkgs {
+		pkg, _ := p.(map[string]any)
+		name, _ := pkg["name"].(string)
+		if name == "" {
+			continue
+		}
+		g.declare(name)
+		if source, ok := pkg["source"].(map[string]any); ok {
+			if typ, _ := source["type"].(string); typ != "" && typ != "legacy" {
+				g.exclude(name)
+			}
+		}
+		deps, _ := pkg["dependencies"].(map[string]any)
+		for dep := range deps {
+			g.addEdge(name, dep)
+		}
+	}
+
+	g.directsFromManifest(path, "pyproject.toml")
+	return g.resolve(), nil
+}
+
+// parseUVLock handles uv.lock. Editable and virtual packages are the
+// project's own; their dependencies (including optional and dev groups) are
+// the direct ones. Non-registry sources are walked but not reported.
+func parseUVLock(_ string, data []byte) ([]Package, error) {
+	doc, err := toml.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+
+	g := newGraph("pypi", manifest.NormalizePython)
+	var roots []string
+	pkgs, _ := doc["package"].([]any)
+	for _, p := range pkgs {
+		pkg, _ := p.(map[string]any)
+		name, _ := pkg["name"].(string)
+		if name == "" {
+			continue
+		}
+		g.declare(name)
+
+		source, _ := pkg["source"].(map[string]any)
+		_, editable := source["editable"]
+		_, virtual := source["virtual"]
+		_, registry := source["registry"]
+		switch {
+		case editable || virtual:
+			roots = append(roots, name)
+		case !registry:
+			g.exclude(name)
+		}
+
+		addUVDeps(g, name, pkg["dependencies"])
+		for _, field := range []string{"optional-dependencies", "dev-dependencies"} {
+			groups, _ := pkg[field].(map[string]any)
+			for _, deps := range groups {
+				addUVDeps(g, name, deps)
+			}
+		}
+	}
+	for _, name := range roots {
+		g.removeRoot(name)
+	}
+	return g.resolve(), nil
+}
+
+func addUVDeps(g *graph, from string, deps any) {
+	list, _ := deps.([]any)
+	for _, d := range list {
+		dep, _ := d.(map[string]any)
+		if name, _ := dep["name"].(string); name != "" {
+			g.addEdge(from, name)
+		}
+	}
+}
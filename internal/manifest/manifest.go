@@ -0,0 +1,388 @@
+// Package manifest extracts direct dependencies from package manifests
+// (package.json, go.mod, Cargo.toml, requirements.txt, pyproject.toml)
+// and converts them into provider targets.
+package manifest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/yutakobayashidev/repiq/internal/provider"
+	"github.com/yutakobayashidev/repiq/internal/toml"
+)
+
+var (
+	// pep508NameRe matches the distribution name at the start of a PEP 508
+	// requirement string (e.g. "requests[socks]>=2.0; python_version>'3'")
+	// and what follows its extras.
+	pep508NameRe = regexp.MustCompile(`^\s*([A-Za-z0-9][A-Za-z0-9._-]*)\s*(?:\[[^\]]*\])?\s*(.*)$`)
+	// pythonNameSepRe matches the runs of separators PEP 503 folds into
+	// "-".
+	pythonNameSepRe = regexp.MustCompile(`[-_.]+`)
+)
+
+// Supported reports whether path names a manifest format Parse understands.
+func Supported(path string) bool {
+	return parserFor(filepath.Base(path)) != nil
+}
+
+// Parse reads the manifest at path and returns its direct dependencies as
+// targets, sorted by identifier. The format is chosen by file name.
+func Parse(path string) ([]provider.Target, error) {
+	parse := parserFor(filepath.Base(path))
+	if parse == nil {
+		return nil, fmt.Errorf("unsupported manifest %q", path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	targets, err := parse(path, data)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return targets, nil
+}
+
+type parseFunc func(path string, data []byte) ([]provider.Target, error)
+
+func parserFor(base string) parseFunc {
+	switch {
+	case base == "package.json":
+		return ParsePackageJSON
+	case base == "go.mod":
+		return ParseGoMod
+	case base == "Cargo.toml":
+		return ParseCargoToml
+	case base == "pyproject.toml":
+		return ParsePyprojectToml
+	case strings.HasPrefix(base, "requirements") && strings.HasSuffix(base, ".txt"):
+		return ParseRequirementsTxt
+	}
+	return nil
+}
+
+// ParsePackageJSON extracts dependencies, devDependencies and
+// optionalDependencies. Local (file:, link:, workspace:) and VCS specs are
+// skipped; npm: aliases resolve to the aliased package.
+func ParsePackageJSON(_ string, data []byte) ([]provider.Target, error) {
+	var pkg struct {
+		Dependencies         map[string]string `json:"dependencies"`
+		DevDependencies      map[string]string `json:"devDependencies"`
+		OptionalDependencies map[string]string `json:"optionalDependencies"`
+	}
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, deps := range []map[string]string{pkg.Dependencies, pkg.DevDependencies, pkg.OptionalDependencies} {
+		for name, spec := range deps {
+			if resolved, ok := npmPackageName(name, spec); ok {
+				names = append(names, resolved)
+			}
+		}
+	}
+	return toTargets("npm", names), nil
+}
+
+// npmPackageName returns the registry package a dependency entry refers to,
+// or false when the spec points somewhere other than the npm registry.
+func npmPackageName(name, spec string) (string, bool) {
+	spec = strings.TrimSpace(spec)
+	if alias, ok := strings.CutPrefix(spec, "npm:"); ok {
+		// npm:pkg@range or npm:@scope/pkg@range
+		if at := strings.LastIndex(alias, "@"); at > 0 {
+			alias = alias[:at]
+		}
+		return alias, alias != ""
+	}
+	if strings.Contains(spec, ":") {
+		// file:, link:, workspace:, git+https:, github:, http(s): ...
+		return "", false
+	}
+	if strings.Contains(spec, "/") {
+		// GitHub shorthand (user/repo).
+		return "", false
+	}
+	return name, true
+}
+
+// ParseGoMod extracts require directives, skipping // indirect entries.
+func ParseGoMod(_ string, data []byte) ([]provider.Target, error) {
+	var names []string
+	inBlock := false
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		indirect := strings.Contains(line, "// indirect")
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+
+		switch {
+		case inBlock && line == ")":
+			inBlock = false
+			continue
+		case line == "require (":
+			inBlock = true
+			continue
+		case strings.HasPrefix(line, "require "):
+			line = strings.TrimSpace(strings.TrimPrefix(line, "require"))
+		case !inBlock:
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) < 2 || indirect {
+			continue
+		}
+		names = append(names, strings.Trim(fields[0], `"`))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return toTargets("go", names), nil
+}
+
+// ParseCargoToml extracts [dependencies], [dev-dependencies],
+// [build-dependencies], their [target.*] variants and
+// [workspace.dependencies]. Path and git dependencies are skipped.
+func ParseCargoToml(_ string, data []byte) ([]provider.Target, error) {
+	doc, err := toml.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var tables []map[string]any
+	collect := func(parent map[string]any) {
+		for _, key := range []string{"dependencies", "dev-dependencies", "build-dependencies"} {
+			if t, ok := parent[key].(map[string]any); ok {
+				tables = append(tables, t)
+			}
+		}
+	}
+	collect(doc)
+	if targets, ok := doc["target"].(map[string]any); ok {
+		for _, v := range targets {
+			if t, ok := v.(map[string]any); ok {
+				collect(t)
+			}
+		}
+	}
+	if ws, ok := doc["workspace"].(map[string]any); ok {
+		collect(ws)
+	}
+
+	var names []string
+	for _, deps := range tables {
+		for name, spec := range deps {
+			if resolved, ok := crateName(name, spec); ok {
+				names = append(names, resolved)
+			}
+		}
+	}
+	return toTargets("crates", names), nil
+}
+
+func crateName(name string, spec any) (string, bool) {
+	detail, ok := spec.(map[string]any)
+	if !ok {
+		return name, true
+	}
+	if _, isGit := detail["git"]; isGit {
+		return "", false
+	}
+	_, hasPath := detail["path"]
+	_, hasVersion := detail["version"]
+	if hasPath && !hasVersion {
+		return "", false
+	}
+	if renamed, ok := detail["package"].(string); ok && renamed != "" {
+		return renamed, true
+	}
+	return name, true
+}
+
+// ParseRequirementsTxt extracts requirement lines. Nested -r/--requirement
+// files are followed relative to path; other options, editable installs
+// and bare URLs are skipped.
+func ParseRequirementsTxt(path string, data []byte) ([]provider.Target, error) {
+	names, err := requirementNames(path, data, map[string]bool{})
+	if err != nil {
+		return nil, err
+	}
+	return toTargets("pypi", names), nil
+}
+
+func requirementNames(path string, data []byte, seen map[string]bool) ([]string, error) {
+	seen[filepath.Clean(path)] = true
+
+	var names []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if i := strings.Index(line, " #"); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if nested, ok := requirementInclude(line); ok {
+			nestedPath := filepath.Join(filepath.Dir(path), nested)
+			if seen[filepath.Clean(nestedPath)] {
+				continue
+			}
+			nestedData, err := os.ReadFile(nestedPath)
+			if err != nil {
+				return nil, err
+			}
+			nestedNames, err := requirementNames(nestedPath, nestedData, seen)
+			if err != nil {
+				return nil, err
+			}
+			names = append(names, nestedNames...)
+			continue
+		}
+		if strings.HasPrefix(line, "-") {
+			continue
+		}
+		if name, ok := pep508Name(line); ok {
+			names = append(names, name)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return names, nil
+}
+
+func requirementInclude(line string) (string, bool) {
+	for _, prefix := range []string{"-r ", "--requirement ", "--requirement="} {
+		if rest, ok := strings.CutPrefix(line, prefix); ok {
+			return strings.TrimSpace(rest), true
+		}
+	}
+	if rest, ok := strings.CutPrefix(line, "-r"); ok && rest != "" && !strings.HasPrefix(rest, " ") {
+		return rest, true
+	}
+	return "", false
+}
+
+// ParsePyprojectToml extracts PEP 621 [project] dependencies and
+// optional-dependencies, PEP 735 [dependency-groups], and Poetry
+// dependency tables (excluding the python constraint).
+func ParsePyprojectToml(_ string, data []byte) ([]provider.Target, error) {
+	doc, err := toml.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	addRequirements := func(v any) {
+		list, _ := v.([]any)
+		for _, item := range list {
+			s, ok := item.(string)
+			if !ok {
+				// {include-group = "..."} entries in dependency groups.
+				continue
+			}
+			if name, ok := pep508Name(s); ok {
+				names = append(names, name)
+			}
+		}
+	}
+	addPoetry := func(v any) {
+		deps, _ := v.(map[string]any)
+		for name, spec := range deps {
+			if strings.EqualFold(name, "python") {
+				continue
+			}
+			if detail, ok := spec.(map[string]any); ok {
+				if _, isPath := detail["path"]; isPath {
+					continue
+				}
+				if _, isGit := detail["git"]; isGit {
+					continue
+				}
+			}
+			names = append(names, NormalizePython(name))
+		}
+	}
+
+	if project, ok := doc["project"].(map[string]any); ok {
+		addRequirements(project["dependencies"])
+		if optional, ok := project["optional-dependencies"].(map[string]any); ok {
+			for _, v := range optional {
+				addRequirements(v)
+			}
+		}
+	}
+	if groups, ok := doc["dependency-groups"].(map[string]any); ok {
+		for _, v := range groups {
+			addRequirements(v)
+		}
+	}
+	if tool, ok := doc["tool"].(map[string]any); ok {
+		if poetry, ok := tool["poetry"].(map[string]any); ok {
+			addPoetry(poetry["dependencies"])
+			addPoetry(poetry["dev-dependencies"])
+			if groups, ok := poetry["group"].(map[string]any); ok {
+				for _, g := range groups {
+					if group, ok := g.(map[string]any); ok {
+						addPoetry(group["dependencies"])
+					}
+				}
+			}
+		}
+	}
+	return toTargets("pypi", names), nil
+}
+
+// pep508Name returns the distribution name of a requirement, normalized
+// per PEP 503. URL requirements ("name @ https://...") are skipped like
+// bare URLs and paths, as what they install need not be the PyPI
+// distribution of that name.
+func pep508Name(req string) (string, bool) {
+	m := pep508NameRe.FindStringSubmatch(req)
+	if m == nil {
+		return "", false
+	}
+	// A name is followed by nothing, a version specifier or environment
+	// markers.
+	if rest := m[2]; rest != "" && !strings.ContainsRune("<>=!~(;,", rune(rest[0])) {
+		return "", false
+	}
+	return NormalizePython(m[1]), true
+}
+
+// NormalizePython returns the PEP 503 normalized form of a distribution
+// name, under which PyPI serves it.
+func NormalizePython(name string) string {
+	return strings.ToLower(pythonNameSepRe.ReplaceAllString(name, "-"))
+}
+
+// toTargets de-duplicates names and returns them as sorted targets.
+func toTargets(scheme string, names []string) []provider.Target {
+	seen := make(map[string]bool, len(names))
+	targets := make([]provider.Target, 0, len(names))
+	for _, n := range names {
+		if n == "" || seen[n] {
+			continue
+		}
+		seen[n] = true
+		targets = append(targets, provider.Target{Scheme: scheme, Identifier: n})
+	}
+	sort.Slice(targets, func(i, j int) bool {
+		return targets[i].Identifier < targets[j].Identifier
+	})
+	return targets
+}
@@ -0,0 +1,193 @@
+package manifest
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/yutakobayashidev/repiq/internal/provider"
+)
+
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write %s: %v", name, err)
+	}
+	return path
+}
+
+func identifiers(targets []provider.Target) []string {
+	ids := make([]string, len(targets))
+	for i, t := range targets {
+		ids[i] = t.Scheme + ":" + t.Identifier
+	}
+	return ids
+}
+
+func TestParsePackageJSON(t *testing.T) {
+	path := writeFile(t, t.TempDir(), "package.json", `{
+  "name": "app",
+  "dependencies": {
+    "react": "^19.0.0",
+    "@types/node": "22.x",
+    "local": "file:../local",
+    "ws-pkg": "workspace:*",
+    "forked": "github:me/forked",
+    "shorthand": "me/shorthand",
+    "aliased": "npm:string-width@^4"
+  },
+  "devDependencies": {"typescript": "^5", "react": "^19.0.0"},
+  "optionalDependencies": {"fsevents": "^2"},
+  "peerDependencies": {"react-dom": "^19"}
+}`)
+	targets, err := Parse(path)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	want := []string{"npm:@types/node", "npm:fsevents", "npm:react", "npm:string-width", "npm:typescript"}
+	if got := identifiers(targets); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestParseGoMod(t *testing.T) {
+	path := writeFile(t, t.TempDir(), "go.mod", `module example.com/app
+
+go 1.24
+
+require github.com/google/go-github/v68 v68.0.0
+
+require (
+	golang.org/x/text v0.21.0
+	github.com/google/go-querystring v1.1.0 // indirect
+)
+
+replace golang.org/x/text => ../text
+`)
+	targets, err := Parse(path)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	want := []string{"go:github.com/google/go-github/v68", "go:golang.org/x/text"}
+	if got := identifiers(targets); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestParseCargoToml(t *testing.T) {
+	path := writeFile(t, t.TempDir(), "Cargo.toml", `[package]
+name = "app"
+
+[dependencies]
+serde = { version = "1", features = ["derive"] }
+anyhow = "1"
+local = { path = "../local" }
+published-local = { path = "../pl", version = "0.1" }
+forked = { git = "https://github.com/me/forked" }
+renamed = { version = "0.4", package = "tokio" }
+
+[dev-dependencies]
+criterion = "0.5"
+
+[target.'cfg(windows)'.dependencies]
+winapi = "0.3"
+
+[workspace.dependencies]
+regex = "1"
+`)
+	targets, err := Parse(path)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	want := []string{
+		"crates:anyhow", "crates:criterion", "crates:published-local",
+		"crates:regex", "crates:serde", "crates:tokio", "crates:winapi",
+	}
+	if got := identifiers(targets); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestParseRequirementsTxt(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "base.txt", "flask>=3\n")
+	path := writeFile(t, dir, "requirements-dev.txt", `# comment
+-r base.txt
+requests[socks]==2.31.0  # pinned
+Django>=4.2; python_version >= "3.10"
+-e ./local
+--index-url https://example.com/simple
+https://example.com/pkg.whl
+git+ssh://git@github.com/org/private.git#egg=private
+git+https://github.com/org/tool.git@v1.0#egg=tool
+mypkg @ https://example.com/mypkg.tar.gz
+other_pkg@git+https://github.com/org/other.git@main
+Flask_Login==0.6.3
+flask.login
+`)
+	targets, err := Parse(path)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	want := []string{"pypi:django", "pypi:flask", "pypi:flask-login", "pypi:requests"}
+	if got := identifiers(targets); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestParsePyprojectToml(t *testing.T) {
+	path := writeFile(t, t.TempDir(), "pyproject.toml", `[project]
+name = "app"
+dependencies = [
+  "httpx>=0.27",
+  "pydantic[email]",
+]
+
+[project.optional-dependencies]
+cli = ["click"]
+
+[dependency-groups]
+dev = ["pytest", {include-group = "lint"}]
+
+[tool.poetry.dependencies]
+python = "^3.11"
+fastapi = "^0.110"
+local = { path = "../local" }
+
+[tool.poetry.group.docs.dependencies]
+mkdocs = "*"
+`)
+	targets, err := Parse(path)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	want := []string{"pypi:click", "pypi:fastapi", "pypi:httpx", "pypi:mkdocs", "pypi:pydantic", "pypi:pytest"}
+	if got := identifiers(targets); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestParseUnsupported(t *testing.T) {
+	path := writeFile(t, t.TempDir(), "Gemfile", "gem 'rails'\n")
+	if Supported(path) {
+		t.Error("Gemfile should not be supported")
+	}
+	if _, err := Parse(path); err == nil {
+		t.Fatal("expected error for unsupported manifest")
+	}
+}
+
+func TestParseMissingFile(t *testing.T) {
+	if _, err := Parse(filepath.Join(t.TempDir(), "package.json")); err == nil {
+		t.Fatal("expected error for missing file")
+	}
+}
+
+func TestParseInvalidJSON(t *testing.T) {
+	path := writeFile(t, t.TempDir(), "package.json", "{not json")
+	if _, err := Parse(path); err == nil {
+		t.Fatal("expected error for invalid package.json")
+	}
+}
@@ -0,0 +1,338 @@
+// Package osv enriches registry results with known vulnerabilities from the
+// OSV.dev API. All packages in a run are looked up with a single batched
+// query; advisory details are then fetched once per distinct advisory to
+// determine severity.
+package osv
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/yutakobayashidev/repiq/internal/httpclient"
+	"github.com/yutakobayashidev/repiq/internal/provider"
+)
+
+const (
+	defaultBaseURL = "https://api.osv.dev"
+
+	// maxBatch is the largest number of queries OSV accepts per
+	// querybatch request.
+	maxBatch = 1000
+	// maxPages bounds pagination for packages with very many advisories.
+	maxPages = 10
+	// detailWorkers bounds concurrent advisory detail requests.
+	detailWorkers = 8
+)
+
+// Client queries the OSV.dev API.
+type Client struct {
+	baseURL string
+	client  *http.Client
+}
+
+// New creates an OSV client. Pass empty string for default base URL.
+func New(baseURL string) *Client {
+	if baseURL == "" {
+		baseURL = defaultBaseURL
+	}
+	return &Client{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		client:  httpclient.New(),
+	}
+}
+
+// WithTransport sets the HTTP transport c sends its requests through,
+// httpclient.Default unless set, and returns c.
+func (c *Client) WithTransport(t http.RoundTripper) *Client {
+	c.client = &http.Client{Transport: t}
+	return c
+}
+
+// pkgQuery is a single package version to look up.
+type pkgQuery struct {
+	Package struct {
+		Name      string `json:"name"`
+		Ecosystem string `json:"ecosystem"`
+	} `json:"package"`
+	Version   string `json:"version"`
+	PageToken string `json:"page_token,omitempty"`
+}
+
+// Enrich fills the vulnerability fields of every npm, PyPI, crates.io, Go,
+// RubyGems, Maven, NuGet, Packagist, Hex and pub.dev result that has a
+// latest version and is not private. A failed lookup is added to the
+// result's Warnings, not its Error, since the package itself was fetched.
+func (c *Client) Enrich(ctx context.Context, results []provider.Result) {
+	var queries []pkgQuery
+	var targets []*provider.VulnMetrics
+	var indexes []int
+	for i := range results {
+		q, vm, ok := queryFor(&results[i])
+		if !ok {
+			continue
+		}
+		queries = append(queries, q)
+		targets = append(targets, vm)
+		indexes = append(indexes, i)
+	}
+	if len(queries) == 0 {
+		return
+	}
+
+	ids, err := c.queryAll(ctx, queries)
+	if err != nil {
+		for _, i := range indexes {
+			addWarning(&results[i], fmt.Sprintf("osv: %s", err.Error()))
+		}
+		return
+	}
+
+	var all []string
+	seen := make(map[string]bool)
+	for _, list := range ids {
+		for _, id := range list {
+			if !seen[id] {
+				seen[id] = true
+				all = append(all, id)
+			}
+		}
+	}
+	details, detailErrs := c.fetchDetails(ctx, all)
+
+	for n, vm := range targets {
+		summarize(vm, ids[n], details)
+		for _, id := range ids[n] {
+			if err, ok := detailErrs[id]; ok {
+				addWarning(&results[indexes[n]], fmt.Sprintf("osv: %s: %s", id, err.Error()))
+			}
+		}
+	}
+}
+
+// queryFor returns the OSV query for r and the metrics to fill in.
+// Private packages are skipped, so that their names are not sent to OSV.
+func queryFor(r *provider.Result) (pkgQuery, *provider.VulnMetrics, bool) {
+	var q pkgQuery
+	if r.Private {
+		return q, nil, false
+	}
+	var vm *provider.VulnMetrics
+	switch {
+	case r.NPM != nil:
+		q.Package.Ecosystem, q.Version, vm = "npm", r.NPM.LatestVersion, &r.NPM.VulnMetrics
+	case r.PyPI != nil:
+		q.Package.Ecosystem, q.Version, vm = "PyPI", r.PyPI.LatestVersion, &r.PyPI.VulnMetrics
+	case r.Crates != nil:
+		q.Package.Ecosystem, q.Version, vm = "crates.io", r.Crates.LatestVersion, &r.Crates.VulnMetrics
+	case r.Gem != nil:
+		q.Package.Ecosystem, q.Version, vm = "RubyGems", r.Gem.LatestVersion, &r.Gem.VulnMetrics
+	case r.Maven != nil:
+		q.Package.Ecosystem, q.Version, vm = "Maven", r.Maven.LatestVersion, &r.Maven.VulnMetrics
+	case r.NuGet != nil:
+		q.Package.Ecosystem, q.Version, vm = "NuGet", r.NuGet.LatestVersion, &r.NuGet.VulnMetrics
+	case r.Packagist != nil:
+		// Composer tags are commonly "v"-prefixed; OSV records them without.
+		q.Package.Ecosystem, q.Version, vm = "Packagist", strings.TrimPrefix(r.Packagist.LatestVersion, "v"), &r.Packagist.VulnMetrics
+	case r.Hex != nil:
+		q.Package.Ecosystem, q.Version, vm = "Hex", r.Hex.LatestVersion, &r.Hex.VulnMetrics
+	case r.Pub != nil:
+		q.Package.Ecosystem, q.Version, vm = "Pub", r.Pub.LatestVersion, &r.Pub.VulnMetrics
+	case r.Go != nil:
+		// OSV records Go versions without the "v" prefix.
+		q.Package.Ecosystem, q.Version, vm = "Go", strings.TrimPrefix(r.Go.LatestVersion, "v"), &r.Go.VulnMetrics
+	default:
+		return q, nil, false
+	}
+	_, name, ok := strings.Cut(r.Target, ":")
+	if !ok || name == "" || q.Version == "" {
+		return q, nil, false
+	}
+	q.Package.Name = name
+	return q, vm, true
+}
+
+// queryAll runs queries through querybatch, following per-query page
+// tokens, and returns the advisory IDs affecting each query.
+func (c *Client) queryAll(ctx context.Context, queries []pkgQuery) ([][]string, error) {
+	ids := make([][]string, len(queries))
+	pending := make([]int, len(queries))
+	for i := range queries {
+		pending[i] = i
+	}
+
+	for page := 0; len(pending) > 0 && page < maxPages; page++ {
+		var next []int
+		for start := 0; start < len(pending); start += maxBatch {
+			chunk := pending[start:min(start+maxBatch, len(pending))]
+			batch := make([]pkgQuery, len(chunk))
+			for k, i := range chunk {
+				batch[k] = queries[i]
+			}
+			resp, err := c.queryBatch(ctx, batch)
+			if err != nil {
+				return nil, err
+			}
+			if len(resp) != len(chunk) {
+				return nil, fmt.Errorf("querybatch: got %d results for %d queries", len(resp), len(chunk))
+			}
+			for k, i := range chunk {
+				for _, v := range resp[k].Vulns {
+					ids[i] = append(ids[i], v.ID)
+				}
+				if resp[k].NextPageToken != "" {
+					queries[i].PageToken = resp[k].NextPageToken
+					next = append(next, i)
+				}
+			}
+		}
+		pending = next
+	}
+	return ids, nil
+}
+
+type batchResult struct {
+	Vulns []struct {
+		ID string `json:"id"`
+	} `json:"vulns"`
+	NextPageToken string `json:"next_page_token"`
+}
+
+func (c *Client) queryBatch(ctx context.Context, queries []pkgQuery) ([]batchResult, error) {
+	body, err := json.Marshal(map[string]any{"queries": queries})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/v1/querybatch", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%d %s", resp.StatusCode, http.StatusText(resp.StatusCode))
+	}
+
+	var out struct {
+		Results []batchResult `json:"results"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, fmt.Errorf("decoding response: %w", err)
+	}
+	return out.Results, nil
+}
+
+// vuln is the subset of an OSV record used to rank severity and merge
+// aliases.
+type vuln struct {
+	ID       string   `json:"id"`
+	Aliases  []string `json:"aliases"`
+	Severity []struct {
+		Type  string `json:"type"`
+		Score string `json:"score"`
+	} `json:"severity"`
+	DatabaseSpecific struct {
+		Severity string `json:"severity"`
+	} `json:"database_specific"`
+}
+
+// fetchDetails fetches every advisory in ids with bounded concurrency.
+func (c *Client) fetchDetails(ctx context.Context, ids []string) (map[string]*vuln, map[string]error) {
+	details := make(map[string]*vuln)
+	errs := make(map[string]error)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, detailWorkers)
+
+	wg.Add(len(ids))
+	for _, id := range ids {
+		go func(id string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			v, err := c.fetchVuln(ctx, id)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs[id] = err
+				return
+			}
+			details[id] = v
+		}(id)
+	}
+	wg.Wait()
+	return details, errs
+}
+
+func (c *Client) fetchVuln(ctx context.Context, id string) (*vuln, error) {
+	u := fmt.Sprintf("%s/v1/vulns/%s", c.baseURL, url.PathEscape(id))
+	req, err := http.NewRequestWithContext(ctx, "GET", u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%d %s", resp.StatusCode, http.StatusText(resp.StatusCode))
+	}
+
+	var v vuln
+	if err := json.NewDecoder(resp.Body).Decode(&v); err != nil {
+		return nil, fmt.Errorf("decoding response: %w", err)
+	}
+	return &v, nil
+}
+
+// summarize fills vm from the advisory IDs affecting a package. Advisories
+// that are aliases of one already counted (e.g. a PYSEC and a GHSA record
+// for the same issue) are counted once.
+func summarize(vm *provider.VulnMetrics, ids []string, details map[string]*vuln) {
+	sorted := append([]string(nil), ids...)
+	sort.Strings(sorted)
+
+	vm.AdvisoryIDs = []string{}
+	vm.OpenVulns = 0
+	vm.MaxSeverity = ""
+	covered := make(map[string]bool)
+	for _, id := range sorted {
+		v := details[id]
+		names := []string{id}
+		if v != nil {
+			names = append(names, v.Aliases...)
+		}
+		dup := false
+		for _, name := range names {
+			dup = dup || covered[name]
+			covered[name] = true
+		}
+		if dup {
+			continue
+		}
+		vm.AdvisoryIDs = append(vm.AdvisoryIDs, id)
+		vm.OpenVulns++
+		if sev := severityOf(v); rank(sev) > rank(vm.MaxSeverity) {
+			vm.MaxSeverity = sev
+		}
+	}
+}
+
+func addWarning(r *provider.Result, msg string) {
+	r.Warnings = append(r.Warnings, msg)
+}
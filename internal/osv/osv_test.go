@@ -0,0 +1,342 @@
+package osv
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/yutakobayashidev/repiq/internal/provider"
+)
+
+func mustEncode(w http.ResponseWriter, v any) {
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		panic(err)
+	}
+}
+
+// setupMockServer serves querybatch from a fixed table keyed by
+// "ecosystem/name@version" and advisory details from another.
+func setupMockServer(t *testing.T, batches *int32) *httptest.Server {
+	t.Helper()
+
+	affected := map[string][]string{
+		"npm/lodash@4.17.20":         {"GHSA-35jh-r3h4-6jhm", "GHSA-p6mc-m468-83gw"},
+		"PyPI/jinja2@2.10":           {"GHSA-462w-v97r-4m45", "PYSEC-2019-217"},
+		"Go/golang.org/x/text@0.3.5": {"GO-2021-0113"},
+		"crates.io/smallvec@0.6.9":   {"RUSTSEC-2019-0009"},
+		"npm/paged@1.0.0":            {"GHSA-page-0001"},
+		"npm/paged@1.0.0#page2":      {"GHSA-page-0002"},
+	}
+	vulns := map[string]map[string]any{
+		"GHSA-35jh-r3h4-6jhm": {
+			"id":                "GHSA-35jh-r3h4-6jhm",
+			"aliases":           []string{"CVE-2021-23337"},
+			"database_specific": map[string]any{"severity": "HIGH"},
+		},
+		"GHSA-p6mc-m468-83gw": {
+			"id":                "GHSA-p6mc-m468-83gw",
+			"aliases":           []string{"CVE-2020-8203"},
+			"database_specific": map[string]any{"severity": "MODERATE"},
+		},
+		"GHSA-462w-v97r-4m45": {
+			"id":                "GHSA-462w-v97r-4m45",
+			"aliases":           []string{"CVE-2019-10906"},
+			"database_specific": map[string]any{"severity": "HIGH"},
+		},
+		"PYSEC-2019-217": {
+			"id":      "PYSEC-2019-217",
+			"aliases": []string{"CVE-2019-10906", "GHSA-462w-v97r-4m45"},
+		},
+		"GO-2021-0113": {
+			"id": "GO-2021-0113",
+			"severity": []map[string]any{
+				{"type": "CVSS_V3", "score": "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:N/A:H"},
+			},
+		},
+		"RUSTSEC-2019-0009": {
+			"id": "RUSTSEC-2019-0009",
+			"severity": []map[string]any{
+				{"type": "CVSS_V3", "score": "CVSS:3.0/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"},
+			},
+		},
+		"GHSA-page-0001": {"id": "GHSA-page-0001"},
+		"GHSA-page-0002": {"id": "GHSA-page-0002", "database_specific": map[string]any{"severity": "LOW"}},
+	}
+
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("POST /v1/querybatch", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(batches, 1)
+		var req struct {
+			Queries []pkgQuery `json:"queries"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		results := make([]map[string]any, len(req.Queries))
+		for i, q := range req.Queries {
+			key := q.Package.Ecosystem + "/" + q.Package.Name + "@" + q.Version
+			if q.PageToken != "" {
+				key += "#" + q.PageToken
+			}
+			var list []map[string]any
+			for _, id := range affected[key] {
+				list = append(list, map[string]any{"id": id, "modified": "2024-01-01T00:00:00Z"})
+			}
+			results[i] = map[string]any{}
+			if list != nil {
+				results[i]["vulns"] = list
+			}
+			if key == "npm/paged@1.0.0" {
+				results[i]["next_page_token"] = "page2"
+			}
+		}
+		mustEncode(w, map[string]any{"results": results})
+	})
+
+	mux.HandleFunc("GET /v1/vulns/{id}", func(w http.ResponseWriter, r *http.Request) {
+		v, ok := vulns[r.PathValue("id")]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		mustEncode(w, v)
+	})
+
+	return httptest.NewServer(mux)
+}
+
+func TestEnrich(t *testing.T) {
+	var batches int32
+	srv := setupMockServer(t, &batches)
+	defer srv.Close()
+
+	results := []provider.Result{
+		{Target: "npm:lodash", NPM: &provider.NPMMetrics{LatestVersion: "4.17.20"}},
+		{Target: "pypi:jinja2", PyPI: &provider.PyPIMetrics{LatestVersion: "2.10"}},
+		{Target: "go:golang.org/x/text", Go: &provider.GoMetrics{LatestVersion: "v0.3.5"}},
+		{Target: "crates:smallvec", Crates: &provider.CratesMetrics{LatestVersion: "0.6.9"}},
+		{Target: "npm:react", NPM: &provider.NPMMetrics{LatestVersion: "19.1.0"}},
+		{Target: "github:facebook/react", GitHub: &provider.GitHubMetrics{Stars: 1}},
+		{Target: "npm:missing", Error: "npm registry: 404 Not Found"},
+	}
+	New(srv.URL).Enrich(context.Background(), results)
+
+	if batches != 1 {
+		t.Errorf("got %d querybatch requests, want 1", batches)
+	}
+
+	tests := []struct {
+		name     string
+		got      provider.VulnMetrics
+		count    int
+		severity string
+		ids      []string
+	}{
+		{"npm", results[0].NPM.VulnMetrics, 2, "HIGH", []string{"GHSA-35jh-r3h4-6jhm", "GHSA-p6mc-m468-83gw"}},
+		// PYSEC-2019-217 is an alias of the GHSA advisory.
+		{"pypi", results[1].PyPI.VulnMetrics, 1, "HIGH", []string{"GHSA-462w-v97r-4m45"}},
+		{"go", results[2].Go.VulnMetrics, 1, "HIGH", []string{"GO-2021-0113"}},
+		{"crates", results[3].Crates.VulnMetrics, 1, "CRITICAL", []string{"RUSTSEC-2019-0009"}},
+		{"clean", results[4].NPM.VulnMetrics, 0, "", []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got.OpenVulns != tt.count {
+				t.Errorf("open_vulns: got %d, want %d", tt.got.OpenVulns, tt.count)
+			}
+			if tt.got.MaxSeverity != tt.severity {
+				t.Errorf("max_severity: got %q, want %q", tt.got.MaxSeverity, tt.severity)
+			}
+			if !reflect.DeepEqual(tt.got.AdvisoryIDs, tt.ids) {
+				t.Errorf("advisory_ids: got %v, want %v", tt.got.AdvisoryIDs, tt.ids)
+			}
+		})
+	}
+
+	for _, r := range results[:5] {
+		if r.Error != "" {
+			t.Errorf("%s: unexpected error %q", r.Target, r.Error)
+		}
+	}
+	if results[6].Error != "npm registry: 404 Not Found" {
+		t.Errorf("error result should be untouched, got %q", results[6].Error)
+	}
+}
+
+func TestEnrichPagination(t *testing.T) {
+	var batches int32
+	srv := setupMockServer(t, &batches)
+	defer srv.Close()
+
+	results := []provider.Result{
+		{Target: "npm:paged", NPM: &provider.NPMMetrics{LatestVersion: "1.0.0"}},
+	}
+	New(srv.URL).Enrich(context.Background(), results)
+
+	vm := results[0].NPM.VulnMetrics
+	if !reflect.DeepEqual(vm.AdvisoryIDs, []string{"GHSA-page-0001", "GHSA-page-0002"}) {
+		t.Errorf("advisory_ids: got %v", vm.AdvisoryIDs)
+	}
+	// GHSA-page-0001 has no severity data; LOW still outranks UNKNOWN.
+	if vm.MaxSeverity != "LOW" {
+		t.Errorf("max_severity: got %q, want LOW", vm.MaxSeverity)
+	}
+	if batches != 2 {
+		t.Errorf("got %d querybatch requests, want 2", batches)
+	}
+}
+
+func TestEnrichNoQueries(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		t.Error("no request expected")
+	}))
+	defer srv.Close()
+
+	results := []provider.Result{
+		{Target: "github:facebook/react", GitHub: &provider.GitHubMetrics{}},
+		{Target: "npm:nolatest", NPM: &provider.NPMMetrics{}},
+	}
+	New(srv.URL).Enrich(context.Background(), results)
+	if results[1].NPM.AdvisoryIDs != nil {
+		t.Error("results without a version should not be enriched")
+	}
+}
+
+func TestEnrichSkipsPrivate(t *testing.T) {
+	var queried []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Queries []pkgQuery `json:"queries"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		results := make([]map[string]any, len(req.Queries))
+		for i, q := range req.Queries {
+			queried = append(queried, q.Package.Name)
+			results[i] = map[string]any{}
+		}
+		mustEncode(w, map[string]any{"results": results})
+	}))
+	defer srv.Close()
+
+	results := []provider.Result{
+		{Target: "go:corp.example.com/team/lib", Go: &provider.GoMetrics{LatestVersion: "v1.2.3"}, Private: true},
+		{Target: "npm:@corp/internal", NPM: &provider.NPMMetrics{LatestVersion: "1.0.0"}, Private: true},
+		{Target: "npm:react", NPM: &provider.NPMMetrics{LatestVersion: "19.1.0"}},
+	}
+	New(srv.URL).Enrich(context.Background(), results)
+
+	if !reflect.DeepEqual(queried, []string{"react"}) {
+		t.Errorf("private packages must not be sent to OSV: got queries for %v", queried)
+	}
+	if results[0].Go.AdvisoryIDs != nil || results[1].NPM.AdvisoryIDs != nil {
+		t.Error("private results should not be enriched")
+	}
+}
+
+func TestEnrichBatchFailure(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	results := []provider.Result{
+		{Target: "npm:lodash", NPM: &provider.NPMMetrics{LatestVersion: "4.17.20"}},
+		{Target: "pypi:flask", PyPI: &provider.PyPIMetrics{LatestVersion: "3.0.0"}, Error: "downloads: 429 Too Many Requests"},
+	}
+	New(srv.URL).Enrich(context.Background(), results)
+
+	if results[0].NPM == nil {
+		t.Fatal("metrics should be kept on OSV failure")
+	}
+	if results[0].Error != "" || len(results[0].Warnings) != 1 || !strings.Contains(results[0].Warnings[0], "osv: 500") {
+		t.Errorf("expected an osv warning and no error, got %q, %v", results[0].Error, results[0].Warnings)
+	}
+	if results[1].Error != "downloads: 429 Too Many Requests" {
+		t.Errorf("the fetch error should be kept as is, got %q", results[1].Error)
+	}
+}
+
+func TestEnrichDetailFailure(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1/querybatch", func(w http.ResponseWriter, _ *http.Request) {
+		mustEncode(w, map[string]any{"results": []any{
+			map[string]any{"vulns": []any{map[string]any{"id": "GHSA-gone"}}},
+		}})
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	results := []provider.Result{
+		{Target: "npm:lodash", NPM: &provider.NPMMetrics{LatestVersion: "4.17.20"}},
+	}
+	New(srv.URL).Enrich(context.Background(), results)
+
+	vm := results[0].NPM.VulnMetrics
+	if vm.OpenVulns != 1 || vm.MaxSeverity != "UNKNOWN" {
+		t.Errorf("got %+v, want 1 UNKNOWN vulnerability", vm)
+	}
+	if results[0].Error != "" || len(results[0].Warnings) != 1 || !strings.Contains(results[0].Warnings[0], "osv: GHSA-gone: 404") {
+		t.Errorf("expected a detail warning and no error, got %q, %v", results[0].Error, results[0].Warnings)
+	}
+}
+
+func TestQueryForGem(t *testing.T) {
+	r := provider.Result{Target: "gem:rails", Gem: &provider.GemMetrics{LatestVersion: "8.0.2"}}
+	q, vm, ok := queryFor(&r)
+	if !ok || vm != &r.Gem.VulnMetrics {
+		t.Fatalf("expected a query filling the gem's metrics, got ok=%v", ok)
+	}
+	if q.Package.Ecosystem != "RubyGems" || q.Package.Name != "rails" || q.Version != "8.0.2" {
+		t.Errorf("got %+v", q)
+	}
+}
+
+func TestQueryForMaven(t *testing.T) {
+	r := provider.Result{Target: "maven:com.google.guava:guava", Maven: &provider.MavenMetrics{LatestVersion: "33.4.8-jre"}}
+	q, _, ok := queryFor(&r)
+	if !ok || q.Package.Ecosystem != "Maven" || q.Package.Name != "com.google.guava:guava" || q.Version != "33.4.8-jre" {
+		t.Errorf("got %+v, ok=%v", q, ok)
+	}
+}
+
+func TestQueryForNuGet(t *testing.T) {
+	r := provider.Result{Target: "nuget:Newtonsoft.Json", NuGet: &provider.NuGetMetrics{LatestVersion: "13.0.3"}}
+	q, _, ok := queryFor(&r)
+	if !ok || q.Package.Ecosystem != "NuGet" || q.Package.Name != "Newtonsoft.Json" || q.Version != "13.0.3" {
+		t.Errorf("got %+v, ok=%v", q, ok)
+	}
+}
+
+func TestQueryForPackagist(t *testing.T) {
+	r := provider.Result{Target: "packagist:laravel/framework", Packagist: &provider.PackagistMetrics{LatestVersion: "v12.0.0"}}
+	q, _, ok := queryFor(&r)
+	if !ok || q.Package.Ecosystem != "Packagist" || q.Package.Name != "laravel/framework" || q.Version != "12.0.0" {
+		t.Errorf("got %+v, ok=%v", q, ok)
+	}
+}
+
+func TestQueryForHex(t *testing.T) {
+	r := provider.Result{Target: "hex:phoenix", Hex: &provider.HexMetrics{LatestVersion: "1.7.14"}}
+	q, _, ok := queryFor(&r)
+	if !ok || q.Package.Ecosystem != "Hex" || q.Package.Name != "phoenix" || q.Version != "1.7.14" {
+		t.Errorf("got %+v, ok=%v", q, ok)
+	}
+}
+
+func TestQueryForPub(t *testing.T) {
+	r := provider.Result{Target: "pub:http", Pub: &provider.PubMetrics{LatestVersion: "1.4.0"}}
+	q, _, ok := queryFor(&r)
+	if !ok || q.Package.Ecosystem != "Pub" || q.Package.Name != "http" || q.Version != "1.4.0" {
+		t.Errorf("got %+v, ok=%v", q, ok)
+	}
+}
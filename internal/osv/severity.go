@@ -0,0 +1,131 @@
+package osv
+
+import (
+	"math"
+	"strings"
+)
+
+// Severity ratings, lowest first.
+var ratings = []string{"UNKNOWN", "LOW", "MEDIUM", "HIGH", "CRITICAL"}
+
+// rank orders severities; "" (no vulnerabilities) ranks lowest.
+func rank(severity string) int {
+	for i, r := range ratings {
+		if r == severity {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// severityOf rates an advisory. The database-specific rating (GitHub
+// advisories use LOW/MODERATE/HIGH/CRITICAL) is preferred; otherwise the
+// highest CVSS v3 base score is rated. Advisories without either are
+// UNKNOWN.
+func severityOf(v *vuln) string {
+	if v == nil {
+		return "UNKNOWN"
+	}
+	switch s := strings.ToUpper(v.DatabaseSpecific.Severity); s {
+	case "MODERATE":
+		return "MEDIUM"
+	case "LOW", "MEDIUM", "HIGH", "CRITICAL":
+		return s
+	}
+
+	best := -1.0
+	for _, s := range v.Severity {
+		if s.Type != "CVSS_V3" {
+			continue
+		}
+		if score, ok := cvss3BaseScore(s.Score); ok && score > best {
+			best = score
+		}
+	}
+	switch {
+	case best >= 9:
+		return "CRITICAL"
+	case best >= 7:
+		return "HIGH"
+	case best >= 4:
+		return "MEDIUM"
+	case best > 0:
+		return "LOW"
+	}
+	return "UNKNOWN"
+}
+
+// cvss3BaseScore computes the base score of a CVSS v3.0/v3.1 vector such as
+// "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H".
+func cvss3BaseScore(vector string) (float64, bool) {
+	parts := strings.Split(vector, "/")
+	if len(parts) < 2 || !strings.HasPrefix(parts[0], "CVSS:3") {
+		return 0, false
+	}
+	m := make(map[string]string)
+	for _, p := range parts[1:] {
+		k, v, ok := strings.Cut(p, ":")
+		if !ok {
+			return 0, false
+		}
+		m[k] = v
+	}
+
+	weights := map[string]map[string]float64{
+		"AV": {"N": 0.85, "A": 0.62, "L": 0.55, "P": 0.2},
+		"AC": {"L": 0.77, "H": 0.44},
+		"UI": {"N": 0.85, "R": 0.62},
+		"C":  {"H": 0.56, "L": 0.22, "N": 0},
+		"I":  {"H": 0.56, "L": 0.22, "N": 0},
+		"A":  {"H": 0.56, "L": 0.22, "N": 0},
+	}
+	w := make(map[string]float64)
+	for metric, values := range weights {
+		v, ok := values[m[metric]]
+		if !ok {
+			return 0, false
+		}
+		w[metric] = v
+	}
+
+	changed := false
+	switch m["S"] {
+	case "U":
+	case "C":
+		changed = true
+	default:
+		return 0, false
+	}
+	pr := map[string]float64{"N": 0.85, "L": 0.62, "H": 0.27}
+	if changed {
+		pr = map[string]float64{"N": 0.85, "L": 0.68, "H": 0.5}
+	}
+	prWeight, ok := pr[m["PR"]]
+	if !ok {
+		return 0, false
+	}
+
+	iss := 1 - (1-w["C"])*(1-w["I"])*(1-w["A"])
+	impact := 6.42 * iss
+	if changed {
+		impact = 7.52*(iss-0.029) - 3.25*math.Pow(iss-0.02, 15)
+	}
+	exploitability := 8.22 * w["AV"] * w["AC"] * prWeight * w["UI"]
+	if impact <= 0 {
+		return 0, true
+	}
+	if changed {
+		return roundUp(math.Min(1.08*(impact+exploitability), 10)), true
+	}
+	return roundUp(math.Min(impact+exploitability, 10)), true
+}
+
+// roundUp implements the CVSS v3.1 Roundup function: the smallest number,
+// to one decimal place, that is equal to or higher than x.
+func roundUp(x float64) float64 {
+	i := int64(math.Round(x * 100000))
+	if i%10000 == 0 {
+		return float64(i) / 100000
+	}
+	return float64(i/10000+1) / 10
+}
@@ -0,0 +1,72 @@
+package osv
+
+import "testing"
+
+func TestCVSS3BaseScore(t *testing.T) {
+	tests := []struct {
+		vector string
+		want   float64
+	}{
+		{"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H", 9.8},
+		{"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:C/C:H/I:H/A:H", 10.0},
+		{"CVSS:3.1/AV:N/AC:L/PR:N/UI:R/S:C/C:L/I:L/A:N", 6.1},
+		{"CVSS:3.0/AV:L/AC:L/PR:L/UI:N/S:U/C:H/I:N/A:N", 5.5},
+		{"CVSS:3.1/AV:N/AC:H/PR:H/UI:R/S:U/C:L/I:N/A:N", 2.0},
+		{"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:N/A:N", 0},
+	}
+	for _, tt := range tests {
+		got, ok := cvss3BaseScore(tt.vector)
+		if !ok {
+			t.Errorf("%s: not parsed", tt.vector)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.vector, got, tt.want)
+		}
+	}
+}
+
+func TestCVSS3BaseScoreInvalid(t *testing.T) {
+	for _, vector := range []string{
+		"",
+		"CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N",
+		"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:X/C:H/I:H/A:H",
+		"CVSS:3.1/AV:N/AC:L",
+		"AV:N/AC:L/Au:N/C:P/I:P/A:P",
+	} {
+		if _, ok := cvss3BaseScore(vector); ok {
+			t.Errorf("%q: expected parse failure", vector)
+		}
+	}
+}
+
+func TestSeverityOf(t *testing.T) {
+	withCVSS := func(score string) *vuln {
+		v := &vuln{}
+		v.Severity = append(v.Severity, struct {
+			Type  string `json:"type"`
+			Score string `json:"score"`
+		}{"CVSS_V3", score})
+		return v
+	}
+	moderate := &vuln{}
+	moderate.DatabaseSpecific.Severity = "MODERATE"
+
+	tests := []struct {
+		name string
+		v    *vuln
+		want string
+	}{
+		{"nil", nil, "UNKNOWN"},
+		{"empty", &vuln{}, "UNKNOWN"},
+		{"moderate", moderate, "MEDIUM"},
+		{"critical cvss", withCVSS("CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"), "CRITICAL"},
+		{"medium cvss", withCVSS("CVSS:3.1/AV:N/AC:L/PR:N/UI:R/S:C/C:L/I:L/A:N"), "MEDIUM"},
+		{"low cvss", withCVSS("CVSS:3.1/AV:N/AC:H/PR:H/UI:R/S:U/C:L/I:N/A:N"), "LOW"},
+	}
+	for _, tt := range tests {
+		if got := severityOf(tt.v); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,358 @@
+// Package policy evaluates declarative thresholds against fetched metrics so
+// CI can fail on unmaintained, unpopular or unacceptably licensed
+// dependencies.
+//
+// A policy file is YAML:
+//
+//	rules:
+//	  - field: last_commit_days
+//	    max: 365
+//	  - field: license
+//	    allow: [MIT, Apache-2.0, BSD-3-Clause]
+//	  - scheme: npm
+//	    field: weekly_downloads
+//	    min: 1000
+//
+// A rule without a scheme applies to every scheme whose metrics have a
+// field of that name and a matching type.
+package policy
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/yutakobayashidev/repiq/internal/provider"
+	"github.com/yutakobayashidev/repiq/internal/yaml"
+)
+
+// Policy is a set of rules.
+type Policy struct {
+	Rules []Rule
+}
+
+// Rule constrains a single metrics field. Min and Max apply to numeric
+// fields, Allow and Deny to string fields (compared case-insensitively).
+type Rule struct {
+	Scheme string
+	Field  string
+	Min    *float64
+	Max    *float64
+	Allow  []string
+	Deny   []string
+}
+
+// Violation is a rule a target failed.
+type Violation struct {
+	Target string `json:"target"`
+	Field  string `json:"field"`
+	Rule   string `json:"rule"`
+	Actual any    `json:"actual"`
+}
+
+// TargetError is a target that could not be evaluated because its fetch
+// failed.
+type TargetError struct {
+	Target string `json:"target"`
+	Error  string `json:"error"`
+}
+
+// Report is the outcome of checking results against a policy.
+type Report struct {
+	Targets    int           `json:"targets"`
+	Violations []Violation   `json:"violations"`
+	Errors     []TargetError `json:"errors,omitempty"`
+}
+
+// Load reads and validates the policy file at path.
+func Load(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	p, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return p, nil
+}
+
+// Parse parses and validates a YAML policy document.
+func Parse(data []byte) (*Policy, error) {
+	v, err := yaml.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	doc, ok := v.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("policy must be a mapping with a rules list")
+	}
+	for key := range doc {
+		if key != "rules" {
+			return nil, fmt.Errorf("unknown key %q", key)
+		}
+	}
+	rawRules, ok := doc["rules"].([]any)
+	if !ok || len(rawRules) == 0 {
+		return nil, fmt.Errorf("policy has no rules")
+	}
+
+	known := provider.MetricFields()
+	p := &Policy{}
+	for i, raw := range rawRules {
+		m, ok := raw.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("rule %d: must be a mapping", i+1)
+		}
+		r, err := parseRule(m)
+		if err != nil {
+			return nil, fmt.Errorf("rule %d: %w", i+1, err)
+		}
+		if err := r.validate(known); err != nil {
+			return nil, fmt.Errorf("rule %d: %w", i+1, err)
+		}
+		p.Rules = append(p.Rules, r)
+	}
+	return p, nil
+}
+
+func parseRule(m map[string]any) (Rule, error) {
+	var r Rule
+	for key, v := range m {
+		var err error
+		switch key {
+		case "scheme":
+			r.Scheme, err = stringValue(v)
+		case "field":
+			r.Field, err = stringValue(v)
+		case "min":
+			r.Min, err = numberValue(v)
+		case "max":
+			r.Max, err = numberValue(v)
+		case "allow":
+			r.Allow, err = stringList(v)
+		case "deny":
+			r.Deny, err = stringList(v)
+		default:
+			err = fmt.Errorf("unknown key")
+		}
+		if err != nil {
+			return Rule{}, fmt.Errorf("%s: %w", key, err)
+		}
+	}
+	return r, nil
+}
+
+// validate checks r against the known metrics fields so that typos fail
+// loudly instead of silently passing every target.
+func (r Rule) validate(known map[string]map[string]reflect.Kind) error {
+	if r.Field == "" {
+		return fmt.Errorf("field is required")
+	}
+	numeric := r.Min != nil || r.Max != nil
+	textual := r.Allow != nil || r.Deny != nil
+	if !numeric && !textual {
+		return fmt.Errorf("%s: one of min, max, allow or deny is required", r.Field)
+	}
+	if numeric && textual {
+		return fmt.Errorf("%s: min/max cannot be combined with allow/deny", r.Field)
+	}
+	if r.Scheme != "" {
+		if _, ok := known[r.Scheme]; !ok {
+			return fmt.Errorf("unknown scheme %q", r.Scheme)
+		}
+	}
+
+	// An unscoped rule applies to every scheme whose field of that name has
+	// a matching type, so "license" can mean an SPDX string for npm and a
+	// check score for scorecard.
+	found, matched := false, false
+	for scheme, fields := range known {
+		if r.Scheme != "" && scheme != r.Scheme {
+			continue
+		}
+		kind, ok := fields[r.Field]
+		if !ok {
+			continue
+		}
+		found = true
+		if (numeric && isNumericKind(kind)) || (textual && kind == reflect.String) {
+			matched = true
+		}
+	}
+	switch {
+	case !found && r.Scheme != "":
+		return fmt.Errorf("unknown field %q for scheme %q", r.Field, r.Scheme)
+	case !found:
+		return fmt.Errorf("unknown field %q", r.Field)
+	case !matched && numeric:
+		return fmt.Errorf("%s: min/max require a numeric field", r.Field)
+	case !matched:
+		return fmt.Errorf("%s: allow/deny require a string field", r.Field)
+	}
+	return nil
+}
+
+// Check evaluates every result against the policy. Results whose fetch
+// failed are reported as errors rather than evaluated, since their metrics
+// may be incomplete.
+func (p *Policy) Check(results []provider.Result) Report {
+	report := Report{Targets: len(results), Violations: []Violation{}}
+	for _, r := range results {
+		if r.Error != "" {
+			report.Errors = append(report.Errors, TargetError{Target: r.Target, Error: r.Error})
+			continue
+		}
+		report.Violations = append(report.Violations, p.Evaluate(r)...)
+	}
+	return report
+}
+
+// Evaluate returns the rules r violates, ordered as in the policy.
+func (p *Policy) Evaluate(r provider.Result) []Violation {
+	scheme, fields := r.Metrics()
+	if fields == nil {
+		return nil
+	}
+	var violations []Violation
+	for _, rule := range p.Rules {
+		if rule.Scheme != "" && rule.Scheme != scheme {
+			continue
+		}
+		v, ok := fields[rule.Field]
+		if !ok {
+			continue
+		}
+		if desc, failed := rule.failed(v); failed {
+			violations = append(violations, Violation{
+				Target: r.Target,
+				Field:  rule.Field,
+				Rule:   desc,
+				Actual: v,
+			})
+		}
+	}
+	return violations
+}
+
+// failed reports whether v breaks the rule, along with a description of
+// the constraint it broke.
+func (r Rule) failed(v any) (string, bool) {
+	if n, ok := toFloat(v); ok {
+		if r.Min != nil && n < *r.Min {
+			return "min " + formatNumber(*r.Min), true
+		}
+		if r.Max != nil && n > *r.Max {
+			return "max " + formatNumber(*r.Max), true
+		}
+		return "", false
+	}
+	s, ok := v.(string)
+	if !ok {
+		return "", false
+	}
+	if r.Allow != nil && !containsFold(r.Allow, s) {
+		return "allow " + strings.Join(r.Allow, ", "), true
+	}
+	if containsFold(r.Deny, s) {
+		return "deny " + strings.Join(r.Deny, ", "), true
+	}
+	return "", false
+}
+
+// Passed reports whether every target was evaluated and none violated the
+// policy.
+func (r Report) Passed() bool {
+	return len(r.Violations) == 0 && len(r.Errors) == 0
+}
+
+// ViolatingTargets returns the distinct targets with at least one
+// violation, sorted.
+func (r Report) ViolatingTargets() []string {
+	seen := make(map[string]bool)
+	var targets []string
+	for _, v := range r.Violations {
+		if !seen[v.Target] {
+			seen[v.Target] = true
+			targets = append(targets, v.Target)
+		}
+	}
+	sort.Strings(targets)
+	return targets
+}
+
+func isNumericKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+func toFloat(v any) (float64, bool) {
+	rv := reflect.ValueOf(v)
+	switch {
+	case rv.CanInt():
+		return float64(rv.Int()), true
+	case rv.CanUint():
+		return float64(rv.Uint()), true
+	case rv.CanFloat():
+		return rv.Float(), true
+	}
+	return 0, false
+}
+
+func formatNumber(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func stringValue(v any) (string, error) {
+	s, ok := v.(string)
+	if !ok || s == "" {
+		return "", fmt.Errorf("must be a non-empty string")
+	}
+	return s, nil
+}
+
+func numberValue(v any) (*float64, error) {
+	switch n := v.(type) {
+	case int64:
+		f := float64(n)
+		return &f, nil
+	case float64:
+		return &n, nil
+	}
+	return nil, fmt.Errorf("must be a number")
+}
+
+// stringList accepts a list of scalars or a single scalar. Scalars are
+// converted to strings so unquoted values such as 0BSD survive.
+func stringList(v any) ([]string, error) {
+	items, ok := v.([]any)
+	if !ok {
+		items = []any{v}
+	}
+	out := make([]string, 0, len(items))
+	for _, item := range items {
+		switch item.(type) {
+		case nil, []any, map[string]any:
+			return nil, fmt.Errorf("must be a list of strings")
+		}
+		out = append(out, fmt.Sprint(item))
+	}
+	return out, nil
+}
@@ -0,0 +1,189 @@
+package policy
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/yutakobayashidev/repiq/internal/provider"
+)
+
+const samplePolicy = `
+rules:
+  - field: last_commit_days
+    max: 365
+  - field: license
+    allow: [MIT, Apache-2.0, 0BSD]
+  - scheme: npm
+    field: weekly_downloads
+    min: 1000
+`
+
+func TestParse(t *testing.T) {
+	p, err := Parse([]byte(samplePolicy))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if len(p.Rules) != 3 {
+		t.Fatalf("got %d rules, want 3", len(p.Rules))
+	}
+	if p.Rules[0].Max == nil || *p.Rules[0].Max != 365 {
+		t.Errorf("rule 1 max = %v", p.Rules[0].Max)
+	}
+	if !reflect.DeepEqual(p.Rules[1].Allow, []string{"MIT", "Apache-2.0", "0BSD"}) {
+		t.Errorf("rule 2 allow = %v", p.Rules[1].Allow)
+	}
+	if p.Rules[2].Scheme != "npm" || p.Rules[2].Min == nil || *p.Rules[2].Min != 1000 {
+		t.Errorf("rule 3 = %+v", p.Rules[2])
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  string
+		want string
+	}{
+		{"empty", "", "must be a mapping"},
+		{"no rules", "rules: []\n", "no rules"},
+		{"unknown top-level key", "rule:\n  - field: stars\n", `unknown key "rule"`},
+		{"missing field", "rules:\n  - max: 1\n", "field is required"},
+		{"no constraint", "rules:\n  - field: stars\n", "one of min, max, allow or deny"},
+		{"unknown field", "rules:\n  - field: star\n    min: 1\n", `unknown field "star"`},
+		{"field not in scheme", "rules:\n  - scheme: npm\n    field: stars\n    min: 1\n", `unknown field "stars" for scheme "npm"`},
+		{"unknown scheme", "rules:\n  - scheme: nope\n    field: stars\n    min: 1\n", `unknown scheme "nope"`},
+		{"numeric on string", "rules:\n  - field: latest_version\n    max: 1\n", "require a numeric field"},
+		{"allow on number", "rules:\n  - field: stars\n    allow: [1]\n", "require a string field"},
+		{"mixed", "rules:\n  - field: stars\n    min: 1\n    allow: [x]\n", "cannot be combined"},
+		{"bad number", "rules:\n  - field: stars\n    min: lots\n", "min: must be a number"},
+		{"unknown rule key", "rules:\n  - field: stars\n    min: 1\n    above: 2\n", "above: unknown key"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.doc))
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("got %q, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "repiq-policy.yaml")
+	if err := os.WriteFile(path, []byte("rules:\n  - field: stars\n    min: x\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	_, err := Load(path)
+	if err == nil || !strings.Contains(err.Error(), path) {
+		t.Errorf("expected error mentioning %s, got %v", path, err)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	p, err := Parse([]byte(samplePolicy))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	results := []provider.Result{
+		{
+			Target: "github:facebook/react",
+			GitHub: &provider.GitHubMetrics{Stars: 200000, LastCommitDays: 1, License: "MIT"},
+		},
+		{
+			Target: "github:old/abandoned",
+			GitHub: &provider.GitHubMetrics{LastCommitDays: 900, License: "GPL-3.0"},
+		},
+		{
+			Target: "npm:tiny-lib",
+			NPM:    &provider.NPMMetrics{WeeklyDownloads: 12, License: "mit"},
+		},
+		{
+			Target: "pypi:obscure",
+			PyPI:   &provider.PyPIMetrics{WeeklyDownloads: 12, License: "0BSD"},
+		},
+		{
+			Target: "npm:missing",
+			Error:  "npm registry: 404 Not Found",
+		},
+	}
+
+	report := p.Check(results)
+	want := []Violation{
+		{Target: "github:old/abandoned", Field: "last_commit_days", Rule: "max 365", Actual: 900},
+		{Target: "github:old/abandoned", Field: "license", Rule: "allow MIT, Apache-2.0, 0BSD", Actual: "GPL-3.0"},
+		{Target: "npm:tiny-lib", Field: "weekly_downloads", Rule: "min 1000", Actual: 12},
+	}
+	if !reflect.DeepEqual(report.Violations, want) {
+		t.Errorf("violations:\ngot  %+v\nwant %+v", report.Violations, want)
+	}
+	if len(report.Errors) != 1 || report.Errors[0].Target != "npm:missing" {
+		t.Errorf("errors = %+v", report.Errors)
+	}
+	if report.Targets != 5 {
+		t.Errorf("targets = %d, want 5", report.Targets)
+	}
+	if report.Passed() {
+		t.Error("report should not pass")
+	}
+	if got := report.ViolatingTargets(); !reflect.DeepEqual(got, []string{"github:old/abandoned", "npm:tiny-lib"}) {
+		t.Errorf("violating targets = %v", got)
+	}
+}
+
+func TestCheckDeny(t *testing.T) {
+	p, err := Parse([]byte("rules:\n  - field: license\n    deny: AGPL-3.0\n"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	report := p.Check([]provider.Result{
+		{Target: "crates:serde", Crates: &provider.CratesMetrics{License: "MIT OR Apache-2.0"}},
+		{Target: "go:example.com/agpl", Go: &provider.GoMetrics{License: "agpl-3.0"}},
+	})
+	if len(report.Violations) != 1 || report.Violations[0].Target != "go:example.com/agpl" {
+		t.Errorf("violations = %+v", report.Violations)
+	}
+	if report.Violations[0].Rule != "deny AGPL-3.0" {
+		t.Errorf("rule = %q", report.Violations[0].Rule)
+	}
+}
+
+func TestCheckPassed(t *testing.T) {
+	p, err := Parse([]byte(samplePolicy))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	report := p.Check([]provider.Result{
+		{Target: "npm:react", NPM: &provider.NPMMetrics{WeeklyDownloads: 25000000, License: "MIT"}},
+	})
+	if !report.Passed() {
+		t.Errorf("expected pass, got %+v", report)
+	}
+	if report.Violations == nil {
+		t.Error("violations should be an empty slice so JSON renders []")
+	}
+}
+
+func TestCheckFieldTypePerScheme(t *testing.T) {
+	// "license" is an SPDX string for npm but a check score for scorecard;
+	// an unscoped rule only applies where the type matches.
+	p, err := Parse([]byte("rules:\n  - field: license\n    allow: [MIT]\n  - scheme: scorecard\n    field: license\n    min: 5\n"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	report := p.Check([]provider.Result{
+		{Target: "npm:left-pad", NPM: &provider.NPMMetrics{License: "WTFPL"}},
+		{Target: "scorecard:github.com/o/r", Scorecard: &provider.ScorecardMetrics{License: 0}},
+	})
+	want := []Violation{
+		{Target: "npm:left-pad", Field: "license", Rule: "allow MIT", Actual: "WTFPL"},
+		{Target: "scorecard:github.com/o/r", Field: "license", Rule: "min 5", Actual: 0},
+	}
+	if !reflect.DeepEqual(report.Violations, want) {
+		t.Errorf("violations:\ngot  %+v\nwant %+v", report.Violations, want)
+	}
+}
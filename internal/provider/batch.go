@@ -0,0 +1,55 @@
+package provider
+
+import (
+	"context"
+	"sync"
+)
+
+// BatchProvider is implemented by providers that can fetch many
+// identifiers in fewer requests than one Fetch per identifier.
+type BatchProvider interface {
+	Provider
+	// FetchBatch returns one result per identifier, in order. Failures
+	// are reported in Result.Error.
+	FetchBatch(ctx context.Context, identifiers []string) []Result
+}
+
+// FetchMany fetches identifiers with p, in a single FetchBatch call when p
+// is a BatchProvider and with one Fetch per identifier in parallel
+// otherwise. Results are in the order of identifiers.
+func FetchMany(ctx context.Context, p Provider, identifiers []string) []Result {
+	results := make([]Result, len(identifiers))
+	FetchEach(ctx, p, identifiers, func(i int, r Result) { results[i] = r })
+	return results
+}
+
+// FetchEach is FetchMany, calling emit with the index and result of each
+// identifier as soon as it is fetched instead of once all are. Results of a
+// FetchBatch call are emitted together when it returns. emit may be called
+// from several goroutines at once, and is not called after FetchEach
+// returns.
+func FetchEach(ctx context.Context, p Provider, identifiers []string, emit func(i int, r Result)) {
+	if bp, ok := p.(BatchProvider); ok {
+		for i, r := range bp.FetchBatch(ctx, identifiers) {
+			emit(i, r)
+		}
+		return
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(len(identifiers))
+	for i, id := range identifiers {
+		go func(i int, id string) {
+			defer wg.Done()
+			result, err := p.Fetch(ctx, id)
+			if err != nil {
+				result = Result{
+					Target: p.Scheme() + ":" + id,
+					Error:  err.Error(),
+				}
+			}
+			emit(i, result)
+		}(i, id)
+	}
+	wg.Wait()
+}
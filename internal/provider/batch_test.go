@@ -0,0 +1,69 @@
+package provider_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/yutakobayashidev/repiq/internal/provider"
+)
+
+type echoProvider struct{}
+
+func (echoProvider) Scheme() string { return "echo" }
+
+func (echoProvider) Fetch(_ context.Context, id string) (provider.Result, error) {
+	if id == "fail" {
+		return provider.Result{}, errors.New("boom")
+	}
+	return provider.Result{Target: "echo:" + id}, nil
+}
+
+type batchEcho struct {
+	echoProvider
+	calls int
+}
+
+func (b *batchEcho) FetchBatch(_ context.Context, ids []string) []provider.Result {
+	b.calls++
+	out := make([]provider.Result, len(ids))
+	for i, id := range ids {
+		out[i] = provider.Result{Target: "echo:" + id, Error: "batched"}
+	}
+	return out
+}
+
+func TestFetchMany(t *testing.T) {
+	results := provider.FetchMany(context.Background(), echoProvider{}, []string{"a", "fail", "b"})
+	if len(results) != 3 || results[0].Target != "echo:a" || results[2].Target != "echo:b" {
+		t.Fatalf("results out of order: %+v", results)
+	}
+	if results[1].Target != "echo:fail" || results[1].Error != "boom" {
+		t.Errorf("fetch error should become a result error, got %+v", results[1])
+	}
+}
+
+func TestFetchManyBatch(t *testing.T) {
+	p := &batchEcho{}
+	results := provider.FetchMany(context.Background(), p, []string{"a", "b"})
+	if p.calls != 1 {
+		t.Errorf("got %d FetchBatch calls, want 1", p.calls)
+	}
+	if len(results) != 2 || results[1].Error != "batched" {
+		t.Errorf("got %+v", results)
+	}
+}
+
+func TestFetchEach(t *testing.T) {
+	ids := []string{"a", "b", "c"}
+	got := make(chan int, len(ids))
+	provider.FetchEach(context.Background(), echoProvider{}, ids, func(i int, r provider.Result) {
+		if r.Target != "echo:"+ids[i] {
+			t.Errorf("result %d: got target %q", i, r.Target)
+		}
+		got <- i
+	})
+	if len(got) != len(ids) {
+		t.Errorf("got %d results, want %d", len(got), len(ids))
+	}
+}
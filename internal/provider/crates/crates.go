@@ -11,6 +11,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/yutakobayashidev/repiq/internal/httpclient"
 	"github.com/yutakobayashidev/repiq/internal/provider"
 )
 
@@ -42,13 +43,17 @@ func New(baseURL string) *Provider {
 	}
 	return &Provider{
 		baseURL: strings.TrimRight(baseURL, "/"),
-		client: &http.Client{
-			Timeout:   30 * time.Second,
-			Transport: &userAgentTransport{base: http.DefaultTransport},
-		},
+		client:  &http.Client{Transport: &userAgentTransport{base: httpclient.Default}},
 	}
 }
 
+// WithTransport sets the HTTP transport p sends its requests through,
+// httpclient.Default unless set, and returns p.
+func (p *Provider) WithTransport(t http.RoundTripper) *Provider {
+	p.client = &http.Client{Transport: &userAgentTransport{base: t}}
+	return p
+}
+
 func (p *Provider) Scheme() string { return "crates" }
 
 func (p *Provider) Fetch(ctx context.Context, identifier string) (provider.Result, error) {
@@ -78,6 +83,7 @@ func (p *Provider) Fetch(ctx context.Context, identifier string) (provider.Resul
 		Downloads:       meta.downloads,
 		RecentDownloads: meta.recentDownloads,
 		LatestVersion:   version,
+		SourceRepo:      provider.NormalizeRepoURL(meta.repository),
 	}
 
 	for _, v := range meta.versions {
@@ -146,6 +152,7 @@ type crateMetadata struct {
 	recentDownloads  int
 	maxStableVersion string
 	newestVersion    string
+	repository       string
 	versions         []versionEntry
 }
 
@@ -178,6 +185,7 @@ func (p *Provider) fetchMetadata(ctx context.Context, crate string) (*crateMetad
 			RecentDownloads  int     `json:"recent_downloads"`
 			MaxStableVersion *string `json:"max_stable_version"`
 			NewestVersion    string  `json:"newest_version"`
+			Repository       string  `json:"repository"`
 		} `json:"crate"`
 		Versions []struct {
 			Num       string `json:"num"`
@@ -193,6 +201,7 @@ func (p *Provider) fetchMetadata(ctx context.Context, crate string) (*crateMetad
 		downloads:       raw.Crate.Downloads,
 		recentDownloads: raw.Crate.RecentDownloads,
 		newestVersion:   raw.Crate.NewestVersion,
+		repository:      raw.Crate.Repository,
 	}
 	if raw.Crate.MaxStableVersion != nil {
 		meta.maxStableVersion = *raw.Crate.MaxStableVersion
@@ -32,6 +32,7 @@ func setupMockServer(t *testing.T) *httptest.Server {
 				"recent_downloads":   116815564,
 				"max_stable_version": "1.0.228",
 				"newest_version":     "1.0.228",
+				"repository":         "https://github.com/serde-rs/serde",
 			},
 			"versions": []map[string]any{
 				{
@@ -117,6 +118,9 @@ func TestFetchSuccess(t *testing.T) {
 	if c.ReverseDependencies != 72719 {
 		t.Errorf("reverse_dependencies: got %d, want 72719", c.ReverseDependencies)
 	}
+	if c.SourceRepo != "https://github.com/serde-rs/serde" {
+		t.Errorf("source_repo: got %q, want %q", c.SourceRepo, "https://github.com/serde-rs/serde")
+	}
 }
 
 func TestFetchNotFound(t *testing.T) {
@@ -0,0 +1,285 @@
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yutakobayashidev/repiq/internal/httpclient"
+	"github.com/yutakobayashidev/repiq/internal/provider"
+)
+
+var (
+	// validComponentRe is the distribution spec's pattern for a component
+	// of a repository name.
+	validComponentRe = regexp.MustCompile(`^[a-z0-9]+((\.|_|__|-+)[a-z0-9]+)*$`)
+)
+
+const defaultHubURL = "https://hub.docker.com"
+
+// hubHosts are the registry hosts that name Docker Hub.
+var hubHosts = map[string]bool{
+	"docker.io":            true,
+	"index.docker.io":      true,
+	"registry-1.docker.io": true,
+}
+
+// Provider fetches image metrics from the Docker Hub API, or from the OCI
+// distribution API of other registries such as ghcr.io and quay.io.
+type Provider struct {
+	hubURL string
+	client *http.Client
+	// registryURL returns the base URL of a registry host; overridden in
+	// tests.
+	registryURL func(host string) string
+}
+
+// New creates a Docker provider. Pass empty string for the default Docker
+// Hub API URL.
+func New(hubURL string) *Provider {
+	if hubURL == "" {
+		hubURL = defaultHubURL
+	}
+	return &Provider{
+		hubURL:      strings.TrimRight(hubURL, "/"),
+		client:      httpclient.New(),
+		registryURL: func(host string) string { return "https://" + host },
+	}
+}
+
+// WithTransport sets the HTTP transport p sends its requests through,
+// httpclient.Default unless set, and returns p.
+func (p *Provider) WithTransport(t http.RoundTripper) *Provider {
+	p.client = &http.Client{Transport: t}
+	return p
+}
+
+func (p *Provider) Scheme() string { return "docker" }
+
+func (p *Provider) Fetch(ctx context.Context, identifier string) (provider.Result, error) {
+	target := "docker:" + identifier
+
+	host, repo, err := parseIdentifier(identifier)
+	if err != nil {
+		return provider.Result{Target: target, Error: err.Error()}, nil
+	}
+
+	// The repository itself is fetched first, so that a missing image is
+	// a single error; the rest is fetched concurrently.
+	metrics := &provider.DockerMetrics{}
+	var jobs []job
+	if host == "" {
+		err = p.fetchHubRepository(ctx, repo, metrics)
+		jobs = p.hubJobs(repo, metrics)
+	} else {
+		r := &registry{baseURL: p.registryURL(host), repo: repo, client: p.client}
+		err = r.fetchTags(ctx, metrics)
+		jobs = r.jobs(metrics)
+	}
+	if err != nil {
+		return provider.Result{Target: target, Error: err.Error()}, nil
+	}
+	errs := run(ctx, jobs)
+
+	sort.Strings(metrics.Architectures)
+	result := provider.Result{
+		Target: target,
+		Docker: metrics,
+	}
+	if len(errs) > 0 {
+		result.Error = strings.Join(errs, "; ")
+	}
+	return result, nil
+}
+
+// parseIdentifier splits an image reference into its registry host and
+// repository. The host is empty for Docker Hub, whose single-component
+// names are official images under library/.
+func parseIdentifier(identifier string) (host, repo string, err error) {
+	parts := strings.Split(identifier, "/")
+	if len(parts) > 1 && (strings.ContainsAny(parts[0], ".:") || parts[0] == "localhost") {
+		host, parts = parts[0], parts[1:]
+		if !provider.ValidHost(host) {
+			return "", "", fmt.Errorf("invalid registry host %q", host)
+		}
+		if hubHosts[host] {
+			host = ""
+		}
+	}
+	for _, part := range parts {
+		if !validComponentRe.MatchString(part) {
+			if strings.ContainsAny(part, ":@") {
+				return "", "", fmt.Errorf("invalid image %q: tags and digests are not supported", identifier)
+			}
+			return "", "", fmt.Errorf("invalid image %q: expected [<host>/]<namespace>/<name>", identifier)
+		}
+	}
+	if host == "" {
+		switch len(parts) {
+		case 1:
+			parts = []string{"library", parts[0]}
+		case 2:
+		default:
+			return "", "", fmt.Errorf("invalid image %q: Docker Hub images are <namespace>/<name>", identifier)
+		}
+	}
+	return host, strings.Join(parts, "/"), nil
+}
+
+type job struct {
+	name string
+	fn   func(context.Context) error
+}
+
+// run runs jobs concurrently and returns their errors.
+func run(ctx context.Context, jobs []job) []string {
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var errs []string
+
+	wg.Add(len(jobs))
+	for _, j := range jobs {
+		go func(j job) {
+			defer wg.Done()
+			if err := j.fn(ctx); err != nil {
+				mu.Lock()
+				errs = append(errs, fmt.Sprintf("%s: %s", j.name, err.Error()))
+				mu.Unlock()
+			}
+		}(j)
+	}
+	wg.Wait()
+	return errs
+}
+
+// fetchHubRepository fills in the counts Docker Hub keeps for repo.
+func (p *Provider) fetchHubRepository(ctx context.Context, repo string, m *provider.DockerMetrics) error {
+	var r struct {
+		PullCount   int       `json:"pull_count"`
+		StarCount   int       `json:"star_count"`
+		LastUpdated time.Time `json:"last_updated"`
+	}
+	if err := p.hubGet(ctx, "/v2/repositories/"+repo+"/", &r); err != nil {
+		return err
+	}
+	m.PullCount = r.PullCount
+	m.StarCount = r.StarCount
+	m.Official = strings.HasPrefix(repo, "library/")
+	if !r.LastUpdated.IsZero() {
+		m.LastPushedDays = daysSince(r.LastUpdated)
+	}
+	return nil
+}
+
+// hubJobs returns the remaining Docker Hub requests for repo. Each job
+// fills in distinct fields of m.
+func (p *Provider) hubJobs(repo string, m *provider.DockerMetrics) []job {
+	_, name, _ := strings.Cut(repo, "/")
+	return []job{
+		{"tags", func(ctx context.Context) error {
+			var r struct {
+				Count int `json:"count"`
+			}
+			if err := p.hubGet(ctx, "/v2/repositories/"+repo+"/tags?page_size=1", &r); err != nil {
+				return err
+			}
+			m.TagCount = r.Count
+			return nil
+		}},
+		{"latest", func(ctx context.Context) error {
+			var r struct {
+				Images []platform `json:"images"`
+			}
+			if err := p.hubGet(ctx, "/v2/repositories/"+repo+"/tags/latest", &r); err != nil {
+				return err
+			}
+			m.Architectures = platforms(r.Images)
+			return nil
+		}},
+		{"verified", func(ctx context.Context) error {
+			var r struct {
+				Results []struct {
+					ID    string `json:"id"`
+					Badge string `json:"badge"`
+				} `json:"results"`
+			}
+			q := url.Values{"query": {repo}, "size": {"25"}}
+			if err := p.hubGet(ctx, "/api/search/v4?"+q.Encode(), &r); err != nil {
+				return err
+			}
+			for _, res := range r.Results {
+				if res.ID == repo || (m.Official && res.ID == name) {
+					m.Verified = res.Badge == "verified_publisher"
+				}
+			}
+			return nil
+		}},
+	}
+}
+
+func (p *Provider) hubGet(ctx context.Context, path string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", p.hubURL+path, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Docker Hub API: %d %s", resp.StatusCode, http.StatusText(resp.StatusCode))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("decoding response: %w", err)
+	}
+	return nil
+}
+
+// platform is an image's platform, as reported by Docker Hub and in OCI
+// image indexes and configs.
+type platform struct {
+	OS           string `json:"os"`
+	Architecture string `json:"architecture"`
+	Variant      string `json:"variant"`
+}
+
+// platforms returns the distinct os/architecture[/variant] of images,
+// skipping entries such as attestation manifests whose platform is
+// unknown.
+func platforms(images []platform) []string {
+	seen := make(map[string]bool)
+	out := []string{}
+	for _, img := range images {
+		if img.Architecture == "" || img.Architecture == "unknown" {
+			continue
+		}
+		s := img.OS + "/" + img.Architecture
+		if img.Variant != "" {
+			s += "/" + img.Variant
+		}
+		if !seen[s] {
+			seen[s] = true
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func daysSince(t time.Time) int {
+	days := int(math.Floor(time.Since(t).Hours() / 24))
+	if days < 0 {
+		days = 0
+	}
+	return days
+}
@@ -0,0 +1,344 @@
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func mustEncode(w http.ResponseWriter, v any) {
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		panic(err)
+	}
+}
+
+func setupHubServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	pushed3d := time.Now().Add(-3 * 24 * time.Hour).UTC().Format(time.RFC3339)
+
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /v2/repositories/library/nginx/{$}", func(w http.ResponseWriter, _ *http.Request) {
+		mustEncode(w, map[string]any{
+			"namespace":    "library",
+			"name":         "nginx",
+			"pull_count":   1000000000,
+			"star_count":   20000,
+			"last_updated": pushed3d,
+		})
+	})
+	mux.HandleFunc("GET /v2/repositories/library/nginx/tags", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page_size") != "1" {
+			t.Errorf("page_size: got %q", r.URL.Query().Get("page_size"))
+		}
+		mustEncode(w, map[string]any{"count": 1000, "results": []any{map[string]any{"name": "latest"}}})
+	})
+	mux.HandleFunc("GET /v2/repositories/library/nginx/tags/latest", func(w http.ResponseWriter, _ *http.Request) {
+		mustEncode(w, map[string]any{
+			"name": "latest",
+			"images": []map[string]any{
+				{"architecture": "arm64", "os": "linux", "variant": "v8"},
+				{"architecture": "amd64", "os": "linux"},
+				{"architecture": "arm", "os": "linux", "variant": "v7"},
+				{"architecture": "unknown", "os": "unknown"},
+				{"architecture": "amd64", "os": "linux"},
+			},
+		})
+	})
+	mux.HandleFunc("GET /api/search/v4", func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("query") {
+		case "library/nginx":
+			mustEncode(w, map[string]any{"results": []map[string]any{
+				{"id": "nginx", "badge": "official"},
+				{"id": "nginx/nginx-ingress", "badge": "verified_publisher"},
+			}})
+		case "bitnami/redis":
+			mustEncode(w, map[string]any{"results": []map[string]any{
+				{"id": "bitnami/redis", "badge": "verified_publisher"},
+			}})
+		default:
+			mustEncode(w, map[string]any{"results": []any{}})
+		}
+	})
+
+	// A verified image without a latest tag.
+	mux.HandleFunc("GET /v2/repositories/bitnami/redis/{$}", func(w http.ResponseWriter, _ *http.Request) {
+		mustEncode(w, map[string]any{"pull_count": 5000, "star_count": 300, "last_updated": pushed3d})
+	})
+	mux.HandleFunc("GET /v2/repositories/bitnami/redis/tags", func(w http.ResponseWriter, _ *http.Request) {
+		mustEncode(w, map[string]any{"count": 42})
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestScheme(t *testing.T) {
+	p := New("")
+	if p.Scheme() != "docker" {
+		t.Errorf("got %q, want %q", p.Scheme(), "docker")
+	}
+}
+
+func TestFetchHubSuccess(t *testing.T) {
+	srv := setupHubServer(t)
+	p := New(srv.URL)
+
+	for _, id := range []string{"library/nginx", "nginx", "docker.io/library/nginx"} {
+		result, err := p.Fetch(context.Background(), id)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.Error != "" {
+			t.Fatalf("%s: unexpected result error: %s", id, result.Error)
+		}
+		if result.Target != "docker:"+id {
+			t.Errorf("target: got %q", result.Target)
+		}
+		d := result.Docker
+		if d == nil {
+			t.Fatal("expected Docker metrics to be set")
+		}
+		if d.PullCount != 1000000000 || d.StarCount != 20000 {
+			t.Errorf("%s: got pull_count %d, star_count %d", id, d.PullCount, d.StarCount)
+		}
+		if d.LastPushedDays != 3 {
+			t.Errorf("%s: last_pushed_days: got %d, want 3", id, d.LastPushedDays)
+		}
+		if d.TagCount != 1000 {
+			t.Errorf("%s: tag_count: got %d", id, d.TagCount)
+		}
+		if !d.Official || d.Verified {
+			t.Errorf("%s: got official %v, verified %v", id, d.Official, d.Verified)
+		}
+		if strings.Join(d.Architectures, ",") != "linux/amd64,linux/arm/v7,linux/arm64/v8" {
+			t.Errorf("%s: architectures: got %v", id, d.Architectures)
+		}
+	}
+}
+
+func TestFetchHubPartialFailure(t *testing.T) {
+	srv := setupHubServer(t)
+	p := New(srv.URL)
+
+	result, err := p.Fetch(context.Background(), "bitnami/redis")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	d := result.Docker
+	if d == nil {
+		t.Fatal("expected Docker metrics despite the failure")
+	}
+	if result.Error != "latest: Docker Hub API: 404 Not Found" {
+		t.Errorf("error: got %q", result.Error)
+	}
+	if d.Official || !d.Verified {
+		t.Errorf("got official %v, verified %v", d.Official, d.Verified)
+	}
+	if d.TagCount != 42 {
+		t.Errorf("tag_count: got %d", d.TagCount)
+	}
+}
+
+func TestFetchHubNotFound(t *testing.T) {
+	srv := setupHubServer(t)
+	p := New(srv.URL)
+
+	result, err := p.Fetch(context.Background(), "nobody/nothing")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Error != "Docker Hub API: 404 Not Found" {
+		t.Errorf("error: got %q", result.Error)
+	}
+	if result.Docker != nil {
+		t.Error("expected Docker to be nil on not found")
+	}
+}
+
+// setupRegistryServer serves a repository through the distribution API,
+// behind an anonymous token endpoint.
+func setupRegistryServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	created10d := time.Now().Add(-10 * 24 * time.Hour).UTC().Format(time.RFC3339)
+
+	mux := http.NewServeMux()
+	var srv *httptest.Server
+
+	authorized := func(w http.ResponseWriter, r *http.Request) bool {
+		if r.Header.Get("Authorization") == "Bearer pull-token" {
+			return true
+		}
+		w.Header().Set("WWW-Authenticate", `Bearer realm="`+srv.URL+`/token",service="registry.test",scope="repository:owner/app:pull"`)
+		w.WriteHeader(http.StatusUnauthorized)
+		return false
+	}
+
+	mux.HandleFunc("GET /token", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("service") != "registry.test" || r.URL.Query().Get("scope") != "repository:owner/app:pull" {
+			http.Error(w, "bad scope", http.StatusBadRequest)
+			return
+		}
+		mustEncode(w, map[string]any{"token": "pull-token"})
+	})
+	mux.HandleFunc("GET /v2/owner/app/tags/list", func(w http.ResponseWriter, r *http.Request) {
+		if !authorized(w, r) {
+			return
+		}
+		if r.URL.Query().Get("last") == "" {
+			w.Header().Set("Link", `</v2/owner/app/tags/list?last=v2&n=1000>; rel="next"`)
+			mustEncode(w, map[string]any{"name": "owner/app", "tags": []string{"v1", "v2"}})
+			return
+		}
+		mustEncode(w, map[string]any{"name": "owner/app", "tags": []string{"v3", "latest"}})
+	})
+	mux.HandleFunc("GET /v2/owner/app/manifests/latest", func(w http.ResponseWriter, r *http.Request) {
+		if !authorized(w, r) {
+			return
+		}
+		if !strings.Contains(r.Header.Get("Accept"), "application/vnd.oci.image.index.v1+json") {
+			t.Errorf("accept: got %q", r.Header.Get("Accept"))
+		}
+		w.Header().Set("Content-Type", "application/vnd.oci.image.index.v1+json")
+		mustEncode(w, map[string]any{
+			"mediaType": "application/vnd.oci.image.index.v1+json",
+			"manifests": []map[string]any{
+				{"digest": "sha256:attest", "platform": map[string]any{"architecture": "unknown", "os": "unknown"}},
+				{"digest": "sha256:amd64", "platform": map[string]any{"architecture": "amd64", "os": "linux"}},
+				{"digest": "sha256:arm64", "platform": map[string]any{"architecture": "arm64", "os": "linux"}},
+			},
+		})
+	})
+	mux.HandleFunc("GET /v2/owner/app/manifests/sha256:amd64", func(w http.ResponseWriter, r *http.Request) {
+		if !authorized(w, r) {
+			return
+		}
+		mustEncode(w, map[string]any{"config": map[string]any{"digest": "sha256:config"}})
+	})
+	mux.HandleFunc("GET /v2/owner/app/blobs/sha256:config", func(w http.ResponseWriter, r *http.Request) {
+		if !authorized(w, r) {
+			return
+		}
+		mustEncode(w, map[string]any{"architecture": "amd64", "os": "linux", "created": created10d})
+	})
+
+	srv = httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestFetchRegistrySuccess(t *testing.T) {
+	srv := setupRegistryServer(t)
+	p := New("http://unused")
+	p.registryURL = func(host string) string {
+		if host != "ghcr.io" {
+			t.Errorf("host: got %q", host)
+		}
+		return srv.URL
+	}
+
+	result, err := p.Fetch(context.Background(), "ghcr.io/owner/app")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Error != "" {
+		t.Fatalf("unexpected result error: %s", result.Error)
+	}
+	d := result.Docker
+	if d == nil {
+		t.Fatal("expected Docker metrics to be set")
+	}
+	if d.TagCount != 4 {
+		t.Errorf("tag_count should count every page: got %d", d.TagCount)
+	}
+	if strings.Join(d.Architectures, ",") != "linux/amd64,linux/arm64" {
+		t.Errorf("architectures: got %v", d.Architectures)
+	}
+	if d.LastPushedDays != 10 {
+		t.Errorf("last_pushed_days: got %d, want 10", d.LastPushedDays)
+	}
+	if d.PullCount != 0 || d.Official || d.Verified {
+		t.Errorf("registries report no pull counts or badges: got %+v", d)
+	}
+}
+
+func TestFetchRegistryForeignNextLink(t *testing.T) {
+	foreign := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("request sent to another host, with authorization %q", r.Header.Get("Authorization"))
+		mustEncode(w, map[string]any{"tags": []string{"v3"}})
+	}))
+	defer foreign.Close()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v2/owner/app/tags/list", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Link", `<`+foreign.URL+`/v2/owner/app/tags/list?last=v2>; rel="next"`)
+		mustEncode(w, map[string]any{"tags": []string{"v1", "v2"}})
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	p := New("http://unused")
+	p.registryURL = func(string) string { return srv.URL }
+
+	result, err := p.Fetch(context.Background(), "ghcr.io/owner/app")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(result.Error, "refusing to follow") {
+		t.Errorf("error: got %q", result.Error)
+	}
+}
+
+func TestFetchRegistryNotFound(t *testing.T) {
+	srv := setupRegistryServer(t)
+	p := New("http://unused")
+	p.registryURL = func(string) string { return srv.URL }
+
+	result, err := p.Fetch(context.Background(), "quay.io/owner/missing")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Error != "registry API: 404 Not Found" {
+		t.Errorf("error: got %q", result.Error)
+	}
+	if result.Docker != nil {
+		t.Error("expected Docker to be nil on not found")
+	}
+}
+
+func TestParseIdentifier(t *testing.T) {
+	tests := []struct {
+		id, host, repo string
+	}{
+		{"nginx", "", "library/nginx"},
+		{"library/nginx", "", "library/nginx"},
+		{"bitnami/redis", "", "bitnami/redis"},
+		{"index.docker.io/bitnami/redis", "", "bitnami/redis"},
+		{"ghcr.io/owner/app", "ghcr.io", "owner/app"},
+		{"quay.io/org/team/app", "quay.io", "org/team/app"},
+		{"localhost:5000/app", "localhost:5000", "app"},
+	}
+	for _, tt := range tests {
+		host, repo, err := parseIdentifier(tt.id)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", tt.id, err)
+			continue
+		}
+		if host != tt.host || repo != tt.repo {
+			t.Errorf("%q: got %q %q, want %q %q", tt.id, host, repo, tt.host, tt.repo)
+		}
+	}
+
+	for _, id := range []string{"", "Nginx", "nginx:1.27", "nginx@sha256:abc", "a/b/c", "ghcr.io/", "ghcr.io/owner/../app", "-bad.io/app"} {
+		if _, _, err := parseIdentifier(id); err == nil {
+			t.Errorf("%q: expected an error", id)
+		}
+	}
+}
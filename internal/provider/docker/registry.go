@@ -0,0 +1,245 @@
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yutakobayashidev/repiq/internal/provider"
+)
+
+// maxTagPages bounds the tag list pages read from a registry.
+const maxTagPages = 20
+
+var (
+	challengeParamRe = regexp.MustCompile(`(\w+)="([^"]*)"`)
+	nextLinkRe       = regexp.MustCompile(`<([^>]+)>;\s*rel="next"`)
+)
+
+// Media types accepted for the latest tag's manifest, image indexes first.
+var manifestTypes = []string{
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+}
+
+// registry reads a repository through the OCI distribution API, with an
+// anonymous pull token when the registry asks for one. Registries keep no
+// pull or star counts, so only tags and the latest image are reported.
+type registry struct {
+	baseURL string
+	repo    string
+	client  *http.Client
+
+	mu    sync.Mutex
+	token string
+}
+
+// fetchTags counts the repository's tags, following the tag list's pages.
+func (r *registry) fetchTags(ctx context.Context, m *provider.DockerMetrics) error {
+	next := "/v2/" + r.repo + "/tags/list?n=1000"
+	for page := 0; next != "" && page < maxTagPages; page++ {
+		var list struct {
+			Tags []string `json:"tags"`
+		}
+		h, err := r.get(ctx, next, nil, &list)
+		if err != nil {
+			return err
+		}
+		m.TagCount += len(list.Tags)
+
+		next = ""
+		if match := nextLinkRe.FindStringSubmatch(h.Get("Link")); match != nil {
+			next = match[1]
+		}
+	}
+	return nil
+}
+
+// jobs returns the remaining requests for the repository.
+func (r *registry) jobs(m *provider.DockerMetrics) []job {
+	return []job{
+		{"latest", func(ctx context.Context) error {
+			return r.fetchLatest(ctx, m)
+		}},
+	}
+}
+
+type manifest struct {
+	Manifests []struct {
+		Digest   string   `json:"digest"`
+		Platform platform `json:"platform"`
+	} `json:"manifests"`
+	Config struct {
+		Digest string `json:"digest"`
+	} `json:"config"`
+}
+
+// fetchLatest reads the platforms of the latest tag, and when its image
+// was created. For an image index, the creation time is that of its first
+// image.
+func (r *registry) fetchLatest(ctx context.Context, m *provider.DockerMetrics) error {
+	var mf manifest
+	if _, err := r.get(ctx, "/v2/"+r.repo+"/manifests/latest", manifestTypes, &mf); err != nil {
+		return err
+	}
+
+	if len(mf.Manifests) > 0 {
+		images := make([]platform, 0, len(mf.Manifests))
+		first := ""
+		for _, entry := range mf.Manifests {
+			images = append(images, entry.Platform)
+			if first == "" && entry.Platform.Architecture != "unknown" {
+				first = entry.Digest
+			}
+		}
+		m.Architectures = platforms(images)
+		if first == "" {
+			return nil
+		}
+		mf = manifest{}
+		if _, err := r.get(ctx, "/v2/"+r.repo+"/manifests/"+first, manifestTypes[2:], &mf); err != nil {
+			return err
+		}
+	}
+	if mf.Config.Digest == "" {
+		return nil
+	}
+
+	var config struct {
+		platform
+		Created time.Time `json:"created"`
+	}
+	if _, err := r.get(ctx, "/v2/"+r.repo+"/blobs/"+mf.Config.Digest, nil, &config); err != nil {
+		return err
+	}
+	if m.Architectures == nil {
+		m.Architectures = platforms([]platform{config.platform})
+	}
+	if !config.Created.IsZero() {
+		m.LastPushedDays = daysSince(config.Created)
+	}
+	return nil
+}
+
+// get decodes the response to a GET of ref, a path or URL relative to the
+// registry, and returns its headers. A 401 is answered once by fetching a
+// token from the realm in its challenge. ref must stay on the registry's
+// host, so that its token is never sent elsewhere.
+func (r *registry) get(ctx context.Context, ref string, accept []string, v any) (http.Header, error) {
+	base, err := url.Parse(r.baseURL)
+	if err != nil {
+		return nil, err
+	}
+	u, err := base.Parse(ref)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != base.Scheme || u.Host != base.Host {
+		return nil, fmt.Errorf("registry API: refusing to follow %s off %s", u.Redacted(), base.Host)
+	}
+
+	for attempt := 0; ; attempt++ {
+		r.mu.Lock()
+		token := r.token
+		r.mu.Unlock()
+
+		req, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
+		if err != nil {
+			return nil, err
+		}
+		if len(accept) > 0 {
+			req.Header.Set("Accept", strings.Join(accept, ", "))
+		}
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+
+		resp, err := r.client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode == http.StatusUnauthorized && attempt == 0 {
+			challenge := resp.Header.Get("WWW-Authenticate")
+			_ = resp.Body.Close()
+			if err := r.authorize(ctx, challenge, token); err != nil {
+				return nil, fmt.Errorf("registry token: %w", err)
+			}
+			continue
+		}
+		defer func() { _ = resp.Body.Close() }()
+
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("registry API: %d %s", resp.StatusCode, http.StatusText(resp.StatusCode))
+		}
+		if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+			return nil, fmt.Errorf("decoding response: %w", err)
+		}
+		return resp.Header, nil
+	}
+}
+
+// authorize fetches a pull token as asked by a Bearer challenge, unless
+// another request already replaced the rejected token.
+func (r *registry) authorize(ctx context.Context, challenge, rejected string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.token != rejected {
+		return nil
+	}
+
+	scheme, params, _ := strings.Cut(challenge, " ")
+	if !strings.EqualFold(scheme, "Bearer") {
+		return fmt.Errorf("unsupported challenge %q", challenge)
+	}
+	values := make(map[string]string)
+	for _, match := range challengeParamRe.FindAllStringSubmatch(params, -1) {
+		values[strings.ToLower(match[1])] = match[2]
+	}
+	realm, err := url.Parse(values["realm"])
+	if err != nil || realm.Host == "" {
+		return fmt.Errorf("invalid realm %q", values["realm"])
+	}
+	q := realm.Query()
+	if values["service"] != "" {
+		q.Set("service", values["service"])
+	}
+	q.Set("scope", "repository:"+r.repo+":pull")
+	realm.RawQuery = q.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", realm.String(), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%d %s", resp.StatusCode, http.StatusText(resp.StatusCode))
+	}
+	var body struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return fmt.Errorf("decoding response: %w", err)
+	}
+	r.token = body.Token
+	if r.token == "" {
+		r.token = body.AccessToken
+	}
+	if r.token == "" {
+		return fmt.Errorf("no token in response")
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package provider
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Metrics returns the populated metrics of r keyed by their JSON field
+// names, along with the JSON key of the metrics block ("github", "npm",
+// ...). It returns ("", nil) when r carries no metrics.
+func (r Result) Metrics() (string, map[string]any) {
+	v := reflect.ValueOf(r)
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		key, ok := metricsKey(t.Field(i))
+		if !ok || v.Field(i).IsNil() {
+			continue
+		}
+		fields := make(map[string]any)
+		collectFields(v.Field(i).Elem(), fields)
+		return key, fields
+	}
+	return "", nil
+}
+
+// MetricFields returns the kind of every metrics field, keyed by the JSON
+// key of its metrics block and then by field name.
+func MetricFields() map[string]map[string]reflect.Kind {
+	out := make(map[string]map[string]reflect.Kind)
+	t := reflect.TypeOf(Result{})
+	for i := 0; i < t.NumField(); i++ {
+		key, ok := metricsKey(t.Field(i))
+		if !ok {
+			continue
+		}
+		fields := make(map[string]any)
+		collectFields(reflect.New(t.Field(i).Type.Elem()).Elem(), fields)
+		out[key] = make(map[string]reflect.Kind, len(fields))
+		for name, v := range fields {
+			out[key][name] = reflect.TypeOf(v).Kind()
+		}
+	}
+	return out
+}
+
+// metricsKey reports whether f is a *XxxMetrics field of Result and
+// returns its JSON key.
+func metricsKey(f reflect.StructField) (string, bool) {
+	if f.Type.Kind() != reflect.Pointer || f.Type.Elem().Kind() != reflect.Struct {
+		return "", false
+	}
+	if !strings.HasSuffix(f.Type.Elem().Name(), "Metrics") {
+		return "", false
+	}
+	name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+	return name, name != ""
+}
+
+// collectFields flattens the exported fields of struct v into out, keyed by
+// JSON name. Embedded structs are flattened like encoding/json does.
+func collectFields(v reflect.Value, out map[string]any) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		if f.Anonymous && f.Type.Kind() == reflect.Struct {
+			collectFields(v.Field(i), out)
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		out[name] = v.Field(i).Interface()
+	}
+}
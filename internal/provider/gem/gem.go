@@ -0,0 +1,206 @@
+package gem
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yutakobayashidev/repiq/internal/httpclient"
+	"github.com/yutakobayashidev/repiq/internal/provider"
+)
+
+var validGemRe = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._-]*$`)
+
+const defaultBaseURL = "https://rubygems.org"
+
+// Provider fetches metrics from the rubygems.org API.
+type Provider struct {
+	baseURL string
+	client  *http.Client
+}
+
+// New creates a RubyGems provider. Pass empty string for default base URL.
+func New(baseURL string) *Provider {
+	if baseURL == "" {
+		baseURL = defaultBaseURL
+	}
+	return &Provider{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		client:  httpclient.New(),
+	}
+}
+
+// WithTransport sets the HTTP transport p sends its requests through,
+// httpclient.Default unless set, and returns p.
+func (p *Provider) WithTransport(t http.RoundTripper) *Provider {
+	p.client = &http.Client{Transport: t}
+	return p
+}
+
+func (p *Provider) Scheme() string { return "gem" }
+
+func (p *Provider) Fetch(ctx context.Context, identifier string) (provider.Result, error) {
+	target := "gem:" + identifier
+
+	if identifier == "" || !validGemRe.MatchString(identifier) {
+		return provider.Result{
+			Target: target,
+			Error:  fmt.Sprintf("invalid gem name %q", identifier),
+		}, nil
+	}
+
+	info, err := p.fetchGem(ctx, identifier)
+	if err != nil {
+		return provider.Result{
+			Target: target,
+			Error:  fmt.Sprintf("RubyGems API: %s", err.Error()),
+		}, nil
+	}
+
+	metrics := &provider.GemMetrics{
+		Downloads:         info.Downloads,
+		VersionDownloads:  info.VersionDownloads,
+		LatestVersion:     info.Version,
+		DependenciesCount: len(info.Dependencies.Runtime),
+		License:           strings.Join(info.Licenses, " OR "),
+		SourceRepo:        info.sourceRepo(),
+	}
+	if !info.VersionCreatedAt.IsZero() {
+		metrics.LastPublishDays = daysSince(info.VersionCreatedAt)
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var errs []string
+
+	wg.Add(2)
+
+	go func() {
+		defer wg.Done()
+		v, err := p.fetchVersion(ctx, identifier, info.Version)
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("version: %s", err.Error()))
+			return
+		}
+		metrics.RequiredRubyVersion = v.RubyVersion
+		if info.VersionCreatedAt.IsZero() && !v.CreatedAt.IsZero() {
+			metrics.LastPublishDays = daysSince(v.CreatedAt)
+		}
+	}()
+
+	go func() {
+		defer wg.Done()
+		count, err := p.fetchReverseDependenciesCount(ctx, identifier)
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("reverse_dependencies: %s", err.Error()))
+			return
+		}
+		metrics.ReverseDependencies = count
+	}()
+
+	wg.Wait()
+
+	result := provider.Result{
+		Target: target,
+		Gem:    metrics,
+	}
+	if len(errs) > 0 {
+		result.Error = strings.Join(errs, "; ")
+	}
+	return result, nil
+}
+
+type gemInfo struct {
+	Downloads        int       `json:"downloads"`
+	Version          string    `json:"version"`
+	VersionDownloads int       `json:"version_downloads"`
+	VersionCreatedAt time.Time `json:"version_created_at"`
+	Licenses         []string  `json:"licenses"`
+	SourceCodeURI    string    `json:"source_code_uri"`
+	HomepageURI      string    `json:"homepage_uri"`
+	Dependencies     struct {
+		Runtime []struct {
+			Name string `json:"name"`
+		} `json:"runtime"`
+	} `json:"dependencies"`
+}
+
+// sourceRepo returns source_code_uri, or homepage_uri when it is a
+// repository on a well-known code host.
+func (g *gemInfo) sourceRepo() string {
+	if repo := provider.NormalizeRepoURL(g.SourceCodeURI); repo != "" {
+		return repo
+	}
+	if repo := provider.NormalizeRepoURL(g.HomepageURI); repo != "" && provider.IsForgeRepo(repo) {
+		return repo
+	}
+	return ""
+}
+
+type versionInfo struct {
+	RubyVersion string    `json:"ruby_version"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+func (p *Provider) fetchGem(ctx context.Context, name string) (*gemInfo, error) {
+	var info gemInfo
+	if err := p.get(ctx, fmt.Sprintf("/api/v1/gems/%s.json", name), &info); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+func (p *Provider) fetchVersion(ctx context.Context, name, version string) (*versionInfo, error) {
+	var v versionInfo
+	if err := p.get(ctx, fmt.Sprintf("/api/v2/rubygems/%s/versions/%s.json", name, version), &v); err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+func (p *Provider) fetchReverseDependenciesCount(ctx context.Context, name string) (int, error) {
+	var names []string
+	if err := p.get(ctx, fmt.Sprintf("/api/v1/gems/%s/reverse_dependencies.json", name), &names); err != nil {
+		return 0, err
+	}
+	return len(names), nil
+}
+
+func (p *Provider) get(ctx context.Context, path string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", p.baseURL+path, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%d %s", resp.StatusCode, http.StatusText(resp.StatusCode))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("decoding response: %w", err)
+	}
+	return nil
+}
+
+func daysSince(t time.Time) int {
+	days := int(math.Floor(time.Since(t).Hours() / 24))
+	if days < 0 {
+		days = 0
+	}
+	return days
+}
@@ -0,0 +1,186 @@
+package gem
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func mustEncode(w http.ResponseWriter, v any) {
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		panic(err)
+	}
+}
+
+func setupMockServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	published10d := time.Now().Add(-10 * 24 * time.Hour).Format(time.RFC3339)
+
+	mux := http.NewServeMux()
+
+	// GET /api/v1/gems/rails.json — metadata
+	mux.HandleFunc("GET /api/v1/gems/rails.json", func(w http.ResponseWriter, _ *http.Request) {
+		mustEncode(w, map[string]any{
+			"name":               "rails",
+			"downloads":          600000000,
+			"version":            "8.0.2",
+			"version_downloads":  3000000,
+			"version_created_at": published10d,
+			"licenses":           []string{"MIT"},
+			"source_code_uri":    "https://github.com/rails/rails/tree/v8.0.2",
+			"homepage_uri":       "https://rubyonrails.org",
+			"dependencies": map[string]any{
+				"runtime": []map[string]any{
+					{"name": "actionpack"}, {"name": "activerecord"}, {"name": "railties"},
+				},
+				"development": []map[string]any{{"name": "rake"}},
+			},
+		})
+	})
+
+	mux.HandleFunc("GET /api/v2/rubygems/rails/versions/8.0.2.json", func(w http.ResponseWriter, _ *http.Request) {
+		mustEncode(w, map[string]any{
+			"ruby_version": ">= 3.2.0",
+			"created_at":   published10d,
+		})
+	})
+
+	mux.HandleFunc("GET /api/v1/gems/rails/reverse_dependencies.json", func(w http.ResponseWriter, _ *http.Request) {
+		mustEncode(w, []string{"devise", "rspec-rails", "kaminari"})
+	})
+
+	// A gem with no source_code_uri, whose home page is its repository,
+	// and whose reverse dependencies fail.
+	mux.HandleFunc("GET /api/v1/gems/tiny.json", func(w http.ResponseWriter, _ *http.Request) {
+		mustEncode(w, map[string]any{
+			"downloads":    100,
+			"version":      "0.1.0",
+			"licenses":     nil,
+			"homepage_uri": "https://gitlab.com/someone/tiny",
+		})
+	})
+	mux.HandleFunc("GET /api/v2/rubygems/tiny/versions/0.1.0.json", func(w http.ResponseWriter, _ *http.Request) {
+		mustEncode(w, map[string]any{
+			"ruby_version": nil,
+			"created_at":   time.Now().Add(-400 * 24 * time.Hour).Format(time.RFC3339),
+		})
+	})
+	mux.HandleFunc("GET /api/v1/gems/tiny/reverse_dependencies.json", func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, "boom", http.StatusInternalServerError)
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestScheme(t *testing.T) {
+	p := New("")
+	if p.Scheme() != "gem" {
+		t.Errorf("got %q, want %q", p.Scheme(), "gem")
+	}
+}
+
+func TestFetchSuccess(t *testing.T) {
+	srv := setupMockServer(t)
+	p := New(srv.URL)
+
+	result, err := p.Fetch(context.Background(), "rails")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Error != "" {
+		t.Fatalf("unexpected result error: %s", result.Error)
+	}
+	if result.Target != "gem:rails" {
+		t.Errorf("target: got %q, want %q", result.Target, "gem:rails")
+	}
+	g := result.Gem
+	if g == nil {
+		t.Fatal("expected Gem metrics to be set")
+	}
+	if g.Downloads != 600000000 || g.VersionDownloads != 3000000 {
+		t.Errorf("downloads: got %d and %d", g.Downloads, g.VersionDownloads)
+	}
+	if g.LatestVersion != "8.0.2" {
+		t.Errorf("latest_version: got %q", g.LatestVersion)
+	}
+	if g.LastPublishDays != 10 {
+		t.Errorf("last_publish_days: got %d, want 10", g.LastPublishDays)
+	}
+	if g.DependenciesCount != 3 {
+		t.Errorf("dependencies_count: got %d, want 3 (runtime only)", g.DependenciesCount)
+	}
+	if g.License != "MIT" {
+		t.Errorf("license: got %q", g.License)
+	}
+	if g.RequiredRubyVersion != ">= 3.2.0" {
+		t.Errorf("required_ruby_version: got %q", g.RequiredRubyVersion)
+	}
+	if g.ReverseDependencies != 3 {
+		t.Errorf("reverse_dependencies: got %d, want 3", g.ReverseDependencies)
+	}
+	if g.SourceRepo != "https://github.com/rails/rails" {
+		t.Errorf("source_repo: got %q", g.SourceRepo)
+	}
+}
+
+func TestFetchPartialFailure(t *testing.T) {
+	srv := setupMockServer(t)
+	p := New(srv.URL)
+
+	result, err := p.Fetch(context.Background(), "tiny")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	g := result.Gem
+	if g == nil {
+		t.Fatal("expected Gem metrics despite the failure")
+	}
+	if !strings.Contains(result.Error, "reverse_dependencies: 500") {
+		t.Errorf("error: got %q", result.Error)
+	}
+	if g.LastPublishDays != 400 {
+		t.Errorf("last_publish_days should fall back to the version's created_at: got %d", g.LastPublishDays)
+	}
+	if g.SourceRepo != "https://gitlab.com/someone/tiny" {
+		t.Errorf("source_repo: got %q", g.SourceRepo)
+	}
+	if g.License != "" || g.RequiredRubyVersion != "" {
+		t.Errorf("got license %q and required_ruby_version %q, want empty", g.License, g.RequiredRubyVersion)
+	}
+}
+
+func TestFetchNotFound(t *testing.T) {
+	srv := setupMockServer(t)
+	p := New(srv.URL)
+
+	result, err := p.Fetch(context.Background(), "nonexistent")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Error != "RubyGems API: 404 Not Found" {
+		t.Errorf("error: got %q", result.Error)
+	}
+	if result.Gem != nil {
+		t.Error("expected Gem to be nil on not found")
+	}
+}
+
+func TestFetchInvalidIdentifier(t *testing.T) {
+	p := New("http://unused")
+	for _, id := range []string{"", "../etc", "rails/../x", "-rails", "rails?x=1"} {
+		result, err := p.Fetch(context.Background(), id)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.Error == "" || result.Gem != nil {
+			t.Errorf("%q: expected a validation error, got %+v", id, result)
+		}
+	}
+}
@@ -0,0 +1,256 @@
+package gitea
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/yutakobayashidev/repiq/internal/forge"
+	"github.com/yutakobayashidev/repiq/internal/httpclient"
+	"github.com/yutakobayashidev/repiq/internal/provider"
+)
+
+// CodebergURL is the API base URL of Codeberg, the default instance of the
+// codeberg scheme.
+const CodebergURL = "https://codeberg.org/api/v1"
+
+// dialect is how the Gitea API authenticates and paginates. Pages of 50
+// items are the default maximum of Gitea and Forgejo instances.
+var dialect = forge.Dialect{
+	TokenHeader: "Authorization",
+	TokenPrefix: "token ",
+	LimitParam:  "limit",
+	PageSize:    50,
+	TotalHeader: "X-Total-Count",
+}
+
+// maxContributorPages bounds the commits scanned for contributors, since
+// neither Gitea nor Forgejo has a contributors endpoint.
+const maxContributorPages = 20
+
+var validNameRe = regexp.MustCompile(`^[a-zA-Z0-9._-]+$`)
+
+// HostResolver returns the API base URL of a Gitea or Forgejo host, and the
+// token to use there ("" for none).
+type HostResolver func(host string) (baseURL, token string)
+
+// HostBaseURL returns the API base URL of a Gitea or Forgejo host.
+func HostBaseURL(host string) string {
+	return "https://" + host + "/api/v1"
+}
+
+// Provider fetches repository metrics from the Gitea API, which Forgejo
+// and Codeberg also serve.
+type Provider struct {
+	scheme  string
+	api     forge.API
+	resolve HostResolver
+}
+
+// New creates a provider for scheme. Identifiers of the form owner/repo
+// are fetched from the instance at baseURL, with token; pass empty string
+// for no default instance, in which case identifiers must name their host
+// as host/owner/repo.
+func New(scheme, baseURL, token string) *Provider {
+	return &Provider{
+		scheme: scheme,
+		api: forge.API{
+			Dialect: dialect,
+			BaseURL: strings.TrimRight(baseURL, "/"),
+			Token:   token,
+			Client:  httpclient.New(),
+		},
+		resolve: func(host string) (string, string) { return HostBaseURL(host), "" },
+	}
+}
+
+// WithTransport sets the HTTP transport p sends its requests through,
+// httpclient.Default unless set, and returns p.
+func (p *Provider) WithTransport(t http.RoundTripper) *Provider {
+	p.api.Client = &http.Client{Transport: t}
+	return p
+}
+
+// WithHosts sets how p reaches the hosts of identifiers of the form
+// host/owner/repo, and returns p. By default each host's API is at
+// HostBaseURL and is used without a token.
+func (p *Provider) WithHosts(resolve HostResolver) *Provider {
+	p.resolve = resolve
+	return p
+}
+
+func (p *Provider) Scheme() string { return p.scheme }
+
+func (p *Provider) Fetch(ctx context.Context, identifier string) (provider.Result, error) {
+	target := p.scheme + ":" + identifier
+
+	host, owner, repo, err := parseIdentifier(identifier)
+	if err != nil {
+		return provider.Result{Target: target, Error: err.Error()}, nil
+	}
+	c := p.api
+	if host != "" && !isHost(c.BaseURL, host) {
+		baseURL, token := p.resolve(host)
+		c.BaseURL, c.Token = strings.TrimRight(baseURL, "/"), token
+	} else if c.BaseURL == "" {
+		return provider.Result{
+			Target: target,
+			Error:  fmt.Sprintf("invalid identifier %q: expected host/owner/repo, as no default %s instance is configured", identifier, p.scheme),
+		}, nil
+	}
+	repoPath := "/repos/" + owner + "/" + repo
+
+	var info struct {
+		StarsCount      int      `json:"stars_count"`
+		ForksCount      int      `json:"forks_count"`
+		OpenIssuesCount int      `json:"open_issues_count"`
+		OpenPRCounter   int      `json:"open_pr_counter"`
+		ReleaseCounter  int      `json:"release_counter"`
+		Licenses        []string `json:"licenses"`
+	}
+	if _, err := c.Get(ctx, repoPath, nil, &info); err != nil {
+		return provider.Result{
+			Target: target,
+			Error:  fmt.Sprintf("Gitea API: %s", err.Error()),
+		}, nil
+	}
+
+	metrics := &provider.GiteaMetrics{
+		Stars:        info.StarsCount,
+		Forks:        info.ForksCount,
+		OpenIssues:   info.OpenIssuesCount + info.OpenPRCounter,
+		ReleaseCount: info.ReleaseCounter,
+		License:      strings.Join(info.Licenses, " OR "),
+	}
+
+	errs := fetchParallel(ctx, c, repoPath, metrics)
+
+	result := provider.Result{
+		Target: target,
+		Gitea:  metrics,
+	}
+	if len(errs) > 0 {
+		result.Error = strings.Join(errs, "; ")
+	}
+	return result, nil
+}
+
+// parseIdentifier splits an owner/repo or host/owner/repo identifier.
+func parseIdentifier(identifier string) (host, owner, repo string, err error) {
+	parts := strings.Split(identifier, "/")
+	if len(parts) == 3 {
+		host, parts = parts[0], parts[1:]
+		if !provider.ValidHost(host) {
+			return "", "", "", fmt.Errorf("invalid host %q", host)
+		}
+	}
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", "", fmt.Errorf("invalid identifier %q: expected owner/repo or host/owner/repo", identifier)
+	}
+	for _, s := range parts {
+		if !validNameRe.MatchString(s) || s == "." || s == ".." {
+			return "", "", "", fmt.Errorf("invalid identifier %q: owner and repo must match [a-zA-Z0-9._-]+", identifier)
+		}
+	}
+	return strings.ToLower(host), parts[0], parts[1], nil
+}
+
+func fetchParallel(ctx context.Context, c forge.API, repoPath string, m *provider.GiteaMetrics) []string {
+	since := time.Now().AddDate(0, 0, -30)
+	return forge.Parallel(ctx,
+		func(ctx context.Context) (err error) {
+			m.Contributors, m.ContributorsCapped, err = fetchContributorCount(ctx, c, repoPath)
+			if err != nil {
+				return fmt.Errorf("contributors: %w", err)
+			}
+			return nil
+		},
+		func(ctx context.Context) (err error) {
+			m.LastCommitDays, err = fetchLastCommitDays(ctx, c, repoPath)
+			if err != nil {
+				return fmt.Errorf("commits: %w", err)
+			}
+			return nil
+		},
+		func(ctx context.Context) (err error) {
+			m.Commits30d, err = c.Count(ctx, repoPath+"/commits", commitsQuery(url.Values{
+				"since": {since.UTC().Format(time.RFC3339)},
+			}))
+			if err != nil {
+				return fmt.Errorf("commits_30d: %w", err)
+			}
+			return nil
+		},
+		func(ctx context.Context) (err error) {
+			m.IssuesClosed30d, err = forge.ClosedSince(ctx, c, repoPath+"/issues", url.Values{
+				"state": {"closed"},
+				"type":  {"issues"},
+				"since": {since.UTC().Format(time.RFC3339)},
+			}, since, 0)
+			if err != nil {
+				return fmt.Errorf("issues: %w", err)
+			}
+			return nil
+		},
+	)
+}
+
+// commitsQuery adds the parameters that keep commit listings cheap: no
+// diff stats, signature verification or changed files.
+func commitsQuery(q url.Values) url.Values {
+	q.Set("stat", "false")
+	q.Set("verification", "false")
+	q.Set("files", "false")
+	return q
+}
+
+type commit struct {
+	Commit struct {
+		Author struct {
+			Email string `json:"email"`
+		} `json:"author"`
+		Committer struct {
+			Date time.Time `json:"date"`
+		} `json:"committer"`
+	} `json:"commit"`
+}
+
+// fetchContributorCount counts the distinct commit authors among the
+// latest maxContributorPages pages of commits, and reports whether older
+// commits were left out.
+func fetchContributorCount(ctx context.Context, c forge.API, repoPath string) (int, bool, error) {
+	authors := make(map[string]bool)
+	capped, err := c.List(ctx, repoPath+"/commits", commitsQuery(url.Values{}), maxContributorPages, func(items []json.RawMessage) error {
+		for _, item := range items {
+			var cm commit
+			if err := json.Unmarshal(item, &cm); err != nil {
+				return fmt.Errorf("decoding response: %w", err)
+			}
+			authors[strings.ToLower(cm.Commit.Author.Email)] = true
+		}
+		return nil
+	})
+	return len(authors), capped, err
+}
+
+func fetchLastCommitDays(ctx context.Context, c forge.API, repoPath string) (int, error) {
+	var commits []commit
+	if _, err := c.Get(ctx, repoPath+"/commits", commitsQuery(url.Values{"limit": {"1"}}), &commits); err != nil {
+		return 0, err
+	}
+	if len(commits) == 0 {
+		return 0, nil
+	}
+	return forge.DaysSince(commits[0].Commit.Committer.Date), nil
+}
+
+// isHost reports whether host is that of the instance at baseURL.
+func isHost(baseURL, host string) bool {
+	u, err := url.Parse(baseURL)
+	return err == nil && strings.EqualFold(u.Host, host)
+}
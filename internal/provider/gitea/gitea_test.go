@@ -0,0 +1,260 @@
+package gitea
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yutakobayashidev/repiq/internal/forge"
+)
+
+func mustEncode(w http.ResponseWriter, v any) {
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		panic(err)
+	}
+}
+
+func commitJSON(email string, date time.Time) map[string]any {
+	return map[string]any{"commit": map[string]any{
+		"author":    map[string]any{"email": email},
+		"committer": map[string]any{"date": date.Format(time.RFC3339)},
+	}}
+}
+
+// setupMockServer serves owner/repo with 60 commits by four authors, 30 per
+// page as on an instance whose maximum page size is below pageSize.
+func setupMockServer(t *testing.T, token string) *httptest.Server {
+	t.Helper()
+	now := time.Now().UTC()
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /api/v1/repos/owner/repo", func(w http.ResponseWriter, _ *http.Request) {
+		mustEncode(w, map[string]any{
+			"stars_count":       300,
+			"forks_count":       40,
+			"open_issues_count": 12,
+			"open_pr_counter":   3,
+			"release_counter":   9,
+			"licenses":          []string{"MIT"},
+		})
+	})
+
+	mux.HandleFunc("GET /api/v1/repos/owner/repo/commits", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("stat") != "false" {
+			t.Errorf("commits query: got %s", r.URL.RawQuery)
+		}
+		if q.Get("since") != "" {
+			w.Header().Set("X-Total-Count", "25")
+			mustEncode(w, []any{commitJSON("a@example.com", now)})
+			return
+		}
+		if q.Get("limit") == "1" {
+			mustEncode(w, []any{commitJSON("a@example.com", now.Add(-2*24*time.Hour))})
+			return
+		}
+		commits := make([]any, 30)
+		for i := range commits {
+			commits[i] = commitJSON(fmt.Sprintf("User%d@example.com", i%3), now)
+		}
+		if q.Get("page") == "1" {
+			w.Header().Set("Link", `</api/v1/repos/owner/repo/commits?page=2>; rel="next"`)
+		} else {
+			commits[0] = commitJSON("late@example.com", now)
+		}
+		mustEncode(w, commits)
+	})
+
+	mux.HandleFunc("GET /api/v1/repos/owner/repo/issues", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("state") != "closed" || q.Get("type") != "issues" || q.Get("since") == "" {
+			t.Errorf("issues query: got %s", r.URL.RawQuery)
+		}
+		mustEncode(w, []map[string]any{
+			{"closed_at": now.Add(-5 * 24 * time.Hour).Format(time.RFC3339)},
+			{"closed_at": now.Add(-45 * 24 * time.Hour).Format(time.RFC3339)},
+		})
+	})
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		want := ""
+		if token != "" {
+			want = "token " + token
+		}
+		if r.Header.Get("Authorization") != want {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestScheme(t *testing.T) {
+	if got := New("codeberg", CodebergURL, "").Scheme(); got != "codeberg" {
+		t.Errorf("got %q, want %q", got, "codeberg")
+	}
+}
+
+func TestFetchSuccess(t *testing.T) {
+	srv := setupMockServer(t, "secret")
+	p := New("codeberg", srv.URL+"/api/v1", "secret")
+
+	result, err := p.Fetch(context.Background(), "owner/repo")
+	if err != nil {
+		t.Fatalf("unexpected Go error: %v", err)
+	}
+	if result.Error != "" {
+		t.Fatalf("unexpected error: %s", result.Error)
+	}
+	if result.Target != "codeberg:owner/repo" {
+		t.Errorf("target: got %q", result.Target)
+	}
+	g := result.Gitea
+	if g.Stars != 300 || g.Forks != 40 || g.ReleaseCount != 9 || g.License != "MIT" {
+		t.Errorf("repo: got %+v", g)
+	}
+	if g.OpenIssues != 15 {
+		t.Errorf("open_issues should include pull requests like GitHub: got %d, want 15", g.OpenIssues)
+	}
+	if g.Contributors != 4 || g.ContributorsCapped {
+		t.Errorf("contributors: got %d (capped %v), want 4", g.Contributors, g.ContributorsCapped)
+	}
+	if g.LastCommitDays != 2 || g.Commits30d != 25 || g.IssuesClosed30d != 1 {
+		t.Errorf("activity: got last_commit_days=%d commits_30d=%d issues_closed_30d=%d", g.LastCommitDays, g.Commits30d, g.IssuesClosed30d)
+	}
+}
+
+func TestFetchContributorCountCapped(t *testing.T) {
+	var pages int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pages++
+		w.Header().Set("Link", `<`+r.URL.Path+`?page=next>; rel="next"`)
+		mustEncode(w, []any{commitJSON(fmt.Sprintf("user%d@example.com", pages), time.Now())})
+	}))
+	defer srv.Close()
+
+	count, capped, err := fetchContributorCount(context.Background(), forge.API{Dialect: dialect, BaseURL: srv.URL, Client: srv.Client()}, "/repos/owner/repo")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if count != maxContributorPages || !capped || pages != maxContributorPages {
+		t.Errorf("got %d contributors (capped %v) from %d pages, want %d capped", count, capped, pages, maxContributorPages)
+	}
+}
+
+func TestFetchHost(t *testing.T) {
+	srv := setupMockServer(t, "host-token")
+	var resolved string
+	p := New("gitea", "", "").WithHosts(func(host string) (string, string) {
+		resolved = host
+		return srv.URL + "/api/v1/", "host-token"
+	})
+
+	result, err := p.Fetch(context.Background(), "Git.Example.com/owner/repo")
+	if err != nil {
+		t.Fatalf("unexpected Go error: %v", err)
+	}
+	if result.Error != "" || result.Gitea == nil || result.Gitea.Stars != 300 {
+		t.Fatalf("got %+v", result)
+	}
+	if result.Target != "gitea:Git.Example.com/owner/repo" {
+		t.Errorf("target: got %q", result.Target)
+	}
+	if resolved != "git.example.com" {
+		t.Errorf("resolved host: got %q", resolved)
+	}
+}
+
+func TestFetchOwnHost(t *testing.T) {
+	srv := setupMockServer(t, "secret")
+	p := New("codeberg", srv.URL+"/api/v1", "secret").WithHosts(func(string) (string, string) {
+		t.Error("the default instance's own host should not be resolved")
+		return "", ""
+	})
+	host := strings.TrimPrefix(srv.URL, "http://")
+
+	result, _ := p.Fetch(context.Background(), host+"/owner/repo")
+	if result.Error != "" || result.Gitea == nil {
+		t.Errorf("got %+v", result)
+	}
+}
+
+func TestFetchNoDefaultInstance(t *testing.T) {
+	result, err := New("gitea", "", "").Fetch(context.Background(), "owner/repo")
+	if err != nil {
+		t.Fatalf("unexpected Go error: %v", err)
+	}
+	if !strings.Contains(result.Error, "expected host/owner/repo") {
+		t.Errorf("got %+v", result)
+	}
+}
+
+func TestFetchNotFound(t *testing.T) {
+	srv := setupMockServer(t, "")
+	result, err := New("codeberg", srv.URL+"/api/v1", "").Fetch(context.Background(), "owner/missing")
+	if err != nil {
+		t.Fatalf("unexpected Go error: %v", err)
+	}
+	if result.Error != "Gitea API: 404 Not Found" || result.Gitea != nil {
+		t.Errorf("got %+v", result)
+	}
+}
+
+func TestFetchPartialFailure(t *testing.T) {
+	srv := setupMockServer(t, "")
+	partial := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/issues") {
+			http.Error(w, "boom", http.StatusInternalServerError)
+			return
+		}
+		srv.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer partial.Close()
+
+	result, err := New("codeberg", partial.URL+"/api/v1", "").Fetch(context.Background(), "owner/repo")
+	if err != nil {
+		t.Fatalf("unexpected Go error: %v", err)
+	}
+	if result.Gitea == nil || result.Gitea.Stars != 300 || result.Gitea.Commits30d != 25 {
+		t.Errorf("expected partial metrics, got %+v", result.Gitea)
+	}
+	if result.Error != "issues: 500 Internal Server Error" {
+		t.Errorf("error: got %q", result.Error)
+	}
+}
+
+func TestParseIdentifier(t *testing.T) {
+	tests := []struct {
+		in                string
+		host, owner, repo string
+		wantErr           bool
+	}{
+		{in: "owner/repo", owner: "owner", repo: "repo"},
+		{in: "git.example.com/owner/repo", host: "git.example.com", owner: "owner", repo: "repo"},
+		{in: "localhost:3000/owner/repo.js", host: "localhost:3000", owner: "owner", repo: "repo.js"},
+		{in: "", wantErr: true},
+		{in: "noslash", wantErr: true},
+		{in: "owner/", wantErr: true},
+		{in: "a/b/c/d", wantErr: true},
+		{in: "owner/..", wantErr: true},
+		{in: "owner/repo is:public", wantErr: true},
+		{in: "bad host/owner/repo", wantErr: true},
+	}
+	for _, tt := range tests {
+		host, owner, repo, err := parseIdentifier(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseIdentifier(%q): err = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if host != tt.host || owner != tt.owner || repo != tt.repo {
+			t.Errorf("parseIdentifier(%q): got %q %q %q", tt.in, host, owner, repo)
+		}
+	}
+}
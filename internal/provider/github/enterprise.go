@@ -0,0 +1,101 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/yutakobayashidev/repiq/internal/provider"
+)
+
+// HostResolver returns the REST API base URL of a GitHub Enterprise Server
+// host, and the token to use there ("" for none).
+type HostResolver func(host string) (baseURL, token string)
+
+// EnterpriseBaseURL returns the REST API base URL of a GitHub Enterprise
+// Server host.
+func EnterpriseBaseURL(host string) string {
+	return "https://" + host + "/api/v3/"
+}
+
+// WithHosts sets how p reaches the GitHub Enterprise Server hosts of
+// identifiers of the form host/owner/repo, and returns p. By default each
+// host's API is at EnterpriseBaseURL and is used without a token.
+func (p *Provider) WithHosts(resolve HostResolver) *Provider {
+	p.resolve = resolve
+	return p
+}
+
+// splitHost splits a host/owner/repo identifier. Identifiers on
+// github.com itself, with or without the host, are not split.
+func splitHost(identifier string) (host, rest string, ok bool) {
+	if strings.Count(identifier, "/") != 2 {
+		return "", "", false
+	}
+	host, rest, _ = strings.Cut(identifier, "/")
+	if strings.EqualFold(host, "github.com") {
+		return "", "", false
+	}
+	return host, rest, true
+}
+
+// trimGitHubHost removes an explicit github.com host from identifier.
+func trimGitHubHost(identifier string) string {
+	if host, rest, ok := strings.Cut(identifier, "/"); ok && strings.EqualFold(host, "github.com") && strings.Contains(rest, "/") {
+		return rest
+	}
+	return identifier
+}
+
+// forHost returns the provider of a GitHub Enterprise Server host.
+func (p *Provider) forHost(host string) (*Provider, error) {
+	if !provider.ValidHost(host) {
+		return nil, fmt.Errorf("invalid GitHub host %q", host)
+	}
+	host = strings.ToLower(host)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if hp, ok := p.hosts[host]; ok {
+		return hp, nil
+	}
+	baseURL, token := p.resolve(host)
+	hp := New(token, baseURL).WithTransport(p.transport)
+	p.hosts[host] = hp
+	return hp, nil
+}
+
+// fetchEnterprise fetches the host/owner/repo identifiers at indices with
+// one FetchBatch per host, storing their results in results.
+func (p *Provider) fetchEnterprise(ctx context.Context, identifiers []string, indices []int, results []provider.Result) {
+	byHost := make(map[string][]int)
+	for _, i := range indices {
+		host, _, _ := splitHost(identifiers[i])
+		byHost[host] = append(byHost[host], i)
+	}
+
+	var wg sync.WaitGroup
+	for host, indices := range byHost {
+		hp, err := p.forHost(host)
+		if err != nil {
+			for _, i := range indices {
+				results[i] = provider.Result{Target: "github:" + identifiers[i], Error: err.Error()}
+			}
+			continue
+		}
+		wg.Add(1)
+		go func(hp *Provider, indices []int) {
+			defer wg.Done()
+			rest := make([]string, len(indices))
+			for j, i := range indices {
+				_, rest[j], _ = splitHost(identifiers[i])
+			}
+			for j, r := range hp.FetchBatch(ctx, rest) {
+				r.Target = "github:" + identifiers[indices[j]]
+				results[indices[j]] = r
+			}
+		}(hp, indices)
+	}
+	wg.Wait()
+}
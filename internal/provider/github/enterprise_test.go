@@ -0,0 +1,81 @@
+package github
+
+import (
+	"context"
+	"net/url"
+	"testing"
+)
+
+func TestFetchEnterpriseHost(t *testing.T) {
+	srv := setupMockServer(t)
+	defer srv.Close()
+
+	var resolved []string
+	p := New("", "http://unused/").WithHosts(func(host string) (string, string) {
+		resolved = append(resolved, host)
+		return srv.URL + "/", ""
+	})
+
+	for range 2 {
+		result, err := p.Fetch(context.Background(), "GHE.example.com/owner/repo")
+		if err != nil {
+			t.Fatalf("unexpected Go error: %v", err)
+		}
+		if result.Error != "" {
+			t.Fatalf("unexpected error: %s", result.Error)
+		}
+		if result.Target != "github:GHE.example.com/owner/repo" {
+			t.Errorf("target: got %q", result.Target)
+		}
+		if result.GitHub.Stars != 1000 {
+			t.Errorf("stars: got %d", result.GitHub.Stars)
+		}
+	}
+	if len(resolved) != 1 || resolved[0] != "ghe.example.com" {
+		t.Errorf("hosts resolved: got %v, want [ghe.example.com] once", resolved)
+	}
+}
+
+func TestFetchBatchEnterpriseHosts(t *testing.T) {
+	var queries int32
+	ghe := setupGraphQLServer(t, &queries)
+	defer ghe.Close()
+	dotcom := setupMockServer(t)
+	defer dotcom.Close()
+
+	p := New("", dotcom.URL+"/").WithHosts(func(host string) (string, string) {
+		return ghe.URL + "/", "test-token"
+	})
+	ids := []string{"owner/repo", "ghe.example.com/owner/repo", "github.com/owner/repo", "bad_host!/owner/repo"}
+	results := p.FetchBatch(context.Background(), ids)
+
+	if queries != 1 {
+		t.Errorf("got %d GraphQL queries, want 1", queries)
+	}
+	for i, r := range results[:3] {
+		if r.Target != "github:"+ids[i] {
+			t.Errorf("target %d: got %q", i, r.Target)
+		}
+		if r.Error != "" || r.GitHub == nil || r.GitHub.Stars != 1000 {
+			t.Errorf("result %d: got %+v", i, r)
+		}
+	}
+	if r := results[3]; r.Error != `invalid GitHub host "bad_host!"` {
+		t.Errorf("invalid host: got %+v", r)
+	}
+}
+
+func TestGraphQLURL(t *testing.T) {
+	tests := map[string]string{
+		"https://api.github.com/":            "https://api.github.com/graphql",
+		"https://ghe.example.com/api/v3/":    "https://ghe.example.com/api/graphql",
+		"http://127.0.0.1:8080/":             "http://127.0.0.1:8080/graphql",
+		EnterpriseBaseURL("ghe.example.com"): "https://ghe.example.com/api/graphql",
+	}
+	for base, want := range tests {
+		u, _ := url.Parse(base)
+		if got := graphqlURL(u); got != want {
+			t.Errorf("graphqlURL(%s): got %q, want %q", base, got, want)
+		}
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"time"
 
 	gogithub "github.com/google/go-github/v68/github"
+	"github.com/yutakobayashidev/repiq/internal/httpclient"
 	"github.com/yutakobayashidev/repiq/internal/provider"
 )
 
@@ -20,27 +21,86 @@ var validNameRe = regexp.MustCompile(`^[a-zA-Z0-9._-]+$`)
 // Provider fetches metrics from the GitHub API.
 type Provider struct {
 	client *gogithub.Client
+	// graphql is nil without a token: the GraphQL API requires
+	// authentication.
+	graphql *graphqlClient
+	// token and transport are those the clients were set up with.
+	token     string
+	transport http.RoundTripper
+
+	// resolve finds the API and token of GitHub Enterprise Server hosts,
+	// whose providers are created on first use.
+	resolve HostResolver
+	mu      sync.Mutex
+	hosts   map[string]*Provider
 }
 
 // New creates a GitHub provider. If token is non-empty, authenticated requests are used.
 // baseURL overrides the API base URL (useful for testing); pass "" for default.
 func New(token, baseURL string) *Provider {
-	var httpClient *http.Client
+	p := &Provider{
+		resolve: func(host string) (string, string) { return EnterpriseBaseURL(host), "" },
+		hosts:   make(map[string]*Provider),
+	}
+	p.setClients(token, baseURL, httpclient.Default)
+	return p
+}
+
+// WithTransport sets the HTTP transport p and the providers of its GitHub
+// Enterprise Server hosts send their requests through, httpclient.Default
+// unless set, and returns p.
+func (p *Provider) WithTransport(t http.RoundTripper) *Provider {
+	p.setClients(p.token, p.client.BaseURL.String(), t)
+	return p
+}
+
+// setClients sets up the REST and GraphQL clients of p.
+func (p *Provider) setClients(token, baseURL string, transport http.RoundTripper) {
+	p.token, p.transport = token, transport
+	httpClient := &http.Client{Transport: transport}
 	if token != "" {
 		httpClient = &http.Client{
-			Transport: &tokenTransport{token: token, base: http.DefaultTransport},
+			Transport: &tokenTransport{token: token, base: transport},
 		}
 	}
-	client := gogithub.NewClient(httpClient)
+	p.client = gogithub.NewClient(httpClient)
 	if baseURL != "" {
-		client.BaseURL = mustParseURL(baseURL)
+		p.client.BaseURL = mustParseURL(baseURL)
+	}
+	p.graphql = nil
+	if token != "" {
+		p.graphql = &graphqlClient{
+			httpClient: httpClient,
+			url:        graphqlURL(p.client.BaseURL),
+		}
+	}
+}
+
+// graphqlURL returns the GraphQL endpoint that goes with a REST API base
+// URL: /graphql on github.com, but /api/graphql next to the /api/v3 REST
+// API on GitHub Enterprise Server.
+func graphqlURL(base *url.URL) string {
+	if strings.HasSuffix(base.Path, "/api/v3/") {
+		u := *base
+		u.Path = strings.TrimSuffix(base.Path, "v3/") + "graphql"
+		return u.String()
 	}
-	return &Provider{client: client}
+	return base.ResolveReference(&url.URL{Path: "graphql"}).String()
 }
 
 func (p *Provider) Scheme() string { return "github" }
 
 func (p *Provider) Fetch(ctx context.Context, identifier string) (provider.Result, error) {
+	if host, rest, ok := splitHost(identifier); ok {
+		hp, err := p.forHost(host)
+		if err != nil {
+			return provider.Result{Target: "github:" + identifier, Error: err.Error()}, nil
+		}
+		result, err := hp.Fetch(ctx, rest)
+		result.Target = "github:" + identifier
+		return result, err
+	}
+
 	owner, repo, err := parseIdentifier(identifier)
 	if err != nil {
 		return provider.Result{Target: "github:" + identifier, Error: err.Error()}, nil
@@ -79,9 +139,9 @@ func (p *Provider) Fetch(ctx context.Context, identifier string) (provider.Resul
 }
 
 func parseIdentifier(identifier string) (owner, repo string, err error) {
-	parts := strings.SplitN(identifier, "/", 2)
+	parts := strings.SplitN(trimGitHubHost(identifier), "/", 2)
 	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
-		return "", "", fmt.Errorf("invalid identifier %q: expected owner/repo", identifier)
+		return "", "", fmt.Errorf("invalid identifier %q: expected owner/repo or host/owner/repo", identifier)
 	}
 	if !validNameRe.MatchString(parts[0]) || !validNameRe.MatchString(parts[1]) {
 		return "", "", fmt.Errorf("invalid identifier %q: owner and repo must match [a-zA-Z0-9._-]+", identifier)
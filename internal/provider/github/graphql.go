@@ -0,0 +1,299 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yutakobayashidev/repiq/internal/provider"
+)
+
+// graphqlBatchSize is the number of repositories fetched per GraphQL query.
+// Each repository costs a handful of connection counts and one search, so
+// a batch stays well below the query complexity limits.
+const graphqlBatchSize = 20
+
+var _ provider.BatchProvider = (*Provider)(nil)
+
+type graphqlClient struct {
+	httpClient *http.Client
+	url        string
+}
+
+// batchRepo is a repository queued for a GraphQL batch; index is its
+// position in the FetchBatch identifiers, and id the identifier itself.
+type batchRepo struct {
+	index       int
+	id          string
+	owner, name string
+}
+
+// FetchBatch fetches many repositories with one GraphQL query per
+// graphqlBatchSize repositories, in place of about six REST and Search
+// calls each. Contributor counts are not exposed by GraphQL and still come
+// from one REST call per repository. Without a token, or for repositories
+// the GraphQL query could not fetch, FetchBatch falls back to Fetch.
+// Repositories on GitHub Enterprise Server are batched per host.
+func (p *Provider) FetchBatch(ctx context.Context, identifiers []string) []provider.Result {
+	results := make([]provider.Result, len(identifiers))
+	var queued []batchRepo
+	var fallback, enterprise []int
+	for i, id := range identifiers {
+		if _, _, ok := splitHost(id); ok {
+			enterprise = append(enterprise, i)
+			continue
+		}
+		owner, name, err := parseIdentifier(id)
+		switch {
+		case err != nil:
+			results[i] = provider.Result{Target: "github:" + id, Error: err.Error()}
+		case p.graphql == nil:
+			fallback = append(fallback, i)
+		default:
+			queued = append(queued, batchRepo{index: i, id: id, owner: owner, name: name})
+		}
+	}
+
+	// Enterprise hosts are fetched alongside, each writing only to the
+	// results of its own identifiers.
+	hostsDone := make(chan struct{})
+	go func() {
+		defer close(hostsDone)
+		p.fetchEnterprise(ctx, identifiers, enterprise, results)
+	}()
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var fetched []int
+	for start := 0; start < len(queued); start += graphqlBatchSize {
+		batch := queued[start:min(start+graphqlBatchSize, len(queued))]
+		wg.Add(1)
+		go func(batch []batchRepo) {
+			defer wg.Done()
+			batchResults, ok := p.graphql.fetchRepos(ctx, batch)
+			mu.Lock()
+			defer mu.Unlock()
+			for j, r := range batch {
+				if !ok[j] {
+					fallback = append(fallback, r.index)
+					continue
+				}
+				results[r.index] = batchResults[j]
+				if batchResults[j].GitHub != nil {
+					fetched = append(fetched, r.index)
+				}
+			}
+		}(batch)
+	}
+	wg.Wait()
+
+	wg.Add(len(fetched) + len(fallback))
+	for _, i := range fetched {
+		go func(i int) {
+			defer wg.Done()
+			owner, name, _ := parseIdentifier(identifiers[i])
+			count, err := p.fetchContributorCount(ctx, owner, name)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				results[i].Error = joinError(results[i].Error, fmt.Sprintf("contributors: %s", err))
+				return
+			}
+			results[i].GitHub.Contributors = count
+		}(i)
+	}
+	for _, i := range fallback {
+		go func(i int) {
+			defer wg.Done()
+			result, _ := p.Fetch(ctx, identifiers[i])
+			mu.Lock()
+			results[i] = result
+			mu.Unlock()
+		}(i)
+	}
+	wg.Wait()
+	<-hostsDone
+	return results
+}
+
+type graphqlResponse struct {
+	Data   map[string]json.RawMessage `json:"data"`
+	Errors []struct {
+		Type    string `json:"type"`
+		Path    []any  `json:"path"`
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+type repositoryNode struct {
+	StargazerCount int `json:"stargazerCount"`
+	ForkCount      int `json:"forkCount"`
+	LicenseInfo    *struct {
+		SpdxID string `json:"spdxId"`
+	} `json:"licenseInfo"`
+	Issues           countNode `json:"issues"`
+	PullRequests     countNode `json:"pullRequests"`
+	Releases         countNode `json:"releases"`
+	DefaultBranchRef *struct {
+		Target struct {
+			CommittedDate time.Time `json:"committedDate"`
+			History       countNode `json:"history"`
+		} `json:"target"`
+	} `json:"defaultBranchRef"`
+}
+
+type countNode struct {
+	TotalCount int `json:"totalCount"`
+}
+
+type searchNode struct {
+	IssueCount int `json:"issueCount"`
+}
+
+// repositoryFields selects everything but contributors. Open issues include
+// open pull requests, as the REST open_issues_count does.
+const repositoryFields = `
+    stargazerCount
+    forkCount
+    licenseInfo { spdxId }
+    issues(states: OPEN) { totalCount }
+    pullRequests(states: OPEN) { totalCount }
+    releases { totalCount }
+    defaultBranchRef {
+      target {
+        ... on Commit {
+          committedDate
+          history(since: $since) { totalCount }
+        }
+      }
+    }`
+
+// fetchRepos queries batch in one request. ok[j] is false for repositories
+// that should be retried over REST: all of them when the request itself
+// fails, or those with an error other than not found.
+func (c *graphqlClient) fetchRepos(ctx context.Context, batch []batchRepo) (results []provider.Result, ok []bool) {
+	results = make([]provider.Result, len(batch))
+	ok = make([]bool, len(batch))
+
+	since := time.Now().AddDate(0, 0, -30).Format("2006-01-02")
+	var q strings.Builder
+	q.WriteString("query($since: GitTimestamp!) {\n")
+	for j, r := range batch {
+		fmt.Fprintf(&q, "  r%d: repository(owner: %s, name: %s) {%s\n  }\n",
+			j, strconv.Quote(r.owner), strconv.Quote(r.name), repositoryFields)
+		search := fmt.Sprintf("repo:%s/%s is:issue is:closed closed:>%s", r.owner, r.name, since)
+		fmt.Fprintf(&q, "  s%d: search(query: %s, type: ISSUE) { issueCount }\n", j, strconv.Quote(search))
+	}
+	q.WriteString("}\n")
+
+	resp, err := c.do(ctx, q.String(), map[string]any{"since": since + "T00:00:00Z"})
+	if err != nil {
+		return results, ok
+	}
+
+	// Errors are reported per aliased field: r<j> for a repository and
+	// s<j> for its search.
+	notFound := make(map[string]bool)
+	failed := make(map[string]string)
+	for _, e := range resp.Errors {
+		if len(e.Path) == 0 {
+			continue
+		}
+		alias, _ := e.Path[0].(string)
+		if e.Type == "NOT_FOUND" {
+			notFound[alias] = true
+		} else if _, seen := failed[alias]; !seen {
+			failed[alias] = e.Message
+		}
+	}
+
+	for j, r := range batch {
+		target := "github:" + r.id
+		alias := "r" + strconv.Itoa(j)
+		if notFound[alias] {
+			results[j] = provider.Result{Target: target, Error: "GitHub API: 404 Not Found"}
+			ok[j] = true
+			continue
+		}
+		if _, bad := failed[alias]; bad {
+			continue
+		}
+		var node *repositoryNode
+		if raw, found := resp.Data[alias]; !found || json.Unmarshal(raw, &node) != nil || node == nil {
+			continue
+		}
+
+		m := &provider.GitHubMetrics{
+			Stars:        node.StargazerCount,
+			Forks:        node.ForkCount,
+			OpenIssues:   node.Issues.TotalCount + node.PullRequests.TotalCount,
+			ReleaseCount: node.Releases.TotalCount,
+		}
+		if node.LicenseInfo != nil {
+			m.License = node.LicenseInfo.SpdxID
+		}
+		if ref := node.DefaultBranchRef; ref != nil && !ref.Target.CommittedDate.IsZero() {
+			m.LastCommitDays = max(0, int(math.Floor(time.Since(ref.Target.CommittedDate).Hours()/24)))
+			m.Commits30d = ref.Target.History.TotalCount
+		}
+
+		result := provider.Result{Target: target, GitHub: m}
+		searchAlias := "s" + strconv.Itoa(j)
+		var search searchNode
+		if msg, bad := failed[searchAlias]; bad {
+			result.Error = "search issues: " + msg
+		} else if raw, found := resp.Data[searchAlias]; !found || json.Unmarshal(raw, &search) != nil {
+			result.Error = "search issues: missing from response"
+		} else {
+			m.IssuesClosed30d = search.IssueCount
+		}
+		results[j] = result
+		ok[j] = true
+	}
+	return results, ok
+}
+
+func (c *graphqlClient) do(ctx context.Context, query string, variables map[string]any) (*graphqlResponse, error) {
+	body, err := json.Marshal(map[string]any{"query": query, "variables": variables})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", c.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%d %s", resp.StatusCode, http.StatusText(resp.StatusCode))
+	}
+
+	var out graphqlResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, err
+	}
+	if out.Data == nil {
+		return nil, fmt.Errorf("no data in response")
+	}
+	return &out, nil
+}
+
+func joinError(existing, msg string) string {
+	if existing == "" {
+		return msg
+	}
+	return existing + "; " + msg
+}
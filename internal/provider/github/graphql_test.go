@@ -0,0 +1,198 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// setupGraphQLServer serves GraphQL batches, answering each aliased
+// repository from repos (keyed by "owner/name") and reporting the others
+// as not found, plus the REST contributors endpoint for every repository.
+func setupGraphQLServer(t *testing.T, queries *int32) *httptest.Server {
+	t.Helper()
+	committed := time.Now().Add(-3 * 24 * time.Hour).UTC().Format(time.RFC3339)
+	repos := map[string]map[string]any{
+		"owner/repo": {
+			"stargazerCount": 1000,
+			"forkCount":      200,
+			"licenseInfo":    map[string]any{"spdxId": "MIT"},
+			"issues":         map[string]any{"totalCount": 30},
+			"pullRequests":   map[string]any{"totalCount": 20},
+			"releases":       map[string]any{"totalCount": 15},
+			"defaultBranchRef": map[string]any{"target": map[string]any{
+				"committedDate": committed,
+				"history":       map[string]any{"totalCount": 120},
+			}},
+		},
+		"owner/empty": {
+			"stargazerCount":   1,
+			"licenseInfo":      nil,
+			"defaultBranchRef": nil,
+		},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /graphql", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(queries, 1)
+		if r.Header.Get("Authorization") != "Bearer test-token" {
+			t.Errorf("missing token, got %q", r.Header.Get("Authorization"))
+		}
+		var req struct {
+			Query     string         `json:"query"`
+			Variables map[string]any `json:"variables"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if req.Variables["since"] == nil {
+			t.Error("missing $since variable")
+		}
+
+		data := map[string]any{}
+		var errs []map[string]any
+		for j := 0; ; j++ {
+			prefix := fmt.Sprintf("r%d: repository(owner: ", j)
+			start := strings.Index(req.Query, prefix)
+			if start < 0 {
+				break
+			}
+			var owner, name string
+			if _, err := fmt.Sscanf(req.Query[start+len(prefix):], "%q, name: %q)", &owner, &name); err != nil {
+				t.Fatalf("unexpected query: %v\n%s", err, req.Query)
+			}
+			alias := fmt.Sprintf("r%d", j)
+			if repo, ok := repos[owner+"/"+name]; ok {
+				data[alias] = repo
+			} else {
+				data[alias] = nil
+				errs = append(errs, map[string]any{"type": "NOT_FOUND", "path": []any{alias}, "message": "Could not resolve to a Repository"})
+			}
+			data[fmt.Sprintf("s%d", j)] = map[string]any{"issueCount": 340}
+		}
+		resp := map[string]any{"data": data}
+		if errs != nil {
+			resp["errors"] = errs
+		}
+		mustEncode(w, resp)
+	})
+	mux.HandleFunc("GET /repos/{owner}/{repo}/contributors", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Link", `<https://api.github.com/repos/o/r/contributors?per_page=1&page=42>; rel="last"`)
+		mustEncode(w, []map[string]any{{"login": "user1"}})
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestFetchBatchGraphQL(t *testing.T) {
+	var queries int32
+	srv := setupGraphQLServer(t, &queries)
+	defer srv.Close()
+
+	p := New("test-token", srv.URL+"/")
+	results := p.FetchBatch(context.Background(), []string{"owner/repo", "owner/missing", "not-valid", "owner/empty"})
+
+	if queries != 1 {
+		t.Errorf("got %d GraphQL queries, want 1", queries)
+	}
+	if len(results) != 4 {
+		t.Fatalf("got %d results, want 4", len(results))
+	}
+
+	r := results[0]
+	if r.Error != "" {
+		t.Fatalf("unexpected error: %s", r.Error)
+	}
+	if r.Target != "github:owner/repo" {
+		t.Errorf("target: got %q", r.Target)
+	}
+	g := r.GitHub
+	if g.Stars != 1000 || g.Forks != 200 || g.License != "MIT" || g.ReleaseCount != 15 {
+		t.Errorf("got %+v", g)
+	}
+	if g.OpenIssues != 50 {
+		t.Errorf("open_issues should include pull requests like REST: got %d, want 50", g.OpenIssues)
+	}
+	if g.Contributors != 42 {
+		t.Errorf("contributors: got %d, want 42", g.Contributors)
+	}
+	if g.LastCommitDays != 3 || g.Commits30d != 120 || g.IssuesClosed30d != 340 {
+		t.Errorf("activity: got last_commit_days=%d commits_30d=%d issues_closed_30d=%d", g.LastCommitDays, g.Commits30d, g.IssuesClosed30d)
+	}
+
+	if results[1].Error != "GitHub API: 404 Not Found" || results[1].GitHub != nil {
+		t.Errorf("missing repo: got %+v", results[1])
+	}
+	if results[2].Error == "" || results[2].Target != "github:not-valid" {
+		t.Errorf("invalid identifier: got %+v", results[2])
+	}
+	if e := results[3]; e.Error != "" || e.GitHub == nil || e.GitHub.LastCommitDays != 0 {
+		t.Errorf("empty repo: got %+v", e)
+	}
+}
+
+func TestFetchBatchTargets(t *testing.T) {
+	var queries int32
+	srv := setupGraphQLServer(t, &queries)
+	defer srv.Close()
+
+	p := New("test-token", srv.URL+"/")
+	ids := []string{"github.com/owner/repo", "https://github.com/owner/missing"}
+	results := p.FetchBatch(context.Background(), ids)
+	for i, r := range results {
+		if r.Target != "github:"+ids[i] {
+			t.Errorf("target should match Fetch: got %q, want %q", r.Target, "github:"+ids[i])
+		}
+	}
+	if results[0].GitHub == nil {
+		t.Errorf("expected metrics for %s: got %+v", ids[0], results[0])
+	}
+}
+
+func TestFetchBatchChunks(t *testing.T) {
+	var queries int32
+	srv := setupGraphQLServer(t, &queries)
+	defer srv.Close()
+
+	ids := make([]string, graphqlBatchSize+1)
+	for i := range ids {
+		ids[i] = "owner/repo"
+	}
+	results := New("test-token", srv.URL+"/").FetchBatch(context.Background(), ids)
+	if queries != 2 {
+		t.Errorf("got %d GraphQL queries, want 2", queries)
+	}
+	for _, r := range results {
+		if r.GitHub == nil || r.GitHub.Stars != 1000 {
+			t.Fatalf("got %+v", r)
+		}
+	}
+}
+
+func TestFetchBatchWithoutToken(t *testing.T) {
+	srv := setupMockServer(t)
+	defer srv.Close()
+
+	results := New("", srv.URL+"/").FetchBatch(context.Background(), []string{"owner/repo"})
+	if r := results[0]; r.Error != "" || r.GitHub == nil || r.GitHub.IssuesClosed30d != 340 {
+		t.Errorf("expected REST result, got %+v", r)
+	}
+}
+
+func TestFetchBatchFallback(t *testing.T) {
+	srv := setupMockServer(t)
+	defer srv.Close()
+
+	// The REST mock has no /graphql route, so the batch query fails with
+	// 404 and every repository is fetched over REST instead.
+	results := New("test-token", srv.URL+"/").FetchBatch(context.Background(), []string{"owner/repo"})
+	if r := results[0]; r.Error != "" || r.GitHub == nil || r.GitHub.Stars != 1000 || r.GitHub.Contributors != 42 {
+		t.Errorf("expected REST fallback result, got %+v", r)
+	}
+}
@@ -0,0 +1,245 @@
+package gitlab
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/yutakobayashidev/repiq/internal/forge"
+	"github.com/yutakobayashidev/repiq/internal/httpclient"
+	"github.com/yutakobayashidev/repiq/internal/provider"
+)
+
+const defaultBaseURL = "https://gitlab.com/api/v4"
+
+// maxPages bounds the pages of 100 items listed for one metric, when
+// GitLab does not report the total.
+const maxPages = 100
+
+// dialect is how the GitLab REST API authenticates and paginates.
+var dialect = forge.Dialect{
+	TokenHeader: "PRIVATE-TOKEN",
+	LimitParam:  "per_page",
+	PageSize:    100,
+	TotalHeader: "X-Total",
+	MaxPages:    maxPages,
+}
+
+var validSegmentRe = regexp.MustCompile(`^[a-zA-Z0-9_.][a-zA-Z0-9_.-]*$`)
+
+// HostResolver returns the API base URL of a self-managed GitLab host, and
+// the token to use there ("" for none).
+type HostResolver func(host string) (baseURL, token string)
+
+// HostBaseURL returns the API base URL of a self-managed GitLab host.
+func HostBaseURL(host string) string {
+	return "https://" + host + "/api/v4"
+}
+
+// Provider fetches project metrics from the GitLab REST API.
+type Provider struct {
+	api     forge.API
+	resolve HostResolver
+}
+
+// New creates a GitLab provider for gitlab.com. Pass empty string for
+// default base URL; token may be empty for public projects.
+func New(baseURL, token string) *Provider {
+	if baseURL == "" {
+		baseURL = defaultBaseURL
+	}
+	return &Provider{
+		api: forge.API{
+			Dialect: dialect,
+			BaseURL: strings.TrimRight(baseURL, "/"),
+			Token:   token,
+			Client:  httpclient.New(),
+		},
+		resolve: func(host string) (string, string) { return HostBaseURL(host), "" },
+	}
+}
+
+// WithTransport sets the HTTP transport p sends its requests through,
+// httpclient.Default unless set, and returns p.
+func (p *Provider) WithTransport(t http.RoundTripper) *Provider {
+	p.api.Client = &http.Client{Transport: t}
+	return p
+}
+
+// WithHosts sets how p reaches the self-managed hosts of identifiers of the
+// form host/group/project, and returns p. By default each host's API is at
+// HostBaseURL and is used without a token.
+func (p *Provider) WithHosts(resolve HostResolver) *Provider {
+	p.resolve = resolve
+	return p
+}
+
+func (p *Provider) Scheme() string { return "gitlab" }
+
+func (p *Provider) Fetch(ctx context.Context, identifier string) (provider.Result, error) {
+	target := "gitlab:" + identifier
+
+	host, path, err := parseIdentifier(identifier)
+	if err != nil {
+		return provider.Result{Target: target, Error: err.Error()}, nil
+	}
+	c := p.api
+	if host != "" {
+		baseURL, token := p.resolve(host)
+		c.BaseURL, c.Token = strings.TrimRight(baseURL, "/"), token
+	}
+	projectPath := "/projects/" + url.PathEscape(path)
+
+	var project struct {
+		StarCount       int `json:"star_count"`
+		ForksCount      int `json:"forks_count"`
+		OpenIssuesCount int `json:"open_issues_count"`
+		License         *struct {
+			Key  string `json:"key"`
+			Name string `json:"name"`
+		} `json:"license"`
+	}
+	if _, err := c.Get(ctx, projectPath, url.Values{"license": {"true"}}, &project); err != nil {
+		return provider.Result{
+			Target: target,
+			Error:  fmt.Sprintf("GitLab API: %s", err.Error()),
+		}, nil
+	}
+
+	metrics := &provider.GitLabMetrics{
+		Stars:      project.StarCount,
+		Forks:      project.ForksCount,
+		OpenIssues: project.OpenIssuesCount,
+	}
+	if project.License != nil {
+		metrics.License = spdxID(project.License.Key, project.License.Name)
+	}
+
+	errs := fetchParallel(ctx, c, projectPath, metrics)
+
+	result := provider.Result{
+		Target: target,
+		GitLab: metrics,
+	}
+	if len(errs) > 0 {
+		result.Error = strings.Join(errs, "; ")
+	}
+	return result, nil
+}
+
+// parseIdentifier splits identifier into its host, empty for gitlab.com,
+// and project path. A first segment containing a dot is a host when at
+// least two segments follow it.
+func parseIdentifier(identifier string) (host, path string, err error) {
+	segments := strings.Split(identifier, "/")
+	if len(segments) >= 3 && strings.Contains(segments[0], ".") {
+		host, segments = segments[0], segments[1:]
+		if !provider.ValidHost(host) {
+			return "", "", fmt.Errorf("invalid GitLab host %q", host)
+		}
+		if strings.EqualFold(host, "gitlab.com") {
+			host = ""
+		}
+	}
+	if len(segments) < 2 {
+		return "", "", fmt.Errorf("invalid identifier %q: expected group/project, group/subgroup/project or host/group/project", identifier)
+	}
+	for _, s := range segments {
+		if !validSegmentRe.MatchString(s) || s == "." || s == ".." {
+			return "", "", fmt.Errorf("invalid identifier %q: groups and project must match [a-zA-Z0-9._-]+", identifier)
+		}
+	}
+	return strings.ToLower(host), strings.Join(segments, "/"), nil
+}
+
+// spdxLicenses maps the license keys GitLab reports to SPDX identifiers,
+// for consistency with the GitHub provider.
+var spdxLicenses = map[string]string{
+	"agpl-3.0":     "AGPL-3.0",
+	"apache-2.0":   "Apache-2.0",
+	"bsd-2-clause": "BSD-2-Clause",
+	"bsd-3-clause": "BSD-3-Clause",
+	"bsl-1.0":      "BSL-1.0",
+	"cc0-1.0":      "CC0-1.0",
+	"epl-2.0":      "EPL-2.0",
+	"gpl-2.0":      "GPL-2.0",
+	"gpl-3.0":      "GPL-3.0",
+	"lgpl-2.1":     "LGPL-2.1",
+	"lgpl-3.0":     "LGPL-3.0",
+	"mit":          "MIT",
+	"mpl-2.0":      "MPL-2.0",
+	"unlicense":    "Unlicense",
+}
+
+func spdxID(key, name string) string {
+	if id, ok := spdxLicenses[strings.ToLower(key)]; ok {
+		return id
+	}
+	if key == "" || strings.EqualFold(key, "other") {
+		return name
+	}
+	return key
+}
+
+func fetchParallel(ctx context.Context, c forge.API, projectPath string, m *provider.GitLabMetrics) []string {
+	since := time.Now().AddDate(0, 0, -30)
+	return forge.Parallel(ctx,
+		func(ctx context.Context) (err error) {
+			m.Contributors, err = c.Count(ctx, projectPath+"/repository/contributors", url.Values{})
+			if err != nil {
+				return fmt.Errorf("contributors: %w", err)
+			}
+			return nil
+		},
+		func(ctx context.Context) (err error) {
+			m.ReleaseCount, err = c.Count(ctx, projectPath+"/releases", url.Values{})
+			if err != nil {
+				return fmt.Errorf("releases: %w", err)
+			}
+			return nil
+		},
+		func(ctx context.Context) (err error) {
+			m.LastCommitDays, err = fetchLastCommitDays(ctx, c, projectPath)
+			if err != nil {
+				return fmt.Errorf("commits: %w", err)
+			}
+			return nil
+		},
+		func(ctx context.Context) (err error) {
+			m.Commits30d, err = c.Count(ctx, projectPath+"/repository/commits", url.Values{
+				"since": {since.UTC().Format(time.RFC3339)},
+			})
+			if err != nil {
+				return fmt.Errorf("commits_30d: %w", err)
+			}
+			return nil
+		},
+		func(ctx context.Context) (err error) {
+			m.IssuesClosed30d, err = forge.ClosedSince(ctx, c, projectPath+"/issues", url.Values{
+				"state":         {"closed"},
+				"updated_after": {since.UTC().Format(time.RFC3339)},
+			}, since, maxPages)
+			if err != nil {
+				return fmt.Errorf("issues: %w", err)
+			}
+			return nil
+		},
+	)
+}
+
+func fetchLastCommitDays(ctx context.Context, c forge.API, projectPath string) (int, error) {
+	var commits []struct {
+		CommittedDate time.Time `json:"committed_date"`
+	}
+	if _, err := c.Get(ctx, projectPath+"/repository/commits", url.Values{"per_page": {"1"}}, &commits); err != nil {
+		return 0, err
+	}
+	if len(commits) == 0 {
+		return 0, nil
+	}
+	return forge.DaysSince(commits[0].CommittedDate), nil
+}
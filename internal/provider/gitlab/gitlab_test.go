@@ -0,0 +1,204 @@
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func mustEncode(w http.ResponseWriter, v any) {
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		panic(err)
+	}
+}
+
+// setupMockServer serves the project group/sub/project. Paths are matched
+// escaped, as GitLab identifies projects by their URL-encoded path.
+func setupMockServer(t *testing.T, token string) *httptest.Server {
+	t.Helper()
+	const project = "/api/v4/projects/group%2Fsub%2Fproject"
+	now := time.Now().UTC()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("PRIVATE-TOKEN") != token {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		q := r.URL.Query()
+		switch r.URL.EscapedPath() {
+		case project:
+			mustEncode(w, map[string]any{
+				"star_count":        500,
+				"forks_count":       80,
+				"open_issues_count": 25,
+				"license":           map[string]any{"key": "apache-2.0", "name": "Apache License 2.0"},
+			})
+		case project + "/repository/contributors":
+			w.Header().Set("X-Total", "42")
+			mustEncode(w, []map[string]any{{"name": "user1"}})
+		case project + "/releases":
+			w.Header().Set("X-Total", "15")
+			mustEncode(w, []map[string]any{{"tag_name": "v1.0"}})
+		case project + "/repository/commits":
+			if q.Get("since") == "" {
+				mustEncode(w, []map[string]any{
+					{"committed_date": now.Add(-3 * 24 * time.Hour).Format(time.RFC3339)},
+				})
+				return
+			}
+			// No X-Total, as for large commit lists: 120 commits over two
+			// pages of 100.
+			n := 1
+			if q.Get("per_page") == "100" {
+				n = 100
+				if q.Get("page") == "2" {
+					n = 20
+				}
+			}
+			if q.Get("page") != "2" {
+				w.Header().Set("X-Next-Page", "2")
+			}
+			mustEncode(w, make([]map[string]any, n))
+		case project + "/issues":
+			if q.Get("state") != "closed" || q.Get("updated_after") == "" {
+				t.Errorf("issues query: got %s", r.URL.RawQuery)
+			}
+			mustEncode(w, []map[string]any{
+				{"closed_at": now.Add(-2 * 24 * time.Hour).Format(time.RFC3339)},
+				{"closed_at": now.Add(-10 * 24 * time.Hour).Format(time.RFC3339)},
+				{"closed_at": now.Add(-40 * 24 * time.Hour).Format(time.RFC3339)},
+			})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestScheme(t *testing.T) {
+	p := New("", "")
+	if p.Scheme() != "gitlab" {
+		t.Errorf("got %q, want %q", p.Scheme(), "gitlab")
+	}
+}
+
+func TestFetchSuccess(t *testing.T) {
+	srv := setupMockServer(t, "")
+	p := New(srv.URL+"/api/v4", "")
+
+	result, err := p.Fetch(context.Background(), "group/sub/project")
+	if err != nil {
+		t.Fatalf("unexpected Go error: %v", err)
+	}
+	if result.Error != "" {
+		t.Fatalf("unexpected error: %s", result.Error)
+	}
+	if result.Target != "gitlab:group/sub/project" {
+		t.Errorf("target: got %q", result.Target)
+	}
+	g := result.GitLab
+	if g.Stars != 500 || g.Forks != 80 || g.OpenIssues != 25 || g.License != "Apache-2.0" {
+		t.Errorf("project: got %+v", g)
+	}
+	if g.Contributors != 42 || g.ReleaseCount != 15 {
+		t.Errorf("counts: got contributors=%d release_count=%d", g.Contributors, g.ReleaseCount)
+	}
+	if g.LastCommitDays != 3 || g.Commits30d != 120 || g.IssuesClosed30d != 2 {
+		t.Errorf("activity: got last_commit_days=%d commits_30d=%d issues_closed_30d=%d", g.LastCommitDays, g.Commits30d, g.IssuesClosed30d)
+	}
+}
+
+func TestFetchSelfManagedHost(t *testing.T) {
+	srv := setupMockServer(t, "host-token")
+	var resolved string
+	p := New("http://unused", "").WithHosts(func(host string) (string, string) {
+		resolved = host
+		return srv.URL + "/api/v4/", "host-token"
+	})
+
+	result, err := p.Fetch(context.Background(), "GitLab.Example.com/group/sub/project")
+	if err != nil {
+		t.Fatalf("unexpected Go error: %v", err)
+	}
+	if result.Error != "" || result.GitLab == nil || result.GitLab.Stars != 500 {
+		t.Fatalf("got %+v", result)
+	}
+	if result.Target != "gitlab:GitLab.Example.com/group/sub/project" {
+		t.Errorf("target: got %q", result.Target)
+	}
+	if resolved != "gitlab.example.com" {
+		t.Errorf("resolved host: got %q", resolved)
+	}
+}
+
+func TestFetchNotFound(t *testing.T) {
+	srv := setupMockServer(t, "")
+	p := New(srv.URL+"/api/v4", "")
+
+	result, err := p.Fetch(context.Background(), "group/missing")
+	if err != nil {
+		t.Fatalf("unexpected Go error: %v", err)
+	}
+	if result.Error != "GitLab API: 404 Not Found" || result.GitLab != nil {
+		t.Errorf("got %+v", result)
+	}
+}
+
+func TestFetchPartialFailure(t *testing.T) {
+	srv := setupMockServer(t, "")
+	partial := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.EscapedPath(), "/releases") {
+			http.Error(w, "boom", http.StatusInternalServerError)
+			return
+		}
+		srv.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer partial.Close()
+
+	result, err := New(partial.URL+"/api/v4", "").Fetch(context.Background(), "group/sub/project")
+	if err != nil {
+		t.Fatalf("unexpected Go error: %v", err)
+	}
+	if result.GitLab == nil || result.GitLab.Stars != 500 || result.GitLab.Contributors != 42 {
+		t.Errorf("expected partial metrics, got %+v", result.GitLab)
+	}
+	if result.Error != "releases: 500 Internal Server Error" {
+		t.Errorf("error: got %q", result.Error)
+	}
+}
+
+func TestParseIdentifier(t *testing.T) {
+	tests := []struct {
+		in         string
+		host, path string
+		wantErr    bool
+	}{
+		{in: "group/project", path: "group/project"},
+		{in: "group/sub/project", path: "group/sub/project"},
+		{in: "gitlab.com/group/project", path: "group/project"},
+		{in: "git.example.com/group/project", host: "git.example.com", path: "group/project"},
+		{in: "git.example.com:8443/a/b/c", host: "git.example.com:8443", path: "a/b/c"},
+		{in: "my.group/project", path: "my.group/project"},
+		{in: "", wantErr: true},
+		{in: "project", wantErr: true},
+		{in: "group/", wantErr: true},
+		{in: "group/project?x=1", wantErr: true},
+		{in: "group/../project", wantErr: true},
+		{in: "bad_host.com!/group/project", wantErr: true},
+	}
+	for _, tt := range tests {
+		host, path, err := parseIdentifier(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseIdentifier(%q): err = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if host != tt.host || path != tt.path {
+			t.Errorf("parseIdentifier(%q): got %q, %q, want %q, %q", tt.in, host, path, tt.host, tt.path)
+		}
+	}
+}
@@ -1,18 +1,22 @@
 package golang
 
 import (
+	"cmp"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math"
 	"net/http"
 	"net/url"
+	"path"
 	"regexp"
 	"strings"
 	"sync"
 	"time"
 	"unicode"
 
+	"github.com/yutakobayashidev/repiq/internal/httpclient"
 	"github.com/yutakobayashidev/repiq/internal/provider"
 )
 
@@ -25,24 +29,91 @@ const (
 
 // Provider fetches metrics from the Go Module Proxy and deps.dev.
 type Provider struct {
-	proxyURL   string
+	proxies    []proxy
 	depsdevURL string
-	client     *http.Client
+	// noProxy and noSumDB are GONOPROXY and GONOSUMDB patterns: modules
+	// that are not fetched through a proxy, and private modules whose
+	// paths are not sent to deps.dev.
+	noProxy string
+	noSumDB string
+	client  *http.Client
 }
 
-// New creates a Go modules provider. Pass empty strings for default URLs.
+// proxy is a GOPROXY entry: a URL, "direct" or "off". fallback is true
+// when the entry is followed by "|", so that any error, not only 404 and
+// 410, moves on to the next entry.
+type proxy struct {
+	url      string
+	fallback bool
+}
+
+// New creates a Go modules provider. proxyURL may be a GOPROXY list. Pass
+// empty strings for default URLs.
 func New(proxyURL, depsdevURL string) *Provider {
-	if proxyURL == "" {
-		proxyURL = defaultProxyURL
+	return NewFromEnv(proxyURL, depsdevURL, func(string) string { return "" })
+}
+
+// NewFromEnv creates a Go modules provider that honours GOPROXY,
+// GONOPROXY, GONOSUMDB and GOPRIVATE as read with getenv, as the go
+// command does. A non-empty proxyURL takes precedence over GOPROXY.
+func NewFromEnv(proxyURL, depsdevURL string, getenv func(string) string) *Provider {
+	proxyURL = cmp.Or(proxyURL, getenv("GOPROXY"), defaultProxyURL)
+	private := getenv("GOPRIVATE")
+	return &Provider{
+		proxies:    parseProxyList(proxyURL),
+		depsdevURL: strings.TrimRight(cmp.Or(depsdevURL, defaultDepsdevURL), "/"),
+		noProxy:    cmp.Or(getenv("GONOPROXY"), private),
+		noSumDB:    cmp.Or(getenv("GONOSUMDB"), private),
+		client:     httpclient.New(),
 	}
-	if depsdevURL == "" {
-		depsdevURL = defaultDepsdevURL
+}
+
+// WithTransport sets the HTTP transport p sends its requests through,
+// httpclient.Default unless set, and returns p.
+func (p *Provider) WithTransport(t http.RoundTripper) *Provider {
+	p.client = &http.Client{Transport: t}
+	return p
+}
+
+// parseProxyList parses a GOPROXY value: entries separated by "," or "|".
+func parseProxyList(list string) []proxy {
+	var proxies []proxy
+	for list != "" {
+		entry, sep := list, byte(0)
+		if i := strings.IndexAny(list, ",|"); i >= 0 {
+			entry, sep, list = list[:i], list[i], list[i+1:]
+		} else {
+			list = ""
+		}
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		proxies = append(proxies, proxy{url: strings.TrimRight(entry, "/"), fallback: sep == '|'})
 	}
-	return &Provider{
-		proxyURL:   strings.TrimRight(proxyURL, "/"),
-		depsdevURL: strings.TrimRight(depsdevURL, "/"),
-		client:     &http.Client{Timeout: 30 * time.Second},
+	return proxies
+}
+
+// matchPrefixPatterns reports whether any of the comma-separated glob
+// patterns matches a prefix of the module path, with the semantics of
+// GOPRIVATE: a pattern of n path elements is matched against the first n
+// elements of the path.
+func matchPrefixPatterns(patterns, module string) bool {
+	for _, pattern := range strings.Split(patterns, ",") {
+		pattern = strings.TrimRight(strings.TrimSpace(pattern), "/")
+		if pattern == "" {
+			continue
+		}
+		n := strings.Count(pattern, "/") + 1
+		elems := strings.SplitN(module, "/", n+1)
+		if len(elems) < n {
+			continue
+		}
+		if ok, _ := path.Match(pattern, strings.Join(elems[:n], "/")); ok {
+			return true
+		}
 	}
+	return false
 }
 
 func (p *Provider) Scheme() string { return "go" }
@@ -72,6 +143,17 @@ func (p *Provider) Fetch(ctx context.Context, identifier string) (provider.Resul
 		LatestVersion:   proxyInfo.Version,
 		LastPublishDays: days,
 	}
+	// Modules hosted on a forge name their repository in the path; others
+	// (vanity paths such as golang.org/x/text) are resolved via deps.dev.
+	if repo := provider.NormalizeRepoURL("https://" + identifier); provider.IsForgeRepo(repo) {
+		metrics.SourceRepo = repo
+	}
+
+	// deps.dev only knows public modules, and private paths should not
+	// leave the network: their license and dependencies stay unknown.
+	if matchPrefixPatterns(p.noSumDB, identifier) {
+		return provider.Result{Target: "go:" + identifier, Go: metrics, Private: true}, nil
+	}
 
 	var mu sync.Mutex
 	var wg sync.WaitGroup
@@ -81,7 +163,7 @@ func (p *Provider) Fetch(ctx context.Context, identifier string) (provider.Resul
 
 	go func() {
 		defer wg.Done()
-		license, err := p.fetchLicense(ctx, identifier, proxyInfo.Version)
+		info, err := p.fetchVersionInfo(ctx, identifier, proxyInfo.Version)
 		if err != nil {
 			mu.Lock()
 			errs = append(errs, fmt.Sprintf("license: %s", err.Error()))
@@ -89,7 +171,10 @@ func (p *Provider) Fetch(ctx context.Context, identifier string) (provider.Resul
 			return
 		}
 		mu.Lock()
-		metrics.License = license
+		metrics.License = info.license()
+		if metrics.SourceRepo == "" {
+			metrics.SourceRepo = info.sourceRepo()
+		}
 		mu.Unlock()
 	}()
 
@@ -109,9 +194,12 @@ func (p *Provider) Fetch(ctx context.Context, identifier string) (provider.Resul
 
 	wg.Wait()
 
+	// A module served by another proxy than the public one may be
+	// private too.
 	result := provider.Result{
-		Target: "go:" + identifier,
-		Go:     metrics,
+		Target:  "go:" + identifier,
+		Go:      metrics,
+		Private: proxyInfo.proxy != defaultProxyURL,
 	}
 	if len(errs) > 0 {
 		result.Error = strings.Join(errs, "; ")
@@ -122,11 +210,61 @@ func (p *Provider) Fetch(ctx context.Context, identifier string) (provider.Resul
 type proxyResponse struct {
 	Version string    `json:"Version"`
 	Time    time.Time `json:"Time"`
+	// proxy is the URL of the proxy that answered.
+	proxy string
+}
+
+// proxyError is an unsuccessful response from a proxy.
+type proxyError struct {
+	code int
+}
+
+func (e *proxyError) Error() string {
+	return fmt.Sprintf("go proxy: %d %s", e.code, http.StatusText(e.code))
+}
+
+// notFound reports whether err is a 404 or 410 response, after which the
+// go command tries the next GOPROXY entry whatever the separator.
+func notFound(err error) bool {
+	var pe *proxyError
+	return errors.As(err, &pe) && (pe.code == http.StatusNotFound || pe.code == http.StatusGone)
 }
 
+// fetchLatest asks each GOPROXY entry in turn for the latest version of
+// module.
 func (p *Provider) fetchLatest(ctx context.Context, module string) (*proxyResponse, error) {
+	if matchPrefixPatterns(p.noProxy, module) {
+		return nil, fmt.Errorf("%s matches GONOPROXY; repiq cannot fetch modules from version control directly", module)
+	}
+	var lastErr error
+	for _, px := range p.proxies {
+		switch px.url {
+		case "off":
+			return nil, fmt.Errorf("module lookup disabled by GOPROXY=off")
+		case "direct":
+			if lastErr != nil {
+				return nil, lastErr
+			}
+			return nil, fmt.Errorf("GOPROXY=direct; repiq cannot fetch modules from version control directly")
+		}
+		info, err := p.fetchLatestFrom(ctx, px.url, module)
+		if err == nil {
+			return info, nil
+		}
+		lastErr = err
+		if !px.fallback && !notFound(err) {
+			return nil, err
+		}
+	}
+	if lastErr == nil {
+		return nil, fmt.Errorf("GOPROXY lists no proxy")
+	}
+	return nil, lastErr
+}
+
+func (p *Provider) fetchLatestFrom(ctx context.Context, proxyURL, module string) (*proxyResponse, error) {
 	escaped := escapeModulePath(module)
-	u := fmt.Sprintf("%s/%s/@latest", p.proxyURL, escaped)
+	u := fmt.Sprintf("%s/%s/@latest", proxyURL, escaped)
 
 	req, err := http.NewRequestWithContext(ctx, "GET", u, nil)
 	if err != nil {
@@ -140,48 +278,78 @@ func (p *Provider) fetchLatest(ctx context.Context, module string) (*proxyRespon
 	defer func() { _ = resp.Body.Close() }()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("go proxy: %d %s", resp.StatusCode, http.StatusText(resp.StatusCode))
+		return nil, &proxyError{code: resp.StatusCode}
 	}
 
 	var info proxyResponse
 	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
 		return nil, fmt.Errorf("decoding response: %w", err)
 	}
+	info.proxy = proxyURL
 	return &info, nil
 }
 
 type versionInfoResponse struct {
 	Licenses []string `json:"licenses"`
+	Links    []struct {
+		Label string `json:"label"`
+		URL   string `json:"url"`
+	} `json:"links"`
+	RelatedProjects []struct {
+		ProjectKey struct {
+			ID string `json:"id"`
+		} `json:"projectKey"`
+		RelationType string `json:"relationType"`
+	} `json:"relatedProjects"`
+}
+
+func (v *versionInfoResponse) license() string {
+	return strings.Join(v.Licenses, " OR ")
 }
 
-func (p *Provider) fetchLicense(ctx context.Context, module, version string) (string, error) {
+// sourceRepo prefers the project deps.dev has matched to the module (e.g.
+// github.com/golang/text for golang.org/x/text) over the raw SOURCE_REPO
+// link, which may point at a non-forge host such as go.googlesource.com.
+func (v *versionInfoResponse) sourceRepo() string {
+	for _, rp := range v.RelatedProjects {
+		if rp.RelationType == "SOURCE_REPO" {
+			if repo := provider.NormalizeRepoURL("https://" + rp.ProjectKey.ID); repo != "" {
+				return repo
+			}
+		}
+	}
+	for _, l := range v.Links {
+		if l.Label == "SOURCE_REPO" {
+			return provider.NormalizeRepoURL(l.URL)
+		}
+	}
+	return ""
+}
+
+func (p *Provider) fetchVersionInfo(ctx context.Context, module, version string) (*versionInfoResponse, error) {
 	encoded := url.PathEscape(module)
 	u := fmt.Sprintf("%s/v3alpha/systems/go/packages/%s/versions/%s", p.depsdevURL, encoded, url.PathEscape(version))
 
 	req, err := http.NewRequestWithContext(ctx, "GET", u, nil)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	resp, err := p.client.Do(req)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	defer func() { _ = resp.Body.Close() }()
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("deps.dev version: %d %s", resp.StatusCode, http.StatusText(resp.StatusCode))
+		return nil, fmt.Errorf("deps.dev version: %d %s", resp.StatusCode, http.StatusText(resp.StatusCode))
 	}
 
 	var info versionInfoResponse
 	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
-		return "", fmt.Errorf("decoding response: %w", err)
-	}
-
-	if len(info.Licenses) == 0 {
-		return "", nil
+		return nil, fmt.Errorf("decoding response: %w", err)
 	}
-	return strings.Join(info.Licenses, " OR "), nil
+	return &info, nil
 }
 
 type requirementsResponse struct {
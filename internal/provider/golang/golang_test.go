@@ -36,6 +36,12 @@ func setupMockServers(t *testing.T) (proxy *httptest.Server, depsdev *httptest.S
 	depsdevMux.HandleFunc("GET /v3alpha/systems/go/packages/golang.org%2Fx%2Ftext/versions/v0.34.0", func(w http.ResponseWriter, _ *http.Request) {
 		mustEncode(w, map[string]any{
 			"licenses": []string{"BSD-3-Clause"},
+			"links": []map[string]any{
+				{"label": "SOURCE_REPO", "url": "https://go.googlesource.com/text"},
+			},
+			"relatedProjects": []map[string]any{
+				{"projectKey": map[string]any{"id": "github.com/golang/text"}, "relationType": "SOURCE_REPO"},
+			},
 		})
 	})
 	// GET /v3alpha/systems/go/packages/golang.org%2Fx%2Ftext/versions/v0.34.0:requirements
@@ -97,6 +103,9 @@ func TestFetchSuccess(t *testing.T) {
 	if g.License != "BSD-3-Clause" {
 		t.Errorf("license: got %q, want %q", g.License, "BSD-3-Clause")
 	}
+	if g.SourceRepo != "https://github.com/golang/text" {
+		t.Errorf("source_repo: got %q, want %q", g.SourceRepo, "https://github.com/golang/text")
+	}
 }
 
 func TestFetchNotFound(t *testing.T) {
@@ -290,7 +299,124 @@ func TestMajorVersionSuffix(t *testing.T) {
 	if g.License != "Apache-2.0" {
 		t.Errorf("license: got %q, want %q", g.License, "Apache-2.0")
 	}
+	if g.SourceRepo != "https://github.com/owner/repo" {
+		t.Errorf("source_repo: got %q, want %q (from module path)", g.SourceRepo, "https://github.com/owner/repo")
+	}
 	if !strings.Contains(result.Target, "github.com/owner/repo/v2") {
 		t.Errorf("target should contain module path, got %q", result.Target)
 	}
 }
+
+// latestServer serves @latest for every module with status, or a version
+// when status is 200, and counts requests.
+func latestServer(t *testing.T, status int, calls *int) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		*calls++
+		if status != http.StatusOK {
+			w.WriteHeader(status)
+			return
+		}
+		mustEncode(w, map[string]any{"Version": "v1.2.3", "Time": time.Now().Format(time.RFC3339)})
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestProxyList(t *testing.T) {
+	tests := []struct {
+		name        string
+		first       int
+		sep         string
+		wantVersion bool
+		wantSecond  int
+	}{
+		{"404 falls through a comma", http.StatusNotFound, ",", true, 1},
+		{"410 falls through a comma", http.StatusGone, ",", true, 1},
+		{"500 stops at a comma", http.StatusInternalServerError, ",", false, 0},
+		{"500 falls through a pipe", http.StatusInternalServerError, "|", true, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var firstCalls, secondCalls int
+			first := latestServer(t, tt.first, &firstCalls)
+			second := latestServer(t, http.StatusOK, &secondCalls)
+
+			p := New(first.URL+tt.sep+second.URL, "http://unused")
+			p.noSumDB = "*"
+			result, _ := p.Fetch(context.Background(), "example.com/mod")
+			if got := result.Go != nil; got != tt.wantVersion {
+				t.Errorf("got result %+v", result)
+			}
+			if secondCalls != tt.wantSecond {
+				t.Errorf("second proxy got %d calls, want %d", secondCalls, tt.wantSecond)
+			}
+		})
+	}
+}
+
+func TestProxyOffAndDirect(t *testing.T) {
+	for _, list := range []string{"off", "direct"} {
+		result, _ := New(list, "http://unused").Fetch(context.Background(), "example.com/mod")
+		if result.Go != nil || !strings.Contains(result.Error, list) {
+			t.Errorf("GOPROXY=%s: got %+v", list, result)
+		}
+	}
+
+	var calls int
+	srv := latestServer(t, http.StatusNotFound, &calls)
+	result, _ := New(srv.URL+",direct", "http://unused").Fetch(context.Background(), "example.com/mod")
+	if result.Error != "proxy: go proxy: 404 Not Found" {
+		t.Errorf("direct after a 404: got %q, want the proxy's error", result.Error)
+	}
+}
+
+func TestFromEnvPrivateModules(t *testing.T) {
+	var proxyCalls, depsdevCalls int
+	proxy := latestServer(t, http.StatusOK, &proxyCalls)
+	depsdev := latestServer(t, http.StatusOK, &depsdevCalls)
+
+	env := map[string]string{
+		"GOPROXY":   proxy.URL,
+		"GOPRIVATE": "corp.example.com",
+		"GONOPROXY": "none",
+	}
+	p := NewFromEnv("", depsdev.URL, func(k string) string { return env[k] })
+
+	result, _ := p.Fetch(context.Background(), "corp.example.com/team/lib")
+	if result.Go == nil || result.Go.LatestVersion != "v1.2.3" || result.Error != "" || !result.Private {
+		t.Errorf("private module: got %+v", result)
+	}
+	if proxyCalls != 1 || depsdevCalls != 0 {
+		t.Errorf("got %d proxy and %d deps.dev calls, want 1 and 0", proxyCalls, depsdevCalls)
+	}
+
+	env["GONOPROXY"] = ""
+	p = NewFromEnv("", depsdev.URL, func(k string) string { return env[k] })
+	result, _ = p.Fetch(context.Background(), "corp.example.com/team/lib")
+	if result.Go != nil || !strings.Contains(result.Error, "GONOPROXY") {
+		t.Errorf("GOPRIVATE without GONOPROXY should bypass the proxy, got %+v", result)
+	}
+}
+
+func TestMatchPrefixPatterns(t *testing.T) {
+	tests := []struct {
+		patterns, module string
+		want             bool
+	}{
+		{"corp.example.com", "corp.example.com/team/lib", true},
+		{"*.example.com", "corp.example.com/lib", true},
+		{"github.com/corp", "github.com/corp/lib", true},
+		{"github.com/corp/", "github.com/corp/lib", true},
+		{"github.com/corp", "github.com/corporate/lib", false},
+		{"github.com/other,github.com/corp", "github.com/corp/lib", true},
+		{"github.com/corp/lib/v2", "github.com/corp/lib", false},
+		{"none", "github.com/corp/lib", false},
+		{"", "github.com/corp/lib", false},
+	}
+	for _, tt := range tests {
+		if got := matchPrefixPatterns(tt.patterns, tt.module); got != tt.want {
+			t.Errorf("matchPrefixPatterns(%q, %q) = %v, want %v", tt.patterns, tt.module, got, tt.want)
+		}
+	}
+}
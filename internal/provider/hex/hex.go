@@ -0,0 +1,201 @@
+package hex
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/yutakobayashidev/repiq/internal/httpclient"
+	"github.com/yutakobayashidev/repiq/internal/provider"
+)
+
+var validPkgRe = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+const defaultBaseURL = "https://hex.pm"
+
+// Provider fetches metrics from the hex.pm API.
+type Provider struct {
+	baseURL string
+	client  *http.Client
+}
+
+// New creates a Hex provider. Pass empty string for default base URL.
+func New(baseURL string) *Provider {
+	if baseURL == "" {
+		baseURL = defaultBaseURL
+	}
+	return &Provider{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		client:  httpclient.New(),
+	}
+}
+
+// WithTransport sets the HTTP transport p sends its requests through,
+// httpclient.Default unless set, and returns p.
+func (p *Provider) WithTransport(t http.RoundTripper) *Provider {
+	p.client = &http.Client{Transport: t}
+	return p
+}
+
+func (p *Provider) Scheme() string { return "hex" }
+
+func (p *Provider) Fetch(ctx context.Context, identifier string) (provider.Result, error) {
+	target := "hex:" + identifier
+
+	if !validPkgRe.MatchString(identifier) {
+		return provider.Result{
+			Target: target,
+			Error:  fmt.Sprintf("invalid Hex package name %q", identifier),
+		}, nil
+	}
+
+	var pkg packageInfo
+	if err := p.get(ctx, "/api/packages/"+identifier, &pkg); err != nil {
+		return provider.Result{
+			Target: target,
+			Error:  fmt.Sprintf("Hex API: %s", err.Error()),
+		}, nil
+	}
+
+	version := pkg.LatestStableVersion
+	if version == "" {
+		version = pkg.LatestVersion
+	}
+	metrics := &provider.HexMetrics{
+		Downloads:       pkg.Downloads.All,
+		WeeklyDownloads: pkg.Downloads.Week,
+		RecentDownloads: pkg.Downloads.Recent,
+		LatestVersion:   version,
+		License:         strings.Join(pkg.Meta.Licenses, " OR "),
+		SourceRepo:      pkg.Meta.sourceRepo(),
+	}
+	for _, r := range pkg.Releases {
+		if r.Version == version && !r.InsertedAt.IsZero() {
+			metrics.LastPublishDays = daysSince(r.InsertedAt)
+		}
+	}
+	if r, ok := pkg.Retirements[version]; ok {
+		metrics.Retired = true
+		metrics.RetirementReason = r.Reason
+	}
+
+	result := provider.Result{Target: target, Hex: metrics}
+	if version == "" {
+		result.Error = "no releases"
+		return result, nil
+	}
+
+	var rel releaseInfo
+	if err := p.get(ctx, fmt.Sprintf("/api/packages/%s/releases/%s", identifier, version), &rel); err != nil {
+		result.Error = fmt.Sprintf("release: %s", err.Error())
+		return result, nil
+	}
+	for _, req := range rel.Requirements {
+		if !req.Optional {
+			metrics.DependenciesCount++
+		}
+	}
+	if rel.Retirement != nil {
+		metrics.Retired = true
+		metrics.RetirementReason = rel.Retirement.Reason
+	}
+	return result, nil
+}
+
+type retirement struct {
+	Reason  string `json:"reason"`
+	Message string `json:"message"`
+}
+
+type packageInfo struct {
+	Downloads struct {
+		All    int `json:"all"`
+		Week   int `json:"week"`
+		Recent int `json:"recent"`
+	} `json:"downloads"`
+	LatestVersion       string `json:"latest_version"`
+	LatestStableVersion string `json:"latest_stable_version"`
+	Releases            []struct {
+		Version    string    `json:"version"`
+		InsertedAt time.Time `json:"inserted_at"`
+	} `json:"releases"`
+	Retirements map[string]retirement `json:"retirements"`
+	Meta        packageMeta           `json:"meta"`
+}
+
+type packageMeta struct {
+	Licenses []string          `json:"licenses"`
+	Links    map[string]string `json:"links"`
+}
+
+// sourceRepo picks the source repository from the package's links. Link
+// names are free-form, so only URLs on well-known code hosts are accepted,
+// and names that say so are tried first.
+func (m *packageMeta) sourceRepo() string {
+	names := make([]string, 0, len(m.Links))
+	for name := range m.Links {
+		names = append(names, name)
+	}
+	rank := func(name string) int {
+		switch strings.ToLower(name) {
+		case "github", "gitlab", "source", "source code", "repository":
+			return 0
+		}
+		return 1
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if ri, rj := rank(names[i]), rank(names[j]); ri != rj {
+			return ri < rj
+		}
+		return names[i] < names[j]
+	})
+	for _, name := range names {
+		if repo := provider.NormalizeRepoURL(m.Links[name]); repo != "" && provider.IsForgeRepo(repo) {
+			return repo
+		}
+	}
+	return ""
+}
+
+type releaseInfo struct {
+	Requirements map[string]struct {
+		Optional bool `json:"optional"`
+	} `json:"requirements"`
+	Retirement *retirement `json:"retirement"`
+}
+
+func (p *Provider) get(ctx context.Context, path string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", p.baseURL+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%d %s", resp.StatusCode, http.StatusText(resp.StatusCode))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("decoding response: %w", err)
+	}
+	return nil
+}
+
+func daysSince(t time.Time) int {
+	days := int(math.Floor(time.Since(t).Hours() / 24))
+	if days < 0 {
+		days = 0
+	}
+	return days
+}
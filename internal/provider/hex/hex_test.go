@@ -0,0 +1,185 @@
+package hex
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func mustEncode(w http.ResponseWriter, v any) {
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		panic(err)
+	}
+}
+
+func setupMockServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	published12d := time.Now().Add(-12 * 24 * time.Hour).UTC().Format(time.RFC3339)
+
+	mux := http.NewServeMux()
+
+	// GET /api/packages/phoenix — package, with a newer release candidate
+	mux.HandleFunc("GET /api/packages/phoenix", func(w http.ResponseWriter, _ *http.Request) {
+		mustEncode(w, map[string]any{
+			"name":                  "phoenix",
+			"downloads":             map[string]any{"all": 120000000, "week": 250000, "day": 40000, "recent": 3200000},
+			"latest_version":        "1.8.0-rc.0",
+			"latest_stable_version": "1.7.14",
+			"releases": []map[string]any{
+				{"version": "1.8.0-rc.0", "inserted_at": time.Now().Format(time.RFC3339)},
+				{"version": "1.7.14", "inserted_at": published12d},
+			},
+			"retirements": map[string]any{},
+			"meta": map[string]any{
+				"licenses": []string{"MIT"},
+				"links": map[string]string{
+					"Changelog": "https://hexdocs.pm/phoenix/changelog.html",
+					"GitHub":    "https://github.com/phoenixframework/phoenix",
+				},
+			},
+		})
+	})
+
+	// GET /api/packages/phoenix/releases/1.7.14 — requirements
+	mux.HandleFunc("GET /api/packages/phoenix/releases/1.7.14", func(w http.ResponseWriter, _ *http.Request) {
+		mustEncode(w, map[string]any{
+			"version": "1.7.14",
+			"requirements": map[string]any{
+				"plug":           map[string]any{"app": "plug", "optional": false, "requirement": "~> 1.14"},
+				"plug_crypto":    map[string]any{"app": "plug_crypto", "optional": false, "requirement": "~> 1.2 or ~> 2.0"},
+				"telemetry":      map[string]any{"app": "telemetry", "optional": false, "requirement": "~> 0.4 or ~> 1.0"},
+				"jason":          map[string]any{"app": "jason", "optional": true, "requirement": "~> 1.0"},
+				"plug_cowboy":    map[string]any{"app": "plug_cowboy", "optional": true, "requirement": "~> 2.7"},
+				"phoenix_pubsub": map[string]any{"app": "phoenix_pubsub", "optional": false, "requirement": "~> 2.1"},
+			},
+			"retirement": nil,
+		})
+	})
+
+	// A package whose latest release is retired, and whose release
+	// details fail.
+	mux.HandleFunc("GET /api/packages/old_lib", func(w http.ResponseWriter, _ *http.Request) {
+		mustEncode(w, map[string]any{
+			"downloads":             map[string]any{"all": 500},
+			"latest_version":        "0.2.0",
+			"latest_stable_version": "0.2.0",
+			"releases":              []map[string]any{{"version": "0.2.0", "inserted_at": published12d}},
+			"retirements": map[string]any{
+				"0.2.0": map[string]any{"reason": "security", "message": "use 0.2.1"},
+			},
+			"meta": map[string]any{"licenses": []string{"Apache-2.0", "MIT"}},
+		})
+	})
+	mux.HandleFunc("GET /api/packages/old_lib/releases/0.2.0", func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, "boom", http.StatusInternalServerError)
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestScheme(t *testing.T) {
+	p := New("")
+	if p.Scheme() != "hex" {
+		t.Errorf("got %q, want %q", p.Scheme(), "hex")
+	}
+}
+
+func TestFetchSuccess(t *testing.T) {
+	srv := setupMockServer(t)
+	p := New(srv.URL)
+
+	result, err := p.Fetch(context.Background(), "phoenix")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Error != "" {
+		t.Fatalf("unexpected result error: %s", result.Error)
+	}
+	if result.Target != "hex:phoenix" {
+		t.Errorf("target: got %q", result.Target)
+	}
+	h := result.Hex
+	if h == nil {
+		t.Fatal("expected Hex metrics to be set")
+	}
+	if h.Downloads != 120000000 || h.WeeklyDownloads != 250000 || h.RecentDownloads != 3200000 {
+		t.Errorf("downloads: got %d, %d and %d", h.Downloads, h.WeeklyDownloads, h.RecentDownloads)
+	}
+	if h.LatestVersion != "1.7.14" {
+		t.Errorf("latest_version should be the latest stable release: got %q", h.LatestVersion)
+	}
+	if h.LastPublishDays != 12 {
+		t.Errorf("last_publish_days: got %d, want 12", h.LastPublishDays)
+	}
+	if h.DependenciesCount != 4 {
+		t.Errorf("dependencies_count: got %d, want 4 (optional excluded)", h.DependenciesCount)
+	}
+	if h.License != "MIT" {
+		t.Errorf("license: got %q", h.License)
+	}
+	if h.Retired || h.RetirementReason != "" {
+		t.Errorf("got retired %v, reason %q", h.Retired, h.RetirementReason)
+	}
+	if h.SourceRepo != "https://github.com/phoenixframework/phoenix" {
+		t.Errorf("source_repo: got %q", h.SourceRepo)
+	}
+}
+
+func TestFetchRetiredPartialFailure(t *testing.T) {
+	srv := setupMockServer(t)
+	p := New(srv.URL)
+
+	result, err := p.Fetch(context.Background(), "old_lib")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	h := result.Hex
+	if h == nil {
+		t.Fatal("expected Hex metrics despite the failure")
+	}
+	if !strings.HasPrefix(result.Error, "release: 500") {
+		t.Errorf("error: got %q", result.Error)
+	}
+	if !h.Retired || h.RetirementReason != "security" {
+		t.Errorf("got retired %v, reason %q", h.Retired, h.RetirementReason)
+	}
+	if h.License != "Apache-2.0 OR MIT" {
+		t.Errorf("license: got %q", h.License)
+	}
+}
+
+func TestFetchNotFound(t *testing.T) {
+	srv := setupMockServer(t)
+	p := New(srv.URL)
+
+	result, err := p.Fetch(context.Background(), "nonexistent")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Error != "Hex API: 404 Not Found" {
+		t.Errorf("error: got %q", result.Error)
+	}
+	if result.Hex != nil {
+		t.Error("expected Hex to be nil on not found")
+	}
+}
+
+func TestFetchInvalidIdentifier(t *testing.T) {
+	p := New("http://unused")
+	for _, id := range []string{"", "Phoenix", "1phoenix", "phoenix/x", "../etc", "phoenix-live"} {
+		result, err := p.Fetch(context.Background(), id)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.Error == "" || result.Hex != nil {
+			t.Errorf("%q: expected a validation error, got %+v", id, result)
+		}
+	}
+}
@@ -0,0 +1,291 @@
+package maven
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/yutakobayashidev/repiq/internal/httpclient"
+	"github.com/yutakobayashidev/repiq/internal/provider"
+)
+
+var validCoordRe = regexp.MustCompile(`^[a-zA-Z0-9_-][a-zA-Z0-9._-]*$`)
+
+const defaultBaseURL = "https://repo1.maven.org/maven2"
+
+// maxParentDepth bounds the parent POMs read for inherited licenses and
+// source repositories.
+const maxParentDepth = 5
+
+// Provider fetches metrics from a Maven repository: Maven Central, or a
+// Nexus or Artifactory repository with the same layout.
+type Provider struct {
+	baseURL string
+	client  *http.Client
+}
+
+// New creates a Maven provider. Pass empty string for default base URL.
+func New(baseURL string) *Provider {
+	if baseURL == "" {
+		baseURL = defaultBaseURL
+	}
+	return &Provider{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		client:  httpclient.New(),
+	}
+}
+
+// WithTransport sets the HTTP transport p sends its requests through,
+// httpclient.Default unless set, and returns p.
+func (p *Provider) WithTransport(t http.RoundTripper) *Provider {
+	p.client = &http.Client{Transport: t}
+	return p
+}
+
+func (p *Provider) Scheme() string { return "maven" }
+
+func (p *Provider) Fetch(ctx context.Context, identifier string) (provider.Result, error) {
+	target := "maven:" + identifier
+
+	group, artifact, err := parseIdentifier(identifier)
+	if err != nil {
+		return provider.Result{Target: target, Error: err.Error()}, nil
+	}
+
+	version, err := p.fetchLatestRelease(ctx, group, artifact)
+	if err != nil {
+		return provider.Result{
+			Target: target,
+			Error:  fmt.Sprintf("Maven repository: %s", err.Error()),
+		}, nil
+	}
+
+	metrics := &provider.MavenMetrics{LatestVersion: version}
+	result := provider.Result{Target: target, Maven: metrics}
+
+	pom, modified, err := p.fetchPOM(ctx, group, artifact, version)
+	if err != nil {
+		result.Error = fmt.Sprintf("pom: %s", err.Error())
+		return result, nil
+	}
+	if !modified.IsZero() {
+		days := int(math.Floor(time.Since(modified).Hours() / 24))
+		if days < 0 {
+			days = 0
+		}
+		metrics.LastPublishDays = days
+	}
+	metrics.DependenciesCount = pom.countDependencies()
+	metrics.License = pom.license()
+	metrics.SourceRepo = pom.sourceRepo()
+	if pom.Parent.ArtifactID != "" {
+		metrics.Parent = pom.Parent.GroupID + ":" + pom.Parent.ArtifactID + ":" + pom.Parent.Version
+	}
+
+	// Licenses and SCM are commonly declared once in a parent POM.
+	parent := pom.Parent
+	for depth := 0; depth < maxParentDepth && parent.ArtifactID != ""; depth++ {
+		if metrics.License != "" && metrics.SourceRepo != "" {
+			break
+		}
+		if !validCoordRe.MatchString(parent.GroupID) || !validCoordRe.MatchString(parent.ArtifactID) || !validCoordRe.MatchString(parent.Version) {
+			break
+		}
+		pp, _, err := p.fetchPOM(ctx, parent.GroupID, parent.ArtifactID, parent.Version)
+		if err != nil {
+			result.Error = fmt.Sprintf("parent pom %s: %s", metrics.Parent, err.Error())
+			break
+		}
+		if metrics.License == "" {
+			metrics.License = pp.license()
+		}
+		if metrics.SourceRepo == "" {
+			metrics.SourceRepo = pp.sourceRepo()
+		}
+		parent = pp.Parent
+	}
+	return result, nil
+}
+
+// parseIdentifier splits a group:artifact identifier.
+func parseIdentifier(identifier string) (group, artifact string, err error) {
+	group, artifact, ok := strings.Cut(identifier, ":")
+	if !ok || group == "" || artifact == "" {
+		return "", "", fmt.Errorf("invalid identifier %q: expected group:artifact", identifier)
+	}
+	if !validCoordRe.MatchString(group) || !validCoordRe.MatchString(artifact) || strings.Contains(group, "..") {
+		return "", "", fmt.Errorf("invalid identifier %q: group and artifact must match [a-zA-Z0-9._-]+", identifier)
+	}
+	return group, artifact, nil
+}
+
+// artifactURL returns the URL of an artifact's directory in the repository.
+func (p *Provider) artifactURL(group, artifact string) string {
+	return p.baseURL + "/" + strings.ReplaceAll(group, ".", "/") + "/" + artifact
+}
+
+type metadata struct {
+	Versioning struct {
+		Latest   string   `xml:"latest"`
+		Release  string   `xml:"release"`
+		Versions []string `xml:"versions>version"`
+	} `xml:"versioning"`
+}
+
+// fetchLatestRelease returns the release version from maven-metadata.xml,
+// or the newest version that is not a snapshot when it is not set.
+func (p *Provider) fetchLatestRelease(ctx context.Context, group, artifact string) (string, error) {
+	var md metadata
+	if _, err := p.get(ctx, p.artifactURL(group, artifact)+"/maven-metadata.xml", &md); err != nil {
+		return "", err
+	}
+	v := md.Versioning
+	if v.Release != "" {
+		return v.Release, nil
+	}
+	for i := len(v.Versions) - 1; i >= 0; i-- {
+		if !strings.HasSuffix(v.Versions[i], "-SNAPSHOT") {
+			return v.Versions[i], nil
+		}
+	}
+	if v.Latest != "" {
+		return v.Latest, nil
+	}
+	return "", fmt.Errorf("no versions in maven-metadata.xml")
+}
+
+type coordinates struct {
+	GroupID    string `xml:"groupId"`
+	ArtifactID string `xml:"artifactId"`
+	Version    string `xml:"version"`
+}
+
+type pom struct {
+	Parent   coordinates `xml:"parent"`
+	Licenses []struct {
+		Name string `xml:"name"`
+	} `xml:"licenses>license"`
+	Dependencies []struct {
+		Scope    string `xml:"scope"`
+		Optional string `xml:"optional"`
+	} `xml:"dependencies>dependency"`
+	SCM struct {
+		URL        string `xml:"url"`
+		Connection string `xml:"connection"`
+	} `xml:"scm"`
+	URL string `xml:"url"`
+}
+
+// fetchPOM returns the POM of a version, and when it was published as
+// reported by the repository's Last-Modified header.
+func (p *Provider) fetchPOM(ctx context.Context, group, artifact, version string) (*pom, time.Time, error) {
+	var doc pom
+	u := fmt.Sprintf("%s/%s/%s-%s.pom", p.artifactURL(group, artifact), version, artifact, version)
+	h, err := p.get(ctx, u, &doc)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	modified, _ := http.ParseTime(h.Get("Last-Modified"))
+	return &doc, modified, nil
+}
+
+// countDependencies counts the compile and runtime dependencies that are
+// not optional. Dependencies without a scope are compile dependencies.
+func (d *pom) countDependencies() int {
+	count := 0
+	for _, dep := range d.Dependencies {
+		scope := strings.TrimSpace(dep.Scope)
+		if scope != "" && scope != "compile" && scope != "runtime" {
+			continue
+		}
+		if strings.TrimSpace(dep.Optional) == "true" {
+			continue
+		}
+		count++
+	}
+	return count
+}
+
+// spdxLicenses maps common POM license names to SPDX identifiers.
+var spdxLicenses = map[string]string{
+	"apache 2":                                 "Apache-2.0",
+	"apache 2.0":                               "Apache-2.0",
+	"apache license 2.0":                       "Apache-2.0",
+	"apache license, version 2.0":              "Apache-2.0",
+	"apache-2.0":                               "Apache-2.0",
+	"the apache license, version 2.0":          "Apache-2.0",
+	"the apache software license, version 2.0": "Apache-2.0",
+	"bsd-3-clause":                             "BSD-3-Clause",
+	"eclipse public license - v 2.0":           "EPL-2.0",
+	"eclipse public license 2.0":               "EPL-2.0",
+	"eclipse public license - v 1.0":           "EPL-1.0",
+	"mit":                                      "MIT",
+	"mit license":                              "MIT",
+	"the mit license":                          "MIT",
+}
+
+// license returns the POM's licenses, as SPDX identifiers where known.
+func (d *pom) license() string {
+	var names []string
+	for _, l := range d.Licenses {
+		name := strings.Join(strings.Fields(l.Name), " ")
+		if name == "" {
+			continue
+		}
+		if id, ok := spdxLicenses[strings.ToLower(name)]; ok {
+			name = id
+		}
+		names = append(names, name)
+	}
+	return strings.Join(names, " OR ")
+}
+
+// sourceRepo returns the repository named by the POM's SCM section, or its
+// project URL when that is a repository on a well-known code host.
+func (d *pom) sourceRepo() string {
+	for _, raw := range []string{d.SCM.URL, d.SCM.Connection} {
+		raw = strings.TrimSpace(raw)
+		raw = strings.TrimPrefix(raw, "scm:")
+		raw = strings.TrimPrefix(raw, "git:")
+		if repo := provider.NormalizeRepoURL(raw); repo != "" {
+			return repo
+		}
+	}
+	if repo := provider.NormalizeRepoURL(strings.TrimSpace(d.URL)); repo != "" && provider.IsForgeRepo(repo) {
+		return repo
+	}
+	return ""
+}
+
+// get decodes the XML document at u into v and returns the response
+// headers.
+func (p *Provider) get(ctx context.Context, u string, v any) (http.Header, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%d %s", resp.StatusCode, http.StatusText(resp.StatusCode))
+	}
+	dec := xml.NewDecoder(resp.Body)
+	// Some POMs declare ISO-8859-1. The fields read here are ASCII in
+	// practice, so any ASCII-compatible encoding is read as is.
+	dec.CharsetReader = func(_ string, input io.Reader) (io.Reader, error) { return input, nil }
+	if err := dec.Decode(v); err != nil {
+		return nil, fmt.Errorf("decoding response: %w", err)
+	}
+	return resp.Header, nil
+}
@@ -0,0 +1,195 @@
+package maven
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const guavaMetadata = `<?xml version="1.0" encoding="UTF-8"?>
+<metadata>
+  <groupId>com.google.guava</groupId>
+  <artifactId>guava</artifactId>
+  <versioning>
+    <latest>33.4.8-jre</latest>
+    <release>33.4.8-jre</release>
+    <versions>
+      <version>33.4.7-jre</version>
+      <version>33.4.8-jre</version>
+    </versions>
+    <lastUpdated>20250424000000</lastUpdated>
+  </versioning>
+</metadata>`
+
+const guavaPOM = `<?xml version="1.0" encoding="ISO-8859-1"?>
+<project xmlns="http://maven.apache.org/POM/4.0.0">
+  <modelVersion>4.0.0</modelVersion>
+  <parent>
+    <groupId>com.google.guava</groupId>
+    <artifactId>guava-parent</artifactId>
+    <version>33.4.8-jre</version>
+  </parent>
+  <artifactId>guava</artifactId>
+  <dependencies>
+    <dependency><groupId>com.google.guava</groupId><artifactId>failureaccess</artifactId></dependency>
+    <dependency><groupId>org.jspecify</groupId><artifactId>jspecify</artifactId><scope>compile</scope></dependency>
+    <dependency><groupId>com.google.j2objc</groupId><artifactId>j2objc-annotations</artifactId><scope>runtime</scope></dependency>
+    <dependency><groupId>junit</groupId><artifactId>junit</artifactId><scope>test</scope></dependency>
+    <dependency><groupId>org.checkerframework</groupId><artifactId>checker-qual</artifactId><optional>true</optional></dependency>
+  </dependencies>
+  <dependencyManagement>
+    <dependencies>
+      <dependency><groupId>x</groupId><artifactId>managed</artifactId></dependency>
+    </dependencies>
+  </dependencyManagement>
+</project>`
+
+const guavaParentPOM = `<?xml version="1.0" encoding="UTF-8"?>
+<project xmlns="http://maven.apache.org/POM/4.0.0">
+  <groupId>com.google.guava</groupId>
+  <artifactId>guava-parent</artifactId>
+  <url>https://github.com/google/guava</url>
+  <licenses>
+    <license>
+      <name>Apache License, Version 2.0</name>
+      <url>http://www.apache.org/licenses/LICENSE-2.0.txt</url>
+    </license>
+  </licenses>
+  <scm>
+    <connection>scm:git:https://github.com/google/guava.git</connection>
+    <url>https://github.com/google/guava</url>
+  </scm>
+</project>`
+
+func setupMockServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	published20d := time.Now().Add(-20 * 24 * time.Hour).UTC().Format(http.TimeFormat)
+
+	mux := http.NewServeMux()
+	serve := func(path, body, modified string) {
+		mux.HandleFunc("GET "+path, func(w http.ResponseWriter, _ *http.Request) {
+			if modified != "" {
+				w.Header().Set("Last-Modified", modified)
+			}
+			_, _ = w.Write([]byte(body))
+		})
+	}
+
+	serve("/maven2/com/google/guava/guava/maven-metadata.xml", guavaMetadata, "")
+	serve("/maven2/com/google/guava/guava/33.4.8-jre/guava-33.4.8-jre.pom", guavaPOM, published20d)
+	serve("/maven2/com/google/guava/guava-parent/33.4.8-jre/guava-parent-33.4.8-jre.pom", guavaParentPOM, "")
+
+	// Metadata without <release>, and a POM whose parent is missing.
+	serve("/maven2/org/example/lib/maven-metadata.xml", `<metadata><versioning>
+		<versions><version>1.0.0</version><version>1.1.0</version><version>1.2.0-SNAPSHOT</version></versions>
+	</versioning></metadata>`, "")
+	serve("/maven2/org/example/lib/1.1.0/lib-1.1.0.pom", `<project>
+		<parent><groupId>org.example</groupId><artifactId>missing-parent</artifactId><version>1</version></parent>
+		<licenses><license><name>MIT License</name></license></licenses>
+	</project>`, "")
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestScheme(t *testing.T) {
+	p := New("")
+	if p.Scheme() != "maven" {
+		t.Errorf("got %q, want %q", p.Scheme(), "maven")
+	}
+}
+
+func TestFetchSuccess(t *testing.T) {
+	srv := setupMockServer(t)
+	p := New(srv.URL + "/maven2/")
+
+	result, err := p.Fetch(context.Background(), "com.google.guava:guava")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Error != "" {
+		t.Fatalf("unexpected result error: %s", result.Error)
+	}
+	if result.Target != "maven:com.google.guava:guava" {
+		t.Errorf("target: got %q", result.Target)
+	}
+	m := result.Maven
+	if m == nil {
+		t.Fatal("expected Maven metrics to be set")
+	}
+	if m.LatestVersion != "33.4.8-jre" {
+		t.Errorf("latest_version: got %q", m.LatestVersion)
+	}
+	if m.LastPublishDays != 20 {
+		t.Errorf("last_publish_days: got %d, want 20", m.LastPublishDays)
+	}
+	if m.DependenciesCount != 3 {
+		t.Errorf("dependencies_count: got %d, want 3 (compile and runtime, not optional)", m.DependenciesCount)
+	}
+	if m.Parent != "com.google.guava:guava-parent:33.4.8-jre" {
+		t.Errorf("parent: got %q", m.Parent)
+	}
+	if m.License != "Apache-2.0" {
+		t.Errorf("license should be inherited from the parent POM: got %q", m.License)
+	}
+	if m.SourceRepo != "https://github.com/google/guava" {
+		t.Errorf("source_repo: got %q", m.SourceRepo)
+	}
+}
+
+func TestFetchParentFailure(t *testing.T) {
+	srv := setupMockServer(t)
+	p := New(srv.URL + "/maven2")
+
+	result, err := p.Fetch(context.Background(), "org.example:lib")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	m := result.Maven
+	if m == nil {
+		t.Fatal("expected Maven metrics despite the failure")
+	}
+	if m.LatestVersion != "1.1.0" {
+		t.Errorf("latest_version should skip snapshots: got %q", m.LatestVersion)
+	}
+	if m.License != "MIT" {
+		t.Errorf("license: got %q", m.License)
+	}
+	if !strings.HasPrefix(result.Error, "parent pom org.example:missing-parent:1: 404") {
+		t.Errorf("error: got %q", result.Error)
+	}
+}
+
+func TestFetchNotFound(t *testing.T) {
+	srv := setupMockServer(t)
+	p := New(srv.URL + "/maven2")
+
+	result, err := p.Fetch(context.Background(), "org.example:missing")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Error != "Maven repository: 404 Not Found" {
+		t.Errorf("error: got %q", result.Error)
+	}
+	if result.Maven != nil {
+		t.Error("expected Maven to be nil on not found")
+	}
+}
+
+func TestFetchInvalidIdentifier(t *testing.T) {
+	p := New("http://unused")
+	for _, id := range []string{"", "guava", "com.google.guava:", ":guava", "com..google:guava", "com/google:guava", "a:b:c"} {
+		result, err := p.Fetch(context.Background(), id)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.Error == "" || result.Maven != nil {
+			t.Errorf("%q: expected a validation error, got %+v", id, result)
+		}
+	}
+}
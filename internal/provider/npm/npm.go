@@ -12,6 +12,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/yutakobayashidev/repiq/internal/httpclient"
 	"github.com/yutakobayashidev/repiq/internal/provider"
 )
 
@@ -26,7 +27,9 @@ const (
 type Provider struct {
 	registryURL  string
 	downloadsURL string
-	client       *http.Client
+	// private is set when registryURL is not the public registry.
+	private bool
+	client  *http.Client
 }
 
 // New creates an npm provider. Pass empty strings for default URLs.
@@ -40,10 +43,18 @@ func New(registryURL, downloadsURL string) *Provider {
 	return &Provider{
 		registryURL:  strings.TrimRight(registryURL, "/"),
 		downloadsURL: strings.TrimRight(downloadsURL, "/"),
-		client:       &http.Client{Timeout: 30 * time.Second},
+		private:      strings.TrimRight(registryURL, "/") != defaultRegistryURL,
+		client:       httpclient.New(),
 	}
 }
 
+// WithTransport sets the HTTP transport p sends its requests through,
+// httpclient.Default unless set, and returns p.
+func (p *Provider) WithTransport(t http.RoundTripper) *Provider {
+	p.client = &http.Client{Transport: t}
+	return p
+}
+
 func (p *Provider) Scheme() string { return "npm" }
 
 func (p *Provider) Fetch(ctx context.Context, identifier string) (provider.Result, error) {
@@ -74,6 +85,7 @@ func (p *Provider) Fetch(ctx context.Context, identifier string) (provider.Resul
 			metrics.LatestVersion = latest.Version
 			metrics.DependenciesCount = len(latest.Dependencies)
 			metrics.License = latest.License
+			metrics.SourceRepo = provider.NormalizeRepoURL(parseRepository(latest.RawRepository))
 			mu.Unlock()
 			return nil
 		}},
@@ -123,7 +135,8 @@ func (p *Provider) Fetch(ctx context.Context, identifier string) (provider.Resul
 	wg.Wait()
 
 	result := provider.Result{
-		Target: "npm:" + identifier,
+		Target:  "npm:" + identifier,
+		Private: p.private,
 	}
 
 	if len(errs) == len(jobs) {
@@ -139,10 +152,11 @@ func (p *Provider) Fetch(ctx context.Context, identifier string) (provider.Resul
 }
 
 type latestResponse struct {
-	Version      string            `json:"version"`
-	Dependencies map[string]string `json:"dependencies"`
-	License      string
-	RawLicense   json.RawMessage `json:"license"`
+	Version       string            `json:"version"`
+	Dependencies  map[string]string `json:"dependencies"`
+	License       string
+	RawLicense    json.RawMessage `json:"license"`
+	RawRepository json.RawMessage `json:"repository"`
 }
 
 func (p *Provider) fetchLatest(ctx context.Context, pkg string) (*latestResponse, error) {
@@ -190,6 +204,27 @@ func parseLicense(raw json.RawMessage) string {
 	return ""
 }
 
+// parseRepository extracts the URL from the "repository" field, which is
+// either a string ("github:user/repo", a URL) or {"type": "git", "url": ...}.
+func parseRepository(raw json.RawMessage) string {
+	if len(raw) == 0 {
+		return ""
+	}
+
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+
+	var obj struct {
+		URL string `json:"url"`
+	}
+	if err := json.Unmarshal(raw, &obj); err == nil {
+		return obj.URL
+	}
+	return ""
+}
+
 func (p *Provider) fetchLastPublishDays(ctx context.Context, pkg string) (int, error) {
 	u := fmt.Sprintf("%s/%s", p.registryURL, pkg)
 	req, err := http.NewRequestWithContext(ctx, "GET", u, nil)
@@ -27,6 +27,11 @@ func setupMockServers(t *testing.T) (registry *httptest.Server, downloads *httpt
 			"name":    "react",
 			"version": "19.1.0",
 			"license": "MIT",
+			"repository": map[string]any{
+				"type":      "git",
+				"url":       "git+https://github.com/facebook/react.git",
+				"directory": "packages/react",
+			},
 			"dependencies": map[string]any{
 				"loose-envify":    "^1.1.0",
 				"object-assign":   "^4.1.1",
@@ -80,6 +85,15 @@ func TestScheme(t *testing.T) {
 	}
 }
 
+func TestPrivateRegistry(t *testing.T) {
+	if New("", "").private || New("https://registry.npmjs.org/", "").private {
+		t.Error("the public registry should not be private")
+	}
+	if !New("https://npm.example.com/repository/npm", "").private {
+		t.Error("a configured registry should be private")
+	}
+}
+
 func TestFetchSuccess(t *testing.T) {
 	reg, dl := setupMockServers(t)
 	p := New(reg.URL, dl.URL)
@@ -113,6 +127,9 @@ func TestFetchSuccess(t *testing.T) {
 	if n.License != "MIT" {
 		t.Errorf("license: got %q, want %q", n.License, "MIT")
 	}
+	if n.SourceRepo != "https://github.com/facebook/react" {
+		t.Errorf("source_repo: got %q, want %q", n.SourceRepo, "https://github.com/facebook/react")
+	}
 	if n.LastPublishDays < 14 || n.LastPublishDays > 16 {
 		t.Errorf("last_publish_days: got %d, want ~15", n.LastPublishDays)
 	}
@@ -341,3 +358,20 @@ func TestFetchPartialFailure(t *testing.T) {
 		t.Errorf("expected error to mention downloads, got %q", result.Error)
 	}
 }
+
+func TestParseRepository(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want string
+	}{
+		{`"github:user/repo"`, "github:user/repo"},
+		{`{"type":"git","url":"https://github.com/user/repo.git"}`, "https://github.com/user/repo.git"},
+		{`null`, ""},
+		{``, ""},
+	}
+	for _, tt := range tests {
+		if got := parseRepository(json.RawMessage(tt.raw)); got != tt.want {
+			t.Errorf("parseRepository(%s) = %q, want %q", tt.raw, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,332 @@
+package nuget
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yutakobayashidev/repiq/internal/httpclient"
+	"github.com/yutakobayashidev/repiq/internal/provider"
+)
+
+var validIDRe = regexp.MustCompile(`^[a-zA-Z0-9_][a-zA-Z0-9_.-]{0,99}$`)
+
+const defaultIndexURL = "https://api.nuget.org/v3/index.json"
+
+// Resource types of the service index, most preferred first. The 3.6.0
+// registrations include SemVer 2.0.0 packages.
+var (
+	registrationTypes = []string{"RegistrationsBaseUrl/3.6.0", "RegistrationsBaseUrl/3.4.0", "RegistrationsBaseUrl"}
+	searchTypes       = []string{"SearchQueryService/3.5.0", "SearchQueryService/3.0.0-rc", "SearchQueryService"}
+)
+
+// Provider fetches metrics from a NuGet v3 feed.
+type Provider struct {
+	indexURL string
+	client   *http.Client
+
+	mu        sync.Mutex
+	resources *resources
+}
+
+// resources are the service index resources the provider uses.
+type resources struct {
+	registrations string
+	search        string
+}
+
+// New creates a NuGet provider from the URL of a feed's v3 service index.
+// Pass empty string for nuget.org.
+func New(indexURL string) *Provider {
+	if indexURL == "" {
+		indexURL = defaultIndexURL
+	}
+	return &Provider{
+		indexURL: indexURL,
+		client:   httpclient.New(),
+	}
+}
+
+// WithTransport sets the HTTP transport p sends its requests through,
+// httpclient.Default unless set, and returns p.
+func (p *Provider) WithTransport(t http.RoundTripper) *Provider {
+	p.client = &http.Client{Transport: t}
+	return p
+}
+
+func (p *Provider) Scheme() string { return "nuget" }
+
+func (p *Provider) Fetch(ctx context.Context, identifier string) (provider.Result, error) {
+	target := "nuget:" + identifier
+
+	if !validIDRe.MatchString(identifier) {
+		return provider.Result{
+			Target: target,
+			Error:  fmt.Sprintf("invalid NuGet package ID %q", identifier),
+		}, nil
+	}
+
+	res, err := p.serviceIndex(ctx)
+	if err != nil {
+		return provider.Result{
+			Target: target,
+			Error:  fmt.Sprintf("NuGet service index: %s", err.Error()),
+		}, nil
+	}
+
+	entry, err := p.fetchLatest(ctx, res.registrations, identifier)
+	if err != nil {
+		return provider.Result{
+			Target: target,
+			Error:  fmt.Sprintf("NuGet API: %s", err.Error()),
+		}, nil
+	}
+
+	metrics := &provider.NuGetMetrics{}
+	entry.fill(metrics)
+	result := provider.Result{Target: target, NuGet: metrics}
+
+	// Download counts are only served by the search resource.
+	if res.search == "" {
+		return result, nil
+	}
+	downloads, err := p.fetchTotalDownloads(ctx, res.search, identifier)
+	if err != nil {
+		result.Error = fmt.Sprintf("downloads: %s", err.Error())
+		return result, nil
+	}
+	metrics.TotalDownloads = downloads
+	return result, nil
+}
+
+// serviceIndex returns the feed's resources, reading the service index on
+// first use.
+func (p *Provider) serviceIndex(ctx context.Context) (*resources, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.resources != nil {
+		return p.resources, nil
+	}
+
+	var index struct {
+		Resources []struct {
+			ID   string `json:"@id"`
+			Type string `json:"@type"`
+		} `json:"resources"`
+	}
+	if err := p.get(ctx, p.indexURL, &index); err != nil {
+		return nil, err
+	}
+	byType := make(map[string]string)
+	for _, r := range index.Resources {
+		byType[r.Type] = r.ID
+	}
+	pick := func(types []string) string {
+		for _, t := range types {
+			if id, ok := byType[t]; ok {
+				return id
+			}
+		}
+		return ""
+	}
+
+	res := &resources{
+		registrations: pick(registrationTypes),
+		search:        pick(searchTypes),
+	}
+	if res.registrations == "" {
+		return nil, fmt.Errorf("no RegistrationsBaseUrl resource")
+	}
+	if !strings.HasSuffix(res.registrations, "/") {
+		res.registrations += "/"
+	}
+	p.resources = res
+	return res, nil
+}
+
+type registrationPage struct {
+	ID    string `json:"@id"`
+	Items []struct {
+		CatalogEntry catalogEntry `json:"catalogEntry"`
+	} `json:"items"`
+}
+
+type catalogEntry struct {
+	Version           string    `json:"version"`
+	Listed            *bool     `json:"listed"`
+	Published         time.Time `json:"published"`
+	LicenseExpression string    `json:"licenseExpression"`
+	ProjectURL        string    `json:"projectUrl"`
+	DependencyGroups  []struct {
+		TargetFramework string            `json:"targetFramework"`
+		Dependencies    []json.RawMessage `json:"dependencies"`
+	} `json:"dependencyGroups"`
+	Deprecation *struct {
+		Reasons []string `json:"reasons"`
+	} `json:"deprecation"`
+}
+
+func (e *catalogEntry) listed() bool {
+	return e.Listed == nil || *e.Listed
+}
+
+func (e *catalogEntry) stable() bool {
+	v, _, _ := strings.Cut(e.Version, "+")
+	return !strings.Contains(v, "-")
+}
+
+func (e *catalogEntry) fill(m *provider.NuGetMetrics) {
+	m.LatestVersion = e.Version
+	m.Listed = e.listed()
+	m.License = e.LicenseExpression
+	// Unlisted versions report a 1900 publish date.
+	if e.Published.Year() > 1900 {
+		days := int(math.Floor(time.Since(e.Published).Hours() / 24))
+		if days < 0 {
+			days = 0
+		}
+		m.LastPublishDays = days
+	}
+	if e.Deprecation != nil {
+		m.Deprecation = strings.Join(e.Deprecation.Reasons, ", ")
+		if m.Deprecation == "" {
+			m.Deprecation = "Deprecated"
+		}
+	}
+	if repo := provider.NormalizeRepoURL(e.ProjectURL); repo != "" && provider.IsForgeRepo(repo) {
+		m.SourceRepo = repo
+	}
+
+	m.TargetFrameworks = []string{}
+	broadest := -1
+	for i, g := range e.DependencyGroups {
+		if g.TargetFramework != "" {
+			m.TargetFrameworks = append(m.TargetFrameworks, g.TargetFramework)
+		}
+		if broadest < 0 || broader(g.TargetFramework, e.DependencyGroups[broadest].TargetFramework) {
+			broadest = i
+		}
+	}
+	if broadest >= 0 {
+		g := e.DependencyGroups[broadest]
+		m.DependenciesFramework = g.TargetFramework
+		m.DependenciesCount = len(g.Dependencies)
+	}
+}
+
+// broader reports whether the dependency group of framework a applies to
+// more platforms than that of b: a group for any framework first, then
+// .NET Standard, oldest version first, then the oldest other framework.
+func broader(a, b string) bool {
+	rank := func(f string) int {
+		switch f = strings.ToLower(f); {
+		case f == "":
+			return 0
+		case strings.HasPrefix(f, ".netstandard"), strings.HasPrefix(f, "netstandard"):
+			return 1
+		}
+		return 2
+	}
+	if ra, rb := rank(a), rank(b); ra != rb {
+		return ra < rb
+	}
+	return strings.ToLower(a) < strings.ToLower(b)
+}
+
+// fetchLatest returns the catalog entry of the latest stable version,
+// preferring listed versions, or of the latest version when none is
+// stable. Registration pages are ordered by version and may have to be
+// fetched separately, so they are read newest first until a listed stable
+// version turns up.
+func (p *Provider) fetchLatest(ctx context.Context, registrations, id string) (*catalogEntry, error) {
+	var index struct {
+		Items []registrationPage `json:"items"`
+	}
+	if err := p.get(ctx, registrations+strings.ToLower(id)+"/index.json", &index); err != nil {
+		return nil, err
+	}
+
+	var latest, unlisted *catalogEntry
+	for i := len(index.Items) - 1; i >= 0; i-- {
+		page := index.Items[i]
+		if len(page.Items) == 0 {
+			if err := p.get(ctx, page.ID, &page); err != nil {
+				return nil, err
+			}
+		}
+		for j := len(page.Items) - 1; j >= 0; j-- {
+			e := &page.Items[j].CatalogEntry
+			if latest == nil {
+				latest = e
+			}
+			if !e.stable() {
+				continue
+			}
+			if e.listed() {
+				return e, nil
+			}
+			if unlisted == nil {
+				unlisted = e
+			}
+		}
+	}
+	if unlisted != nil {
+		return unlisted, nil
+	}
+	if latest == nil {
+		return nil, fmt.Errorf("no versions")
+	}
+	return latest, nil
+}
+
+func (p *Provider) fetchTotalDownloads(ctx context.Context, search, id string) (int, error) {
+	q := url.Values{
+		"q":           {"packageid:" + id},
+		"prerelease":  {"true"},
+		"semVerLevel": {"2.0.0"},
+		"take":        {"1"},
+	}
+	var resp struct {
+		Data []struct {
+			ID             string `json:"id"`
+			TotalDownloads int    `json:"totalDownloads"`
+		} `json:"data"`
+	}
+	if err := p.get(ctx, search+"?"+q.Encode(), &resp); err != nil {
+		return 0, err
+	}
+	for _, d := range resp.Data {
+		if strings.EqualFold(d.ID, id) {
+			return d.TotalDownloads, nil
+		}
+	}
+	return 0, fmt.Errorf("package not found in search")
+}
+
+func (p *Provider) get(ctx context.Context, u string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", u, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%d %s", resp.StatusCode, http.StatusText(resp.StatusCode))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("decoding response: %w", err)
+	}
+	return nil
+}
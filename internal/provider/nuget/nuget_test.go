@@ -0,0 +1,240 @@
+package nuget
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func mustEncode(w http.ResponseWriter, v any) {
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		panic(err)
+	}
+}
+
+func leaf(version string, fields map[string]any) map[string]any {
+	entry := map[string]any{"version": version}
+	for k, v := range fields {
+		entry[k] = v
+	}
+	return map[string]any{"catalogEntry": entry}
+}
+
+func setupMockServer(t *testing.T) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+
+	published30d := time.Now().Add(-30 * 24 * time.Hour).UTC().Format(time.RFC3339)
+	var indexHits atomic.Int32
+
+	mux := http.NewServeMux()
+	var srv *httptest.Server
+
+	mux.HandleFunc("GET /v3/index.json", func(w http.ResponseWriter, _ *http.Request) {
+		indexHits.Add(1)
+		mustEncode(w, map[string]any{
+			"version": "3.0.0",
+			"resources": []map[string]any{
+				{"@id": srv.URL + "/search", "@type": "SearchQueryService/3.5.0"},
+				{"@id": srv.URL + "/registration-old/", "@type": "RegistrationsBaseUrl"},
+				{"@id": srv.URL + "/registration", "@type": "RegistrationsBaseUrl/3.6.0"},
+			},
+		})
+	})
+
+	// Newtonsoft.Json: the newest page has to be fetched on its own and
+	// ends with a prerelease.
+	mux.HandleFunc("GET /registration/newtonsoft.json/index.json", func(w http.ResponseWriter, _ *http.Request) {
+		mustEncode(w, map[string]any{
+			"items": []map[string]any{
+				{"@id": srv.URL + "/registration/newtonsoft.json/page/1.0.0/12.0.3.json", "items": []map[string]any{
+					leaf("12.0.3", map[string]any{"published": "2019-11-09T01:27:30Z"}),
+				}},
+				{"@id": srv.URL + "/registration/newtonsoft.json/page/13.0.1/14.0.1-beta1.json"},
+			},
+		})
+	})
+	mux.HandleFunc("GET /registration/newtonsoft.json/page/13.0.1/14.0.1-beta1.json", func(w http.ResponseWriter, _ *http.Request) {
+		mustEncode(w, map[string]any{
+			"items": []map[string]any{
+				leaf("13.0.3", map[string]any{
+					"published":         published30d,
+					"listed":            true,
+					"licenseExpression": "MIT",
+					"projectUrl":        "https://github.com/JamesNK/Newtonsoft.Json",
+					"dependencyGroups": []map[string]any{
+						{"targetFramework": ".NETFramework4.5"},
+						{"targetFramework": "net6.0", "dependencies": []map[string]any{{"id": "A"}}},
+						{"targetFramework": ".NETStandard2.0", "dependencies": []map[string]any{{"id": "A"}, {"id": "B"}}},
+						{"targetFramework": ".NETStandard1.3", "dependencies": []map[string]any{{"id": "A"}, {"id": "B"}, {"id": "C"}}},
+					},
+				}),
+				leaf("14.0.1-beta1", map[string]any{"published": published30d}),
+			},
+		})
+	})
+	mux.HandleFunc("GET /search", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("q") != "packageid:Newtonsoft.Json" {
+			mustEncode(w, map[string]any{"data": []any{}})
+			return
+		}
+		mustEncode(w, map[string]any{
+			"data": []map[string]any{{"id": "Newtonsoft.Json", "totalDownloads": 5000000000}},
+		})
+	})
+
+	// Old.Lib: the only stable version is unlisted and deprecated, and its
+	// dependency groups include one for any framework.
+	mux.HandleFunc("GET /registration/old.lib/index.json", func(w http.ResponseWriter, _ *http.Request) {
+		mustEncode(w, map[string]any{
+			"items": []map[string]any{
+				{"items": []map[string]any{
+					leaf("1.0.0-rc1", map[string]any{"published": published30d}),
+					leaf("2.0.0", map[string]any{
+						"published":   "1900-01-01T00:00:00Z",
+						"listed":      false,
+						"deprecation": map[string]any{"reasons": []string{"Legacy", "CriticalBugs"}},
+						"dependencyGroups": []map[string]any{
+							{"targetFramework": ".NETStandard2.0", "dependencies": []map[string]any{{"id": "A"}}},
+							{"dependencies": []map[string]any{{"id": "A"}, {"id": "B"}}},
+						},
+					}),
+				}},
+			},
+		})
+	})
+
+	srv = httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv, &indexHits
+}
+
+func TestScheme(t *testing.T) {
+	p := New("")
+	if p.Scheme() != "nuget" {
+		t.Errorf("got %q, want %q", p.Scheme(), "nuget")
+	}
+}
+
+func TestFetchSuccess(t *testing.T) {
+	srv, _ := setupMockServer(t)
+	p := New(srv.URL + "/v3/index.json")
+
+	result, err := p.Fetch(context.Background(), "Newtonsoft.Json")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Error != "" {
+		t.Fatalf("unexpected result error: %s", result.Error)
+	}
+	if result.Target != "nuget:Newtonsoft.Json" {
+		t.Errorf("target: got %q", result.Target)
+	}
+	n := result.NuGet
+	if n == nil {
+		t.Fatal("expected NuGet metrics to be set")
+	}
+	if n.TotalDownloads != 5000000000 {
+		t.Errorf("total_downloads: got %d", n.TotalDownloads)
+	}
+	if n.LatestVersion != "13.0.3" {
+		t.Errorf("latest_version should skip prereleases: got %q", n.LatestVersion)
+	}
+	if n.LastPublishDays != 30 {
+		t.Errorf("last_publish_days: got %d, want 30", n.LastPublishDays)
+	}
+	if n.DependenciesFramework != ".NETStandard1.3" || n.DependenciesCount != 3 {
+		t.Errorf("dependencies: got %d for %q, want 3 for .NETStandard1.3", n.DependenciesCount, n.DependenciesFramework)
+	}
+	if strings.Join(n.TargetFrameworks, ",") != ".NETFramework4.5,net6.0,.NETStandard2.0,.NETStandard1.3" {
+		t.Errorf("target_frameworks: got %v", n.TargetFrameworks)
+	}
+	if n.License != "MIT" {
+		t.Errorf("license: got %q", n.License)
+	}
+	if !n.Listed || n.Deprecation != "" {
+		t.Errorf("got listed %v and deprecation %q", n.Listed, n.Deprecation)
+	}
+	if n.SourceRepo != "https://github.com/JamesNK/Newtonsoft.Json" {
+		t.Errorf("source_repo: got %q", n.SourceRepo)
+	}
+}
+
+func TestFetchUnlistedDeprecated(t *testing.T) {
+	srv, _ := setupMockServer(t)
+	p := New(srv.URL + "/v3/index.json")
+
+	result, err := p.Fetch(context.Background(), "Old.Lib")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	n := result.NuGet
+	if n == nil {
+		t.Fatalf("expected NuGet metrics, got error %q", result.Error)
+	}
+	if !strings.HasPrefix(result.Error, "downloads: ") {
+		t.Errorf("error: got %q", result.Error)
+	}
+	if n.LatestVersion != "2.0.0" || n.Listed {
+		t.Errorf("latest_version should be the unlisted stable version: got %q (listed %v)", n.LatestVersion, n.Listed)
+	}
+	if n.Deprecation != "Legacy, CriticalBugs" {
+		t.Errorf("deprecation: got %q", n.Deprecation)
+	}
+	if n.LastPublishDays != 0 {
+		t.Errorf("last_publish_days should ignore the unlisted placeholder date: got %d", n.LastPublishDays)
+	}
+	if n.DependenciesFramework != "" || n.DependenciesCount != 2 {
+		t.Errorf("dependencies: got %d for %q, want 2 for any framework", n.DependenciesCount, n.DependenciesFramework)
+	}
+	if strings.Join(n.TargetFrameworks, ",") != ".NETStandard2.0" {
+		t.Errorf("target_frameworks: got %v", n.TargetFrameworks)
+	}
+}
+
+func TestFetchNotFound(t *testing.T) {
+	srv, _ := setupMockServer(t)
+	p := New(srv.URL + "/v3/index.json")
+
+	result, err := p.Fetch(context.Background(), "Missing")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Error != "NuGet API: 404 Not Found" {
+		t.Errorf("error: got %q", result.Error)
+	}
+	if result.NuGet != nil {
+		t.Error("expected NuGet to be nil on not found")
+	}
+}
+
+func TestServiceIndexCached(t *testing.T) {
+	srv, hits := setupMockServer(t)
+	p := New(srv.URL + "/v3/index.json")
+
+	for range 3 {
+		if result, _ := p.Fetch(context.Background(), "Newtonsoft.Json"); result.Error != "" {
+			t.Fatalf("unexpected result error: %s", result.Error)
+		}
+	}
+	if got := hits.Load(); got != 1 {
+		t.Errorf("service index fetched %d times, want 1", got)
+	}
+}
+
+func TestFetchInvalidIdentifier(t *testing.T) {
+	p := New("http://unused")
+	for _, id := range []string{"", "../etc", "Newtonsoft.Json/x", ".hidden", "a?b", strings.Repeat("a", 101)} {
+		result, err := p.Fetch(context.Background(), id)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.Error == "" || result.NuGet != nil {
+			t.Errorf("%q: expected a validation error, got %+v", id, result)
+		}
+	}
+}
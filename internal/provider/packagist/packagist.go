@@ -0,0 +1,310 @@
+package packagist
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yutakobayashidev/repiq/internal/httpclient"
+	"github.com/yutakobayashidev/repiq/internal/provider"
+)
+
+// validPkgRe is Composer's package name pattern.
+var validPkgRe = regexp.MustCompile(`^[a-z0-9]([_.-]?[a-z0-9]+)*/[a-z0-9](([_.]|-{1,2})?[a-z0-9]+)*$`)
+
+const (
+	defaultRepoURL  = "https://repo.packagist.org"
+	defaultStatsURL = "https://packagist.org"
+)
+
+// Provider fetches metrics from a Composer repository's metadata and the
+// packagist.org API.
+type Provider struct {
+	repoURL  string
+	statsURL string
+	client   *http.Client
+}
+
+// New creates a Packagist provider. Pass empty strings for default URLs.
+func New(repoURL, statsURL string) *Provider {
+	if repoURL == "" {
+		repoURL = defaultRepoURL
+	}
+	if statsURL == "" {
+		statsURL = defaultStatsURL
+	}
+	return &Provider{
+		repoURL:  strings.TrimRight(repoURL, "/"),
+		statsURL: strings.TrimRight(statsURL, "/"),
+		client:   httpclient.New(),
+	}
+}
+
+// WithTransport sets the HTTP transport p sends its requests through,
+// httpclient.Default unless set, and returns p.
+func (p *Provider) WithTransport(t http.RoundTripper) *Provider {
+	p.client = &http.Client{Transport: t}
+	return p
+}
+
+func (p *Provider) Scheme() string { return "packagist" }
+
+func (p *Provider) Fetch(ctx context.Context, identifier string) (provider.Result, error) {
+	if !validPkgRe.MatchString(identifier) {
+		return provider.Result{
+			Target: "packagist:" + identifier,
+			Error:  fmt.Sprintf("invalid Composer package name %q: expected vendor/package", identifier),
+		}, nil
+	}
+
+	metrics := &provider.PackagistMetrics{}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var errs []string
+
+	type job struct {
+		name string
+		fn   func(context.Context) error
+	}
+
+	jobs := []job{
+		{"metadata", func(ctx context.Context) error {
+			v, err := p.fetchLatestStable(ctx, identifier)
+			if err != nil {
+				return err
+			}
+			mu.Lock()
+			metrics.LatestVersion = v.Version
+			metrics.License = strings.Join(v.License, " OR ")
+			metrics.DependenciesCount = v.countDependencies()
+			metrics.LastPublishDays = v.lastPublishDays()
+			if repo := provider.NormalizeRepoURL(v.Source.URL); repo != "" {
+				metrics.SourceRepo = repo
+			}
+			mu.Unlock()
+			return nil
+		}},
+		{"stats", func(ctx context.Context) error {
+			pkg, err := p.fetchStats(ctx, identifier)
+			if err != nil {
+				return err
+			}
+			mu.Lock()
+			metrics.TotalDownloads = pkg.Downloads.Total
+			metrics.MonthlyDownloads = pkg.Downloads.Monthly
+			metrics.DailyDownloads = pkg.Downloads.Daily
+			metrics.Favers = pkg.Favers
+			metrics.Abandoned, metrics.Replacement = pkg.abandoned()
+			mu.Unlock()
+			return nil
+		}},
+	}
+
+	wg.Add(len(jobs))
+	for _, j := range jobs {
+		go func(j job) {
+			defer wg.Done()
+			if err := j.fn(ctx); err != nil {
+				mu.Lock()
+				errs = append(errs, fmt.Sprintf("%s: %s", j.name, err.Error()))
+				mu.Unlock()
+			}
+		}(j)
+	}
+	wg.Wait()
+
+	result := provider.Result{
+		Target: "packagist:" + identifier,
+	}
+
+	if len(errs) == len(jobs) {
+		result.Error = strings.Join(errs, "; ")
+		return result, nil
+	}
+
+	result.Packagist = metrics
+	if len(errs) > 0 {
+		result.Error = strings.Join(errs, "; ")
+	}
+	return result, nil
+}
+
+type version struct {
+	Version           string            `json:"version"`
+	VersionNormalized string            `json:"version_normalized"`
+	Time              string            `json:"time"`
+	License           []string          `json:"license"`
+	Require           map[string]string `json:"require"`
+	Source            struct {
+		URL string `json:"url"`
+	} `json:"source"`
+}
+
+// stable reports whether v is a tagged release without a stability suffix
+// such as -beta1 or -RC2.
+func (v *version) stable() bool {
+	return v.VersionNormalized != "" && !strings.Contains(v.VersionNormalized, "-") && !strings.HasPrefix(v.VersionNormalized, "dev-")
+}
+
+// newer reports whether v has a higher normalized version than w.
+func (v *version) newer(w *version) bool {
+	a, b := strings.Split(v.VersionNormalized, "."), strings.Split(w.VersionNormalized, ".")
+	for i := 0; i < len(a) && i < len(b); i++ {
+		x, _ := strconv.Atoi(a[i])
+		y, _ := strconv.Atoi(b[i])
+		if x != y {
+			return x > y
+		}
+	}
+	return len(a) > len(b)
+}
+
+// countDependencies counts require entries that are packages. Platform
+// requirements (php, ext-*, lib-*, composer-plugin-api) have no vendor and
+// are not counted.
+func (v *version) countDependencies() int {
+	count := 0
+	for name := range v.Require {
+		if strings.Contains(name, "/") {
+			count++
+		}
+	}
+	return count
+}
+
+func (v *version) lastPublishDays() int {
+	t, err := time.Parse(time.RFC3339, v.Time)
+	if err != nil {
+		return 0
+	}
+	days := int(math.Floor(time.Since(t).Hours() / 24))
+	if days < 0 {
+		days = 0
+	}
+	return days
+}
+
+// fetchLatestStable returns the highest stable release from the Composer v2
+// metadata, or the first release listed when none is stable.
+func (p *Provider) fetchLatestStable(ctx context.Context, pkg string) (*version, error) {
+	var data struct {
+		Minified string                                  `json:"minified"`
+		Packages map[string][]map[string]json.RawMessage `json:"packages"`
+	}
+	u := fmt.Sprintf("%s/p2/%s.json", p.repoURL, pkg)
+	if err := p.get(ctx, u, "Composer repository", &data); err != nil {
+		return nil, err
+	}
+
+	entries := data.Packages[pkg]
+	if data.Minified == "composer/2.0" {
+		entries = expand(entries)
+	}
+
+	var latest *version
+	for i, e := range entries {
+		raw, err := json.Marshal(e)
+		if err != nil {
+			return nil, err
+		}
+		var v version
+		if err := json.Unmarshal(raw, &v); err != nil {
+			return nil, fmt.Errorf("decoding version: %w", err)
+		}
+		switch {
+		case i == 0:
+			latest = &v
+		case v.stable() && (!latest.stable() || v.newer(latest)):
+			latest = &v
+		}
+	}
+	if latest == nil {
+		return nil, fmt.Errorf("no releases")
+	}
+	return latest, nil
+}
+
+// expand undoes Composer's metadata minification, in which each version
+// lists only the fields that differ from the version before it and
+// "__unset" removes a field.
+func expand(entries []map[string]json.RawMessage) []map[string]json.RawMessage {
+	out := make([]map[string]json.RawMessage, len(entries))
+	prev := map[string]json.RawMessage{}
+	for i, e := range entries {
+		cur := make(map[string]json.RawMessage, len(prev)+len(e))
+		for k, v := range prev {
+			cur[k] = v
+		}
+		for k, v := range e {
+			if string(v) == `"__unset"` {
+				delete(cur, k)
+				continue
+			}
+			cur[k] = v
+		}
+		out[i] = cur
+		prev = cur
+	}
+	return out
+}
+
+type statsPackage struct {
+	Downloads struct {
+		Total   int `json:"total"`
+		Monthly int `json:"monthly"`
+		Daily   int `json:"daily"`
+	} `json:"downloads"`
+	Favers    int             `json:"favers"`
+	Abandoned json.RawMessage `json:"abandoned"`
+}
+
+// abandoned decodes the abandoned field, which is false, true, or the name
+// of the suggested replacement.
+func (s *statsPackage) abandoned() (bool, string) {
+	var replacement string
+	if err := json.Unmarshal(s.Abandoned, &replacement); err == nil {
+		return true, replacement
+	}
+	var abandoned bool
+	_ = json.Unmarshal(s.Abandoned, &abandoned)
+	return abandoned, ""
+}
+
+func (p *Provider) fetchStats(ctx context.Context, pkg string) (*statsPackage, error) {
+	var data struct {
+		Package statsPackage `json:"package"`
+	}
+	u := fmt.Sprintf("%s/packages/%s.json", p.statsURL, pkg)
+	if err := p.get(ctx, u, "Packagist API", &data); err != nil {
+		return nil, err
+	}
+	return &data.Package, nil
+}
+
+func (p *Provider) get(ctx context.Context, u, api string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", u, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: %d %s", api, resp.StatusCode, http.StatusText(resp.StatusCode))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("decoding response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,213 @@
+package packagist
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func mustEncode(w http.ResponseWriter, v any) {
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		panic(err)
+	}
+}
+
+func setupMockServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	published5d := time.Now().Add(-5 * 24 * time.Hour).UTC().Format(time.RFC3339)
+
+	mux := http.NewServeMux()
+
+	// GET /p2/laravel/framework.json — minified Composer v2 metadata,
+	// newest first, starting with a prerelease.
+	mux.HandleFunc("GET /p2/laravel/framework.json", func(w http.ResponseWriter, _ *http.Request) {
+		mustEncode(w, map[string]any{
+			"minified": "composer/2.0",
+			"packages": map[string]any{
+				"laravel/framework": []map[string]any{
+					{
+						"name":               "laravel/framework",
+						"version":            "v12.1.0-beta1",
+						"version_normalized": "12.1.0.0-beta1",
+						"time":               published5d,
+						"license":            []string{"MIT"},
+						"source":             map[string]any{"url": "https://github.com/laravel/framework.git", "type": "git"},
+						"require": map[string]any{
+							"php": "^8.2", "ext-mbstring": "*", "composer-runtime-api": "^2.2",
+							"symfony/console": "^7.2", "symfony/mailer": "^7.2", "league/flysystem": "^3.25",
+						},
+					},
+					{
+						"version":            "v12.0.1",
+						"version_normalized": "12.0.1.0",
+					},
+					{
+						"version":            "v12.0.0",
+						"version_normalized": "12.0.0.0",
+						"time":               "2025-02-24T14:00:00+00:00",
+						"require":            "__unset",
+					},
+				},
+			},
+		})
+	})
+
+	// GET /packages/laravel/framework.json — statistics
+	mux.HandleFunc("GET /packages/laravel/framework.json", func(w http.ResponseWriter, _ *http.Request) {
+		mustEncode(w, map[string]any{
+			"package": map[string]any{
+				"name":      "laravel/framework",
+				"downloads": map[string]any{"total": 450000000, "monthly": 9000000, "daily": 300000},
+				"favers":    34000,
+				"abandoned": false,
+			},
+		})
+	})
+
+	// An abandoned package whose statistics are unavailable from the
+	// mirror and whose metadata is not minified.
+	mux.HandleFunc("GET /p2/swiftmailer/swiftmailer.json", func(w http.ResponseWriter, _ *http.Request) {
+		mustEncode(w, map[string]any{
+			"packages": map[string]any{
+				"swiftmailer/swiftmailer": []map[string]any{
+					{"version": "v6.3.0", "version_normalized": "6.3.0.0", "time": published5d, "license": []string{"MIT"}},
+				},
+			},
+		})
+	})
+	mux.HandleFunc("GET /packages/swiftmailer/swiftmailer.json", func(w http.ResponseWriter, _ *http.Request) {
+		mustEncode(w, map[string]any{
+			"package": map[string]any{"abandoned": "symfony/mailer"},
+		})
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestScheme(t *testing.T) {
+	p := New("", "")
+	if p.Scheme() != "packagist" {
+		t.Errorf("got %q, want %q", p.Scheme(), "packagist")
+	}
+}
+
+func TestFetchSuccess(t *testing.T) {
+	srv := setupMockServer(t)
+	p := New(srv.URL, srv.URL)
+
+	result, err := p.Fetch(context.Background(), "laravel/framework")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Error != "" {
+		t.Fatalf("unexpected result error: %s", result.Error)
+	}
+	if result.Target != "packagist:laravel/framework" {
+		t.Errorf("target: got %q", result.Target)
+	}
+	m := result.Packagist
+	if m == nil {
+		t.Fatal("expected Packagist metrics to be set")
+	}
+	if m.TotalDownloads != 450000000 || m.MonthlyDownloads != 9000000 || m.DailyDownloads != 300000 {
+		t.Errorf("downloads: got %d, %d and %d", m.TotalDownloads, m.MonthlyDownloads, m.DailyDownloads)
+	}
+	if m.Favers != 34000 {
+		t.Errorf("favers: got %d", m.Favers)
+	}
+	if m.LatestVersion != "v12.0.1" {
+		t.Errorf("latest_version should be the highest stable release: got %q", m.LatestVersion)
+	}
+	// v12.0.1 inherits its time, license, source and require from the
+	// prerelease listed before it.
+	if m.LastPublishDays != 5 {
+		t.Errorf("last_publish_days: got %d, want 5", m.LastPublishDays)
+	}
+	if m.DependenciesCount != 3 {
+		t.Errorf("dependencies_count: got %d, want 3 (platform requirements excluded)", m.DependenciesCount)
+	}
+	if m.License != "MIT" {
+		t.Errorf("license: got %q", m.License)
+	}
+	if m.Abandoned || m.Replacement != "" {
+		t.Errorf("got abandoned %v, replacement %q", m.Abandoned, m.Replacement)
+	}
+	if m.SourceRepo != "https://github.com/laravel/framework" {
+		t.Errorf("source_repo: got %q", m.SourceRepo)
+	}
+}
+
+func TestFetchAbandoned(t *testing.T) {
+	srv := setupMockServer(t)
+	p := New(srv.URL, srv.URL)
+
+	result, err := p.Fetch(context.Background(), "swiftmailer/swiftmailer")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	m := result.Packagist
+	if m == nil {
+		t.Fatalf("expected Packagist metrics, got error %q", result.Error)
+	}
+	if !m.Abandoned || m.Replacement != "symfony/mailer" {
+		t.Errorf("got abandoned %v, replacement %q", m.Abandoned, m.Replacement)
+	}
+	if m.LatestVersion != "v6.3.0" {
+		t.Errorf("latest_version: got %q", m.LatestVersion)
+	}
+}
+
+func TestFetchStatsFailure(t *testing.T) {
+	srv := setupMockServer(t)
+	p := New(srv.URL, srv.URL+"/missing")
+
+	result, err := p.Fetch(context.Background(), "laravel/framework")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Packagist == nil {
+		t.Fatal("expected Packagist metrics despite the failure")
+	}
+	if result.Packagist.LatestVersion != "v12.0.1" {
+		t.Errorf("latest_version: got %q", result.Packagist.LatestVersion)
+	}
+	if !strings.HasPrefix(result.Error, "stats: Packagist API: 404") {
+		t.Errorf("error: got %q", result.Error)
+	}
+}
+
+func TestFetchNotFound(t *testing.T) {
+	srv := setupMockServer(t)
+	p := New(srv.URL, srv.URL)
+
+	result, err := p.Fetch(context.Background(), "nobody/nothing")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Packagist != nil {
+		t.Error("expected Packagist to be nil when every request fails")
+	}
+	if !strings.Contains(result.Error, "metadata: Composer repository: 404") || !strings.Contains(result.Error, "stats: Packagist API: 404") {
+		t.Errorf("error: got %q", result.Error)
+	}
+}
+
+func TestFetchInvalidIdentifier(t *testing.T) {
+	p := New("http://unused", "http://unused")
+	for _, id := range []string{"", "laravel", "Laravel/Framework", "laravel/framework/x", "../etc/passwd", "laravel/", "/framework"} {
+		result, err := p.Fetch(context.Background(), id)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.Error == "" || result.Packagist != nil {
+			t.Errorf("%q: expected a validation error, got %+v", id, result)
+		}
+	}
+}
@@ -10,13 +10,43 @@ type Provider interface {
 
 // Result holds the output for a single target.
 type Result struct {
-	Target string         `json:"target"`
-	GitHub *GitHubMetrics `json:"github,omitempty"`
-	NPM    *NPMMetrics   `json:"npm,omitempty"`
-	PyPI   *PyPIMetrics   `json:"pypi,omitempty"`
-	Crates *CratesMetrics `json:"crates,omitempty"`
-	Go     *GoMetrics     `json:"go,omitempty"`
-	Error  string         `json:"error,omitempty"`
+	Target    string            `json:"target"`
+	GitHub    *GitHubMetrics    `json:"github,omitempty"`
+	GitLab    *GitLabMetrics    `json:"gitlab,omitempty"`
+	Gitea     *GiteaMetrics     `json:"gitea,omitempty"`
+	NPM       *NPMMetrics       `json:"npm,omitempty"`
+	PyPI      *PyPIMetrics      `json:"pypi,omitempty"`
+	Crates    *CratesMetrics    `json:"crates,omitempty"`
+	Go        *GoMetrics        `json:"go,omitempty"`
+	Gem       *GemMetrics       `json:"gem,omitempty"`
+	Maven     *MavenMetrics     `json:"maven,omitempty"`
+	NuGet     *NuGetMetrics     `json:"nuget,omitempty"`
+	Packagist *PackagistMetrics `json:"packagist,omitempty"`
+	Hex       *HexMetrics       `json:"hex,omitempty"`
+	Pub       *PubMetrics       `json:"pub,omitempty"`
+	Docker    *DockerMetrics    `json:"docker,omitempty"`
+	Scorecard *ScorecardMetrics `json:"scorecard,omitempty"`
+	// Source holds the GitHub metrics of a registry package's source
+	// repository when requested with --with-source.
+	Source *Result `json:"source,omitempty"`
+	// Dependency is set when the target was discovered by scanning a
+	// lockfile.
+	Dependency *DependencyInfo `json:"dependency,omitempty"`
+	// Private is set for packages from a private registry and for private
+	// Go modules, whose names are not sent to public services such as
+	// OSV.dev.
+	Private bool `json:"private,omitempty"`
+	// Warnings report problems that leave the target's metrics intact,
+	// such as a failed vulnerability lookup.
+	Warnings []string `json:"warnings,omitempty"`
+	Error    string   `json:"error,omitempty"`
+}
+
+// DependencyInfo describes how a lockfile dependency is reached from the
+// project.
+type DependencyInfo struct {
+	Direct bool     `json:"direct"`
+	Path   []string `json:"path,omitempty"`
 }
 
 // NPMMetrics holds npm registry metrics.
@@ -27,6 +57,8 @@ type NPMMetrics struct {
 	LastPublishDays   int    `json:"last_publish_days"`
 	DependenciesCount int    `json:"dependencies_count"`
 	License           string `json:"license"`
+	SourceRepo        string `json:"source_repo"`
+	VulnMetrics
 }
 
 // PyPIMetrics holds PyPI registry metrics.
@@ -38,6 +70,8 @@ type PyPIMetrics struct {
 	DependenciesCount int    `json:"dependencies_count"`
 	License           string `json:"license"`
 	RequiresPython    string `json:"requires_python"`
+	SourceRepo        string `json:"source_repo"`
+	VulnMetrics
 }
 
 // CratesMetrics holds crates.io registry metrics.
@@ -49,6 +83,8 @@ type CratesMetrics struct {
 	DependenciesCount   int    `json:"dependencies_count"`
 	License             string `json:"license"`
 	ReverseDependencies int    `json:"reverse_dependencies"`
+	SourceRepo          string `json:"source_repo"`
+	VulnMetrics
 }
 
 // GoMetrics holds Go module proxy metrics.
@@ -57,17 +93,196 @@ type GoMetrics struct {
 	LastPublishDays   int    `json:"last_publish_days"`
 	DependenciesCount int    `json:"dependencies_count"`
 	License           string `json:"license"`
+	SourceRepo        string `json:"source_repo"`
+	VulnMetrics
+}
+
+// GemMetrics holds RubyGems registry metrics.
+type GemMetrics struct {
+	Downloads           int    `json:"downloads"`
+	VersionDownloads    int    `json:"version_downloads"`
+	LatestVersion       string `json:"latest_version"`
+	LastPublishDays     int    `json:"last_publish_days"`
+	DependenciesCount   int    `json:"dependencies_count"`
+	License             string `json:"license"`
+	RequiredRubyVersion string `json:"required_ruby_version"`
+	ReverseDependencies int    `json:"reverse_dependencies"`
+	SourceRepo          string `json:"source_repo"`
+	VulnMetrics
+}
+
+// MavenMetrics holds Maven repository metrics. Parent is the
+// group:artifact:version of the parent POM, if any.
+type MavenMetrics struct {
+	LatestVersion     string `json:"latest_version"`
+	LastPublishDays   int    `json:"last_publish_days"`
+	DependenciesCount int    `json:"dependencies_count"`
+	License           string `json:"license"`
+	Parent            string `json:"parent"`
+	SourceRepo        string `json:"source_repo"`
+	VulnMetrics
+}
+
+// NuGetMetrics holds NuGet feed metrics for the latest stable version.
+// DependenciesCount counts the dependencies of the broadest target
+// framework, named by DependenciesFramework ("" when the group applies to
+// any framework). Deprecation lists the deprecation reasons, if any.
+type NuGetMetrics struct {
+	TotalDownloads        int      `json:"total_downloads"`
+	LatestVersion         string   `json:"latest_version"`
+	LastPublishDays       int      `json:"last_publish_days"`
+	DependenciesCount     int      `json:"dependencies_count"`
+	DependenciesFramework string   `json:"dependencies_framework"`
+	TargetFrameworks      []string `json:"target_frameworks"`
+	License               string   `json:"license"`
+	Deprecation           string   `json:"deprecation"`
+	Listed                bool     `json:"listed"`
+	SourceRepo            string   `json:"source_repo"`
+	VulnMetrics
+}
+
+// PackagistMetrics holds Packagist metrics for the latest stable release.
+// Replacement is the package suggested in place of an abandoned one.
+type PackagistMetrics struct {
+	TotalDownloads    int    `json:"total_downloads"`
+	MonthlyDownloads  int    `json:"monthly_downloads"`
+	DailyDownloads    int    `json:"daily_downloads"`
+	Favers            int    `json:"favers"`
+	LatestVersion     string `json:"latest_version"`
+	LastPublishDays   int    `json:"last_publish_days"`
+	DependenciesCount int    `json:"dependencies_count"`
+	License           string `json:"license"`
+	Abandoned         bool   `json:"abandoned"`
+	Replacement       string `json:"replacement"`
+	SourceRepo        string `json:"source_repo"`
+	VulnMetrics
+}
+
+// HexMetrics holds hex.pm metrics for the latest stable release.
+// RecentDownloads covers the last 90 days. RetirementReason is one of
+// hex.pm's reasons (e.g. "security", "deprecated") when the release is
+// retired.
+type HexMetrics struct {
+	Downloads         int    `json:"downloads"`
+	WeeklyDownloads   int    `json:"weekly_downloads"`
+	RecentDownloads   int    `json:"recent_downloads"`
+	LatestVersion     string `json:"latest_version"`
+	LastPublishDays   int    `json:"last_publish_days"`
+	DependenciesCount int    `json:"dependencies_count"`
+	License           string `json:"license"`
+	Retired           bool   `json:"retired"`
+	RetirementReason  string `json:"retirement_reason"`
+	SourceRepo        string `json:"source_repo"`
+	VulnMetrics
+}
+
+// PubMetrics holds pub.dev metrics. Popularity is a percentage, left at 0
+// when pub.dev no longer reports it. Platforms are those pub.dev's
+// analysis found the package to support.
+type PubMetrics struct {
+	Likes             int      `json:"likes"`
+	PubPoints         int      `json:"pub_points"`
+	Popularity        int      `json:"popularity"`
+	MonthlyDownloads  int      `json:"monthly_downloads"`
+	LatestVersion     string   `json:"latest_version"`
+	LastPublishDays   int      `json:"last_publish_days"`
+	DependenciesCount int      `json:"dependencies_count"`
+	SDKConstraint     string   `json:"sdk_constraint"`
+	Platforms         []string `json:"platforms"`
+	Discontinued      bool     `json:"discontinued"`
+	ReplacedBy        string   `json:"replaced_by"`
+	SourceRepo        string   `json:"source_repo"`
+	VulnMetrics
+}
+
+// DockerMetrics holds container image metrics. Pull and star counts and
+// the official and verified badges are only known for Docker Hub images.
+// Architectures are the os/architecture[/variant] platforms of the latest
+// tag.
+type DockerMetrics struct {
+	PullCount      int      `json:"pull_count"`
+	StarCount      int      `json:"star_count"`
+	LastPushedDays int      `json:"last_pushed_days"`
+	TagCount       int      `json:"tag_count"`
+	Official       bool     `json:"official"`
+	Verified       bool     `json:"verified"`
+	Architectures  []string `json:"architectures"`
+}
+
+// VulnMetrics holds the known vulnerabilities (from OSV.dev) affecting a
+// package's latest version. MaxSeverity is one of LOW, MEDIUM, HIGH,
+// CRITICAL or UNKNOWN, and empty when there are no vulnerabilities.
+type VulnMetrics struct {
+	OpenVulns   int      `json:"open_vulns"`
+	MaxSeverity string   `json:"max_severity"`
+	AdvisoryIDs []string `json:"advisory_ids"`
 }
 
 // GitHubMetrics holds GitHub-specific metrics.
 type GitHubMetrics struct {
-	Stars            int    `json:"stars"`
-	Forks            int    `json:"forks"`
-	OpenIssues       int    `json:"open_issues"`
-	Contributors     int    `json:"contributors"`
-	ReleaseCount     int    `json:"release_count"`
-	LastCommitDays   int    `json:"last_commit_days"`
-	Commits30d       int    `json:"commits_30d"`
-	IssuesClosed30d  int    `json:"issues_closed_30d"`
-	License          string `json:"license"`
+	Stars           int    `json:"stars"`
+	Forks           int    `json:"forks"`
+	OpenIssues      int    `json:"open_issues"`
+	Contributors    int    `json:"contributors"`
+	ReleaseCount    int    `json:"release_count"`
+	LastCommitDays  int    `json:"last_commit_days"`
+	Commits30d      int    `json:"commits_30d"`
+	IssuesClosed30d int    `json:"issues_closed_30d"`
+	License         string `json:"license"`
+}
+
+// GitLabMetrics holds GitLab project metrics, parallel to GitHubMetrics.
+// OpenIssues does not include merge requests.
+type GitLabMetrics struct {
+	Stars           int    `json:"stars"`
+	Forks           int    `json:"forks"`
+	OpenIssues      int    `json:"open_issues"`
+	Contributors    int    `json:"contributors"`
+	ReleaseCount    int    `json:"release_count"`
+	LastCommitDays  int    `json:"last_commit_days"`
+	Commits30d      int    `json:"commits_30d"`
+	IssuesClosed30d int    `json:"issues_closed_30d"`
+	License         string `json:"license"`
+}
+
+// GiteaMetrics holds Gitea and Forgejo repository metrics, including those
+// of Codeberg, parallel to GitHubMetrics. Contributors is estimated from
+// the latest 1000 commits; ContributorsCapped is set when the repository
+// has older ones, making it a lower bound.
+type GiteaMetrics struct {
+	Stars              int    `json:"stars"`
+	Forks              int    `json:"forks"`
+	OpenIssues         int    `json:"open_issues"`
+	Contributors       int    `json:"contributors"`
+	ContributorsCapped bool   `json:"contributors_capped,omitempty"`
+	ReleaseCount       int    `json:"release_count"`
+	LastCommitDays     int    `json:"last_commit_days"`
+	Commits30d         int    `json:"commits_30d"`
+	IssuesClosed30d    int    `json:"issues_closed_30d"`
+	License            string `json:"license"`
+}
+
+// ScorecardMetrics holds OpenSSF Scorecard results. Check scores range from
+// 0 to 10; -1 means the check was inconclusive or not run.
+type ScorecardMetrics struct {
+	Score                float64 `json:"score"`
+	Date                 string  `json:"date"`
+	BinaryArtifacts      int     `json:"binary_artifacts"`
+	BranchProtection     int     `json:"branch_protection"`
+	CIIBestPractices     int     `json:"cii_best_practices"`
+	CITests              int     `json:"ci_tests"`
+	CodeReview           int     `json:"code_review"`
+	Contributors         int     `json:"contributors"`
+	DangerousWorkflow    int     `json:"dangerous_workflow"`
+	DependencyUpdateTool int     `json:"dependency_update_tool"`
+	Fuzzing              int     `json:"fuzzing"`
+	License              int     `json:"license"`
+	Maintained           int     `json:"maintained"`
+	Packaging            int     `json:"packaging"`
+	PinnedDependencies   int     `json:"pinned_dependencies"`
+	SAST                 int     `json:"sast"`
+	SecurityPolicy       int     `json:"security_policy"`
+	SignedReleases       int     `json:"signed_releases"`
+	TokenPermissions     int     `json:"token_permissions"`
+	Vulnerabilities      int     `json:"vulnerabilities"`
 }
@@ -2,6 +2,7 @@ package provider_test
 
 import (
 	"context"
+	"reflect"
 	"testing"
 
 	"github.com/yutakobayashidev/repiq/internal/provider"
@@ -98,3 +99,209 @@ func TestProviderInterface(t *testing.T) {
 		t.Errorf("got scheme %q, want %q", p.Scheme(), "stub")
 	}
 }
+
+func TestResultGitLabSuccess(t *testing.T) {
+	r := provider.Result{
+		Target: "gitlab:gitlab-org/gitlab-runner",
+		GitLab: &provider.GitLabMetrics{
+			Stars:        2500,
+			Contributors: 900,
+			License:      "MIT",
+		},
+	}
+	if r.GitLab == nil {
+		t.Fatal("expected GitLab to be non-nil")
+	}
+	if r.GitHub != nil {
+		t.Error("expected GitHub to be nil for gitlab result")
+	}
+	if key, fields := r.Metrics(); key != "gitlab" || fields["stars"] != 2500 {
+		t.Errorf("got %q %v", key, fields)
+	}
+}
+
+func TestResultGiteaSuccess(t *testing.T) {
+	r := provider.Result{
+		Target: "codeberg:forgejo/forgejo",
+		Gitea: &provider.GiteaMetrics{
+			Stars:        1200,
+			ReleaseCount: 80,
+		},
+	}
+	if r.Gitea == nil {
+		t.Fatal("expected Gitea to be non-nil")
+	}
+	if key, fields := r.Metrics(); key != "gitea" || fields["release_count"] != 80 {
+		t.Errorf("got %q %v", key, fields)
+	}
+}
+
+func TestResultGemSuccess(t *testing.T) {
+	r := provider.Result{
+		Target: "gem:rails",
+		Gem: &provider.GemMetrics{
+			Downloads:           600000000,
+			RequiredRubyVersion: ">= 3.2.0",
+		},
+	}
+	if r.Gem == nil {
+		t.Fatal("expected Gem to be non-nil")
+	}
+	if key, fields := r.Metrics(); key != "gem" || fields["required_ruby_version"] != ">= 3.2.0" {
+		t.Errorf("got %q %v", key, fields)
+	}
+}
+
+func TestResultMavenSuccess(t *testing.T) {
+	r := provider.Result{
+		Target: "maven:com.google.guava:guava",
+		Maven:  &provider.MavenMetrics{LatestVersion: "33.4.8-jre"},
+	}
+	if r.Maven == nil {
+		t.Fatal("expected Maven to be non-nil")
+	}
+	if key, fields := r.Metrics(); key != "maven" || fields["latest_version"] != "33.4.8-jre" {
+		t.Errorf("got %q %v", key, fields)
+	}
+}
+
+func TestResultNuGetSuccess(t *testing.T) {
+	r := provider.Result{
+		Target: "nuget:Newtonsoft.Json",
+		NuGet:  &provider.NuGetMetrics{LatestVersion: "13.0.3", Listed: true},
+	}
+	if r.NuGet == nil {
+		t.Fatal("expected NuGet to be non-nil")
+	}
+	if key, fields := r.Metrics(); key != "nuget" || fields["latest_version"] != "13.0.3" || fields["listed"] != true {
+		t.Errorf("got %q %v", key, fields)
+	}
+}
+
+func TestResultPackagistSuccess(t *testing.T) {
+	r := provider.Result{
+		Target:    "packagist:laravel/framework",
+		Packagist: &provider.PackagistMetrics{LatestVersion: "v12.0.0", Favers: 34000},
+	}
+	if r.Packagist == nil {
+		t.Fatal("expected Packagist to be non-nil")
+	}
+	if key, fields := r.Metrics(); key != "packagist" || fields["favers"] != 34000 {
+		t.Errorf("got %q %v", key, fields)
+	}
+}
+
+func TestResultHexSuccess(t *testing.T) {
+	r := provider.Result{
+		Target: "hex:phoenix",
+		Hex:    &provider.HexMetrics{LatestVersion: "1.7.14", WeeklyDownloads: 250000},
+	}
+	if r.Hex == nil {
+		t.Fatal("expected Hex to be non-nil")
+	}
+	if key, fields := r.Metrics(); key != "hex" || fields["weekly_downloads"] != 250000 {
+		t.Errorf("got %q %v", key, fields)
+	}
+}
+
+func TestResultPubSuccess(t *testing.T) {
+	r := provider.Result{
+		Target: "pub:provider",
+		Pub:    &provider.PubMetrics{LatestVersion: "6.1.5", PubPoints: 160},
+	}
+	if r.Pub == nil {
+		t.Fatal("expected Pub to be non-nil")
+	}
+	if key, fields := r.Metrics(); key != "pub" || fields["pub_points"] != 160 {
+		t.Errorf("got %q %v", key, fields)
+	}
+}
+
+func TestResultDockerSuccess(t *testing.T) {
+	r := provider.Result{
+		Target: "docker:library/nginx",
+		Docker: &provider.DockerMetrics{PullCount: 1000000000, Official: true},
+	}
+	if r.Docker == nil {
+		t.Fatal("expected Docker to be non-nil")
+	}
+	if key, fields := r.Metrics(); key != "docker" || fields["pull_count"] != 1000000000 || fields["official"] != true {
+		t.Errorf("got %q %v", key, fields)
+	}
+}
+
+func TestResultScorecardSuccess(t *testing.T) {
+	r := provider.Result{
+		Target: "scorecard:github.com/ossf/scorecard",
+		Scorecard: &provider.ScorecardMetrics{
+			Score:      8.2,
+			Maintained: 10,
+			CodeReview: 9,
+		},
+	}
+	if r.Scorecard == nil {
+		t.Fatal("expected Scorecard to be non-nil")
+	}
+	if r.GitHub != nil {
+		t.Error("expected GitHub to be nil for scorecard result")
+	}
+	if r.Scorecard.Score != 8.2 {
+		t.Errorf("got score %v, want 8.2", r.Scorecard.Score)
+	}
+	if r.Scorecard.Maintained != 10 {
+		t.Errorf("got maintained %d, want 10", r.Scorecard.Maintained)
+	}
+}
+
+func TestResultMetrics(t *testing.T) {
+	r := provider.Result{
+		Target: "npm:react",
+		NPM: &provider.NPMMetrics{
+			WeeklyDownloads: 25000000,
+			LatestVersion:   "19.1.0",
+			License:         "MIT",
+		},
+	}
+	key, fields := r.Metrics()
+	if key != "npm" {
+		t.Errorf("got key %q, want %q", key, "npm")
+	}
+	if fields["weekly_downloads"] != 25000000 {
+		t.Errorf("got weekly_downloads %v, want 25000000", fields["weekly_downloads"])
+	}
+	if fields["license"] != "MIT" {
+		t.Errorf("got license %v, want MIT", fields["license"])
+	}
+	if _, ok := fields["target"]; ok {
+		t.Error("metrics should not include the target")
+	}
+}
+
+func TestResultMetricsError(t *testing.T) {
+	r := provider.Result{Target: "github:nonexistent/repo", Error: "GitHub API: 404 Not Found"}
+	if key, fields := r.Metrics(); key != "" || fields != nil {
+		t.Errorf("got (%q, %v), want no metrics", key, fields)
+	}
+}
+
+func TestMetricFields(t *testing.T) {
+	fields := provider.MetricFields()
+	tests := []struct {
+		key   string
+		field string
+		kind  reflect.Kind
+	}{
+		{"github", "last_commit_days", reflect.Int},
+		{"npm", "weekly_downloads", reflect.Int},
+		{"pypi", "requires_python", reflect.String},
+		{"crates", "reverse_dependencies", reflect.Int},
+		{"go", "license", reflect.String},
+		{"npm", "open_vulns", reflect.Int},
+		{"crates", "max_severity", reflect.String},
+	}
+	for _, tt := range tests {
+		if got := fields[tt.key][tt.field]; got != tt.kind {
+			t.Errorf("%s.%s: got kind %v, want %v", tt.key, tt.field, got, tt.kind)
+		}
+	}
+}
@@ -0,0 +1,219 @@
+package pub
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yutakobayashidev/repiq/internal/httpclient"
+	"github.com/yutakobayashidev/repiq/internal/provider"
+)
+
+var validPkgRe = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+const defaultBaseURL = "https://pub.dev"
+
+// Provider fetches metrics from the pub.dev API.
+type Provider struct {
+	baseURL string
+	client  *http.Client
+}
+
+// New creates a pub.dev provider. Pass empty string for default base URL.
+func New(baseURL string) *Provider {
+	if baseURL == "" {
+		baseURL = defaultBaseURL
+	}
+	return &Provider{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		client:  httpclient.New(),
+	}
+}
+
+// WithTransport sets the HTTP transport p sends its requests through,
+// httpclient.Default unless set, and returns p.
+func (p *Provider) WithTransport(t http.RoundTripper) *Provider {
+	p.client = &http.Client{Transport: t}
+	return p
+}
+
+func (p *Provider) Scheme() string { return "pub" }
+
+func (p *Provider) Fetch(ctx context.Context, identifier string) (provider.Result, error) {
+	if !validPkgRe.MatchString(identifier) {
+		return provider.Result{
+			Target: "pub:" + identifier,
+			Error:  fmt.Sprintf("invalid pub package name %q", identifier),
+		}, nil
+	}
+
+	metrics := &provider.PubMetrics{}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var errs []string
+
+	type job struct {
+		name string
+		fn   func(context.Context) error
+	}
+
+	jobs := []job{
+		{"metadata", func(ctx context.Context) error {
+			var pkg packageInfo
+			if err := p.get(ctx, "/api/packages/"+identifier, &pkg); err != nil {
+				return err
+			}
+			mu.Lock()
+			metrics.LatestVersion = pkg.Latest.Version
+			metrics.DependenciesCount = len(pkg.Latest.Pubspec.Dependencies)
+			metrics.SDKConstraint = pkg.Latest.Pubspec.Environment.SDK
+			metrics.SourceRepo = pkg.Latest.Pubspec.sourceRepo()
+			if !pkg.Latest.Published.IsZero() {
+				days := int(math.Floor(time.Since(pkg.Latest.Published).Hours() / 24))
+				if days < 0 {
+					days = 0
+				}
+				metrics.LastPublishDays = days
+			}
+			mu.Unlock()
+			return nil
+		}},
+		{"score", func(ctx context.Context) error {
+			var s score
+			if err := p.get(ctx, "/api/packages/"+identifier+"/score", &s); err != nil {
+				return err
+			}
+			mu.Lock()
+			metrics.Likes = s.LikeCount
+			metrics.PubPoints = s.GrantedPoints
+			metrics.MonthlyDownloads = s.DownloadCount30Days
+			if s.PopularityScore != nil {
+				metrics.Popularity = int(math.Round(*s.PopularityScore * 100))
+			}
+			metrics.Platforms = s.platforms()
+			mu.Unlock()
+			return nil
+		}},
+		{"options", func(ctx context.Context) error {
+			var o options
+			if err := p.get(ctx, "/api/packages/"+identifier+"/options", &o); err != nil {
+				return err
+			}
+			mu.Lock()
+			metrics.Discontinued = o.IsDiscontinued
+			metrics.ReplacedBy = o.ReplacedBy
+			mu.Unlock()
+			return nil
+		}},
+	}
+
+	wg.Add(len(jobs))
+	for _, j := range jobs {
+		go func(j job) {
+			defer wg.Done()
+			if err := j.fn(ctx); err != nil {
+				mu.Lock()
+				errs = append(errs, fmt.Sprintf("%s: %s", j.name, err.Error()))
+				mu.Unlock()
+			}
+		}(j)
+	}
+	wg.Wait()
+
+	result := provider.Result{
+		Target: "pub:" + identifier,
+	}
+
+	if len(errs) == len(jobs) {
+		result.Error = strings.Join(errs, "; ")
+		return result, nil
+	}
+
+	result.Pub = metrics
+	if len(errs) > 0 {
+		result.Error = strings.Join(errs, "; ")
+	}
+	return result, nil
+}
+
+type packageInfo struct {
+	Latest struct {
+		Version   string    `json:"version"`
+		Published time.Time `json:"published"`
+		Pubspec   pubspec   `json:"pubspec"`
+	} `json:"latest"`
+}
+
+type pubspec struct {
+	Dependencies map[string]json.RawMessage `json:"dependencies"`
+	Environment  struct {
+		SDK string `json:"sdk"`
+	} `json:"environment"`
+	Repository string `json:"repository"`
+	Homepage   string `json:"homepage"`
+}
+
+// sourceRepo returns the pubspec's repository, or its homepage when that
+// is a repository on a well-known code host.
+func (s *pubspec) sourceRepo() string {
+	if repo := provider.NormalizeRepoURL(s.Repository); repo != "" {
+		return repo
+	}
+	if repo := provider.NormalizeRepoURL(s.Homepage); repo != "" && provider.IsForgeRepo(repo) {
+		return repo
+	}
+	return ""
+}
+
+type score struct {
+	GrantedPoints       int      `json:"grantedPoints"`
+	LikeCount           int      `json:"likeCount"`
+	DownloadCount30Days int      `json:"downloadCount30Days"`
+	PopularityScore     *float64 `json:"popularityScore"`
+	Tags                []string `json:"tags"`
+}
+
+// platforms returns the platforms pub.dev's analysis found the package to
+// support, from its "platform:" tags.
+func (s *score) platforms() []string {
+	platforms := []string{}
+	for _, tag := range s.Tags {
+		if name, ok := strings.CutPrefix(tag, "platform:"); ok {
+			platforms = append(platforms, name)
+		}
+	}
+	return platforms
+}
+
+type options struct {
+	IsDiscontinued bool   `json:"isDiscontinued"`
+	ReplacedBy     string `json:"replacedBy"`
+}
+
+func (p *Provider) get(ctx context.Context, path string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", p.baseURL+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.pub.v2+json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("pub.dev API: %d %s", resp.StatusCode, http.StatusText(resp.StatusCode))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("decoding response: %w", err)
+	}
+	return nil
+}
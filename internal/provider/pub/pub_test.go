@@ -0,0 +1,203 @@
+package pub
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func mustEncode(w http.ResponseWriter, v any) {
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		panic(err)
+	}
+}
+
+func setupMockServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	published60d := time.Now().Add(-60 * 24 * time.Hour).UTC().Format(time.RFC3339)
+
+	mux := http.NewServeMux()
+
+	// GET /api/packages/http — metadata
+	mux.HandleFunc("GET /api/packages/http", func(w http.ResponseWriter, _ *http.Request) {
+		mustEncode(w, map[string]any{
+			"name": "http",
+			"latest": map[string]any{
+				"version":   "1.4.0",
+				"published": published60d,
+				"pubspec": map[string]any{
+					"name":        "http",
+					"version":     "1.4.0",
+					"repository":  "https://github.com/dart-lang/http/tree/master/pkgs/http",
+					"environment": map[string]any{"sdk": "^3.4.0"},
+					"dependencies": map[string]any{
+						"async":       "^2.5.0",
+						"http_parser": "^4.0.0",
+						"meta":        "^1.3.0",
+						"web":         map[string]any{"version": ">=0.5.0 <2.0.0"},
+					},
+					"dev_dependencies": map[string]any{"test": "^1.21.2"},
+				},
+			},
+		})
+	})
+
+	// GET /api/packages/http/score
+	mux.HandleFunc("GET /api/packages/http/score", func(w http.ResponseWriter, _ *http.Request) {
+		mustEncode(w, map[string]any{
+			"grantedPoints":       160,
+			"maxPoints":           160,
+			"likeCount":           8000,
+			"popularityScore":     0.996,
+			"downloadCount30Days": 6000000,
+			"tags": []string{
+				"sdk:dart", "sdk:flutter", "platform:android", "platform:ios", "platform:web",
+				"is:null-safe", "license:bsd-3-clause",
+			},
+		})
+	})
+
+	// GET /api/packages/http/options
+	mux.HandleFunc("GET /api/packages/http/options", func(w http.ResponseWriter, _ *http.Request) {
+		mustEncode(w, map[string]any{"isDiscontinued": false, "replacedBy": nil, "isUnlisted": false})
+	})
+
+	// A discontinued package with no score, whose home page is its
+	// repository.
+	mux.HandleFunc("GET /api/packages/flutter_old", func(w http.ResponseWriter, _ *http.Request) {
+		mustEncode(w, map[string]any{
+			"latest": map[string]any{
+				"version":   "2.0.0",
+				"published": published60d,
+				"pubspec": map[string]any{
+					"homepage":    "https://github.com/someone/flutter_old",
+					"environment": map[string]any{"sdk": ">=2.12.0 <3.0.0", "flutter": ">=2.0.0"},
+					"dependencies": map[string]any{
+						"flutter": map[string]any{"sdk": "flutter"},
+					},
+				},
+			},
+		})
+	})
+	mux.HandleFunc("GET /api/packages/flutter_old/options", func(w http.ResponseWriter, _ *http.Request) {
+		mustEncode(w, map[string]any{"isDiscontinued": true, "replacedBy": "flutter_new"})
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestScheme(t *testing.T) {
+	p := New("")
+	if p.Scheme() != "pub" {
+		t.Errorf("got %q, want %q", p.Scheme(), "pub")
+	}
+}
+
+func TestFetchSuccess(t *testing.T) {
+	srv := setupMockServer(t)
+	p := New(srv.URL)
+
+	result, err := p.Fetch(context.Background(), "http")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Error != "" {
+		t.Fatalf("unexpected result error: %s", result.Error)
+	}
+	if result.Target != "pub:http" {
+		t.Errorf("target: got %q", result.Target)
+	}
+	m := result.Pub
+	if m == nil {
+		t.Fatal("expected Pub metrics to be set")
+	}
+	if m.Likes != 8000 || m.PubPoints != 160 || m.Popularity != 100 {
+		t.Errorf("got likes %d, pub points %d, popularity %d", m.Likes, m.PubPoints, m.Popularity)
+	}
+	if m.MonthlyDownloads != 6000000 {
+		t.Errorf("monthly_downloads: got %d", m.MonthlyDownloads)
+	}
+	if m.LatestVersion != "1.4.0" {
+		t.Errorf("latest_version: got %q", m.LatestVersion)
+	}
+	if m.LastPublishDays != 60 {
+		t.Errorf("last_publish_days: got %d, want 60", m.LastPublishDays)
+	}
+	if m.DependenciesCount != 4 {
+		t.Errorf("dependencies_count: got %d, want 4 (dev_dependencies excluded)", m.DependenciesCount)
+	}
+	if m.SDKConstraint != "^3.4.0" {
+		t.Errorf("sdk_constraint: got %q", m.SDKConstraint)
+	}
+	if strings.Join(m.Platforms, ",") != "android,ios,web" {
+		t.Errorf("platforms: got %v", m.Platforms)
+	}
+	if m.Discontinued || m.ReplacedBy != "" {
+		t.Errorf("got discontinued %v, replaced_by %q", m.Discontinued, m.ReplacedBy)
+	}
+	if m.SourceRepo != "https://github.com/dart-lang/http" {
+		t.Errorf("source_repo: got %q", m.SourceRepo)
+	}
+}
+
+func TestFetchDiscontinuedPartialFailure(t *testing.T) {
+	srv := setupMockServer(t)
+	p := New(srv.URL)
+
+	result, err := p.Fetch(context.Background(), "flutter_old")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	m := result.Pub
+	if m == nil {
+		t.Fatal("expected Pub metrics despite the failure")
+	}
+	if !strings.HasPrefix(result.Error, "score: pub.dev API: 404") {
+		t.Errorf("error: got %q", result.Error)
+	}
+	if !m.Discontinued || m.ReplacedBy != "flutter_new" {
+		t.Errorf("got discontinued %v, replaced_by %q", m.Discontinued, m.ReplacedBy)
+	}
+	if m.SourceRepo != "https://github.com/someone/flutter_old" {
+		t.Errorf("source_repo: got %q", m.SourceRepo)
+	}
+	if m.DependenciesCount != 1 {
+		t.Errorf("dependencies_count: got %d, want 1", m.DependenciesCount)
+	}
+}
+
+func TestFetchNotFound(t *testing.T) {
+	srv := setupMockServer(t)
+	p := New(srv.URL)
+
+	result, err := p.Fetch(context.Background(), "nonexistent")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Pub != nil {
+		t.Error("expected Pub to be nil when every request fails")
+	}
+	if !strings.Contains(result.Error, "metadata: pub.dev API: 404") {
+		t.Errorf("error: got %q", result.Error)
+	}
+}
+
+func TestFetchInvalidIdentifier(t *testing.T) {
+	p := New("http://unused")
+	for _, id := range []string{"", "Http", "1http", "http-client", "../etc", "http/score"} {
+		result, err := p.Fetch(context.Background(), id)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.Error == "" || result.Pub != nil {
+			t.Errorf("%q: expected a validation error, got %+v", id, result)
+		}
+	}
+}
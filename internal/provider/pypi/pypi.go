@@ -11,6 +11,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/yutakobayashidev/repiq/internal/httpclient"
 	"github.com/yutakobayashidev/repiq/internal/provider"
 )
 
@@ -28,7 +29,9 @@ const (
 type Provider struct {
 	pypiURL  string
 	statsURL string
-	client   *http.Client
+	// private is set when pypiURL is not the public index.
+	private bool
+	client  *http.Client
 }
 
 // New creates a PyPI provider. Pass empty strings for default URLs.
@@ -42,10 +45,18 @@ func New(pypiURL, statsURL string) *Provider {
 	return &Provider{
 		pypiURL:  strings.TrimRight(pypiURL, "/"),
 		statsURL: strings.TrimRight(statsURL, "/"),
-		client:   &http.Client{Timeout: 30 * time.Second},
+		private:  strings.TrimRight(pypiURL, "/") != defaultPyPIURL,
+		client:   httpclient.New(),
 	}
 }
 
+// WithTransport sets the HTTP transport p sends its requests through,
+// httpclient.Default unless set, and returns p.
+func (p *Provider) WithTransport(t http.RoundTripper) *Provider {
+	p.client = &http.Client{Transport: t}
+	return p
+}
+
 func (p *Provider) Scheme() string { return "pypi" }
 
 func (p *Provider) Fetch(ctx context.Context, identifier string) (provider.Result, error) {
@@ -81,6 +92,7 @@ func (p *Provider) Fetch(ctx context.Context, identifier string) (provider.Resul
 			metrics.RequiresPython = meta.Info.RequiresPython
 			metrics.DependenciesCount = countNonExtraDeps(meta.Info.RequiresDist)
 			metrics.LastPublishDays = meta.lastPublishDays()
+			metrics.SourceRepo = meta.Info.sourceRepo()
 			mu.Unlock()
 			return nil
 		}},
@@ -111,7 +123,8 @@ func (p *Provider) Fetch(ctx context.Context, identifier string) (provider.Resul
 	wg.Wait()
 
 	result := provider.Result{
-		Target: "pypi:" + identifier,
+		Target:  "pypi:" + identifier,
+		Private: p.private,
 	}
 
 	if len(errs) == len(jobs) {
@@ -127,16 +140,45 @@ func (p *Provider) Fetch(ctx context.Context, identifier string) (provider.Resul
 }
 
 type pypiResponse struct {
-	Info     pypiInfo                       `json:"info"`
-	Releases map[string][]pypiReleaseFile   `json:"releases"`
+	Info     pypiInfo                     `json:"info"`
+	Releases map[string][]pypiReleaseFile `json:"releases"`
 }
 
 type pypiInfo struct {
-	Version           string   `json:"version"`
-	License           string   `json:"license"`
-	LicenseExpression string   `json:"license_expression"`
-	RequiresPython    string   `json:"requires_python"`
-	RequiresDist      []string `json:"requires_dist"`
+	Version           string            `json:"version"`
+	License           string            `json:"license"`
+	LicenseExpression string            `json:"license_expression"`
+	RequiresPython    string            `json:"requires_python"`
+	RequiresDist      []string          `json:"requires_dist"`
+	HomePage          string            `json:"home_page"`
+	ProjectURLs       map[string]string `json:"project_urls"`
+}
+
+// sourceLabels are project_urls labels that conventionally point at the
+// source repository, most specific first.
+var sourceLabels = []string{"source", "source code", "sourcecode", "repository", "code", "github", "gitlab", "homepage", "home"}
+
+// sourceRepo picks the source repository from project_urls, falling back to
+// home_page. project_urls labels are free-form, so only URLs on well-known
+// code hosts are accepted.
+func (i *pypiInfo) sourceRepo() string {
+	byLabel := make(map[string]string, len(i.ProjectURLs))
+	for label, u := range i.ProjectURLs {
+		byLabel[strings.ToLower(strings.TrimSpace(label))] = u
+	}
+	candidates := make([]string, 0, len(sourceLabels)+1)
+	for _, label := range sourceLabels {
+		if u, ok := byLabel[label]; ok {
+			candidates = append(candidates, u)
+		}
+	}
+	candidates = append(candidates, i.HomePage)
+	for _, c := range candidates {
+		if repo := provider.NormalizeRepoURL(c); repo != "" && provider.IsForgeRepo(repo) {
+			return repo
+		}
+	}
+	return ""
 }
 
 type pypiReleaseFile struct {
@@ -32,6 +32,11 @@ func setupMockServers(t *testing.T) (pypiSrv *httptest.Server, statsSrv *httptes
 				"version":         "2.32.5",
 				"license":         "Apache-2.0",
 				"requires_python": ">=3.9",
+				"home_page":       "https://requests.readthedocs.io",
+				"project_urls": map[string]any{
+					"Documentation": "https://requests.readthedocs.io",
+					"Source":        "https://github.com/psf/requests",
+				},
 				"requires_dist": []string{
 					"certifi>=2017.4.17",
 					"charset-normalizer<4,>=2",
@@ -78,6 +83,15 @@ func TestScheme(t *testing.T) {
 	}
 }
 
+func TestPrivateIndex(t *testing.T) {
+	if New("", "").private || New("https://pypi.org/", "").private {
+		t.Error("the public index should not be private")
+	}
+	if !New("https://pypi.example.com", "").private {
+		t.Error("a configured index should be private")
+	}
+}
+
 func TestFetchSuccess(t *testing.T) {
 	pypiSrv, statsSrv := setupMockServers(t)
 	p := New(pypiSrv.URL, statsSrv.URL)
@@ -119,6 +133,9 @@ func TestFetchSuccess(t *testing.T) {
 	if m.RequiresPython != ">=3.9" {
 		t.Errorf("requires_python: got %q, want %q", m.RequiresPython, ">=3.9")
 	}
+	if m.SourceRepo != "https://github.com/psf/requests" {
+		t.Errorf("source_repo: got %q, want %q", m.SourceRepo, "https://github.com/psf/requests")
+	}
 }
 
 func TestFetchNotFound(t *testing.T) {
@@ -358,3 +375,40 @@ func TestFetchExtrasExcluded(t *testing.T) {
 		t.Errorf("dependencies_count: got %d, want 2", result.PyPI.DependenciesCount)
 	}
 }
+
+func TestSourceRepo(t *testing.T) {
+	tests := []struct {
+		name string
+		info pypiInfo
+		want string
+	}{
+		{
+			name: "source label preferred over homepage",
+			info: pypiInfo{ProjectURLs: map[string]string{
+				"Homepage":    "https://github.com/org/website",
+				"Source Code": "https://github.com/org/lib",
+			}},
+			want: "https://github.com/org/lib",
+		},
+		{
+			name: "non-forge homepage ignored",
+			info: pypiInfo{
+				HomePage:    "https://flask.palletsprojects.com",
+				ProjectURLs: map[string]string{"Homepage": "https://flask.palletsprojects.com"},
+			},
+			want: "",
+		},
+		{
+			name: "home_page fallback",
+			info: pypiInfo{HomePage: "https://gitlab.com/group/project"},
+			want: "https://gitlab.com/group/project",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.info.sourceRepo(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
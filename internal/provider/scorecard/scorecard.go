@@ -0,0 +1,134 @@
+package scorecard
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/yutakobayashidev/repiq/internal/httpclient"
+	"github.com/yutakobayashidev/repiq/internal/provider"
+)
+
+// validRepoRe matches "<host>/<owner>/<repo>" for the hosts the Scorecard
+// API serves.
+var validRepoRe = regexp.MustCompile(`^(github\.com|gitlab\.com)/[a-zA-Z0-9._-]+/[a-zA-Z0-9._-]+$`)
+
+const defaultBaseURL = "https://api.securityscorecards.dev"
+
+// Provider fetches OpenSSF Scorecard results from the public Scorecard API.
+type Provider struct {
+	baseURL string
+	client  *http.Client
+}
+
+// New creates a Scorecard provider. Pass empty string for default base URL.
+func New(baseURL string) *Provider {
+	if baseURL == "" {
+		baseURL = defaultBaseURL
+	}
+	return &Provider{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		client:  httpclient.New(),
+	}
+}
+
+// WithTransport sets the HTTP transport p sends its requests through,
+// httpclient.Default unless set, and returns p.
+func (p *Provider) WithTransport(t http.RoundTripper) *Provider {
+	p.client = &http.Client{Transport: t}
+	return p
+}
+
+func (p *Provider) Scheme() string { return "scorecard" }
+
+func (p *Provider) Fetch(ctx context.Context, identifier string) (provider.Result, error) {
+	target := "scorecard:" + identifier
+
+	if !validRepoRe.MatchString(identifier) || strings.Contains(identifier, "..") {
+		return provider.Result{
+			Target: target,
+			Error:  fmt.Sprintf("invalid repository %q: expected <host>/<owner>/<repo>", identifier),
+		}, nil
+	}
+
+	metrics, err := p.fetchProject(ctx, identifier)
+	if err != nil {
+		return provider.Result{
+			Target: target,
+			Error:  fmt.Sprintf("Scorecard API: %s", err.Error()),
+		}, nil
+	}
+	return provider.Result{Target: target, Scorecard: metrics}, nil
+}
+
+func (p *Provider) fetchProject(ctx context.Context, repo string) (*provider.ScorecardMetrics, error) {
+	u := fmt.Sprintf("%s/projects/%s", p.baseURL, repo)
+	req, err := http.NewRequestWithContext(ctx, "GET", u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%d %s", resp.StatusCode, http.StatusText(resp.StatusCode))
+	}
+
+	var raw struct {
+		Date   string  `json:"date"`
+		Score  float64 `json:"score"`
+		Checks []struct {
+			Name  string `json:"name"`
+			Score int    `json:"score"`
+		} `json:"checks"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
+		return nil, fmt.Errorf("decoding response: %w", err)
+	}
+
+	m := &provider.ScorecardMetrics{Score: raw.Score, Date: raw.Date}
+	checks := checkFields(m)
+	// Checks missing from the response (e.g. ones added in newer Scorecard
+	// releases than the analysis) are reported as inconclusive.
+	for _, f := range checks {
+		*f = -1
+	}
+	for _, c := range raw.Checks {
+		if f, ok := checks[c.Name]; ok {
+			*f = c.Score
+		}
+	}
+	return m, nil
+}
+
+// checkFields maps Scorecard check names to the fields of m that hold
+// their scores.
+func checkFields(m *provider.ScorecardMetrics) map[string]*int {
+	return map[string]*int{
+		"Binary-Artifacts":       &m.BinaryArtifacts,
+		"Branch-Protection":      &m.BranchProtection,
+		"CII-Best-Practices":     &m.CIIBestPractices,
+		"CI-Tests":               &m.CITests,
+		"Code-Review":            &m.CodeReview,
+		"Contributors":           &m.Contributors,
+		"Dangerous-Workflow":     &m.DangerousWorkflow,
+		"Dependency-Update-Tool": &m.DependencyUpdateTool,
+		"Fuzzing":                &m.Fuzzing,
+		"License":                &m.License,
+		"Maintained":             &m.Maintained,
+		"Packaging":              &m.Packaging,
+		"Pinned-Dependencies":    &m.PinnedDependencies,
+		"SAST":                   &m.SAST,
+		"Security-Policy":        &m.SecurityPolicy,
+		"Signed-Releases":        &m.SignedReleases,
+		"Token-Permissions":      &m.TokenPermissions,
+		"Vulnerabilities":        &m.Vulnerabilities,
+	}
+}
@@ -0,0 +1,174 @@
+package scorecard
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func mustEncode(w http.ResponseWriter, v any) {
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		panic(err)
+	}
+}
+
+func setupMockServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+
+	// GET /projects/github.com/ossf/scorecard
+	mux.HandleFunc("GET /projects/github.com/ossf/scorecard", func(w http.ResponseWriter, _ *http.Request) {
+		mustEncode(w, map[string]any{
+			"date": "2025-06-02",
+			"repo": map[string]any{
+				"name":   "github.com/ossf/scorecard",
+				"commit": "3e7c6b0f",
+			},
+			"scorecard": map[string]any{"version": "v5.2.1", "commit": "ab12cd34"},
+			"score":     8.2,
+			"checks": []map[string]any{
+				{"name": "Maintained", "score": 10, "reason": "30 commit(s) and 12 issue activity found in the last 90 days -- score normalized to 10"},
+				{"name": "Code-Review", "score": 9, "reason": "Found 28/30 approved changesets -- score normalized to 9"},
+				{"name": "Branch-Protection", "score": -1, "reason": "internal error"},
+				{"name": "Pinned-Dependencies", "score": 7, "reason": "dependency not pinned by hash detected -- score normalized to 7"},
+				{"name": "License", "score": 10, "reason": "license file detected"},
+				{"name": "Webhooks", "score": 10, "reason": "no webhooks defined"},
+			},
+		})
+	})
+
+	return httptest.NewServer(mux)
+}
+
+func TestScheme(t *testing.T) {
+	p := New("")
+	if p.Scheme() != "scorecard" {
+		t.Errorf("got %q, want %q", p.Scheme(), "scorecard")
+	}
+}
+
+func TestFetchSuccess(t *testing.T) {
+	srv := setupMockServer(t)
+	defer srv.Close()
+
+	p := New(srv.URL)
+	result, err := p.Fetch(context.Background(), "github.com/ossf/scorecard")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Error != "" {
+		t.Fatalf("unexpected result error: %s", result.Error)
+	}
+	if result.Target != "scorecard:github.com/ossf/scorecard" {
+		t.Errorf("target: got %q, want %q", result.Target, "scorecard:github.com/ossf/scorecard")
+	}
+	s := result.Scorecard
+	if s == nil {
+		t.Fatal("expected Scorecard metrics to be set")
+	}
+	if s.Score != 8.2 {
+		t.Errorf("score: got %v, want 8.2", s.Score)
+	}
+	if s.Date != "2025-06-02" {
+		t.Errorf("date: got %q, want %q", s.Date, "2025-06-02")
+	}
+	if s.Maintained != 10 {
+		t.Errorf("maintained: got %d, want 10", s.Maintained)
+	}
+	if s.CodeReview != 9 {
+		t.Errorf("code_review: got %d, want 9", s.CodeReview)
+	}
+	if s.BranchProtection != -1 {
+		t.Errorf("branch_protection: got %d, want -1", s.BranchProtection)
+	}
+	if s.PinnedDependencies != 7 {
+		t.Errorf("pinned_dependencies: got %d, want 7", s.PinnedDependencies)
+	}
+	if s.License != 10 {
+		t.Errorf("license: got %d, want 10", s.License)
+	}
+	// Checks absent from the response are inconclusive.
+	if s.Fuzzing != -1 {
+		t.Errorf("fuzzing: got %d, want -1", s.Fuzzing)
+	}
+}
+
+func TestFetchNotFound(t *testing.T) {
+	srv := setupMockServer(t)
+	defer srv.Close()
+
+	p := New(srv.URL)
+	result, err := p.Fetch(context.Background(), "github.com/nonexistent/repo")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Scorecard != nil {
+		t.Error("expected Scorecard to be nil on failure")
+	}
+	if !strings.Contains(result.Error, "404") {
+		t.Errorf("expected 404 in error, got %q", result.Error)
+	}
+}
+
+func TestFetchInvalidIdentifier(t *testing.T) {
+	p := New("http://unused")
+	for _, id := range []string{
+		"",
+		"ossf/scorecard",
+		"example.com/ossf/scorecard",
+		"github.com/ossf",
+		"github.com/ossf/scorecard/extra",
+		"github.com/../admin",
+		"github.com/ossf/score card",
+	} {
+		t.Run(id, func(t *testing.T) {
+			result, err := p.Fetch(context.Background(), id)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Error == "" {
+				t.Errorf("expected error for %q", id)
+			}
+			if result.Scorecard != nil {
+				t.Error("expected Scorecard to be nil")
+			}
+		})
+	}
+}
+
+func TestFetchMalformedJSON(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("{not json"))
+	}))
+	defer srv.Close()
+
+	p := New(srv.URL)
+	result, err := p.Fetch(context.Background(), "github.com/ossf/scorecard")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(result.Error, "decoding response") {
+		t.Errorf("expected decoding error, got %q", result.Error)
+	}
+}
+
+func TestFetchContextCanceled(t *testing.T) {
+	srv := setupMockServer(t)
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	p := New(srv.URL)
+	result, err := p.Fetch(ctx, "github.com/ossf/scorecard")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Error == "" {
+		t.Error("expected error for canceled context")
+	}
+}
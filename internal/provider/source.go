@@ -0,0 +1,108 @@
+package provider
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var (
+	repoShorthandRe = regexp.MustCompile(`^(?:(github|gitlab|bitbucket):)?([\w.-]+/[\w.-]+)$`)
+	scpLikeRe       = regexp.MustCompile(`^(?:[\w.-]+@)?([\w.-]+\.[a-z]{2,}):(.+)$`)
+)
+
+// forges are code hosts whose URLs are known to name a repository.
+var forges = map[string]bool{
+	"github.com":    true,
+	"gitlab.com":    true,
+	"bitbucket.org": true,
+	"codeberg.org":  true,
+}
+
+// NormalizeRepoURL turns a repository reference as found in package
+// metadata ("git+https://github.com/o/r.git", "git@github.com:o/r.git",
+// "github:o/r", "https://github.com/o/r/tree/main/pkg") into a canonical
+// "https://<host>/<owner>/<repo>" URL. It returns "" when raw does not name
+// a repository.
+func NormalizeRepoURL(raw string) string {
+	raw = strings.TrimSpace(raw)
+	if m := repoShorthandRe.FindStringSubmatch(raw); m != nil {
+		host := "github.com"
+		switch m[1] {
+		case "gitlab":
+			host = "gitlab.com"
+		case "bitbucket":
+			host = "bitbucket.org"
+		}
+		raw = "https://" + host + "/" + m[2]
+	}
+
+	raw = strings.TrimPrefix(raw, "git+")
+	if !strings.Contains(raw, "://") {
+		// scp-like syntax: git@github.com:owner/repo.git
+		m := scpLikeRe.FindStringSubmatch(raw)
+		if m == nil {
+			return ""
+		}
+		raw = "https://" + m[1] + "/" + m[2]
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+	switch u.Scheme {
+	case "http", "https", "git", "ssh", "git+ssh":
+	default:
+		return ""
+	}
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	if host == "" {
+		return ""
+	}
+
+	var segs []string
+	for _, s := range strings.Split(u.Path, "/") {
+		if s == "-" {
+			// GitLab separates the project path from sub-pages with "/-/".
+			break
+		}
+		if s != "" {
+			segs = append(segs, s)
+		}
+	}
+	// Only GitLab nests projects in subgroups; elsewhere anything after
+	// owner/repo is a sub-page such as /tree/main.
+	if host != "gitlab.com" && len(segs) > 2 {
+		segs = segs[:2]
+	}
+	if len(segs) < 2 {
+		return ""
+	}
+	segs[len(segs)-1] = strings.TrimSuffix(segs[len(segs)-1], ".git")
+	for _, s := range segs {
+		if s == "" || s == "." || s == ".." {
+			return ""
+		}
+	}
+	return "https://" + host + "/" + strings.Join(segs, "/")
+}
+
+// IsForgeRepo reports whether repoURL, as returned by NormalizeRepoURL,
+// points at a well-known code host. It is used to tell repositories apart
+// from documentation or home pages when metadata does not say which is
+// which.
+func IsForgeRepo(repoURL string) bool {
+	u, err := url.Parse(repoURL)
+	return err == nil && forges[u.Host]
+}
+
+// GitHubRepo returns "owner/repo" when repoURL, as returned by
+// NormalizeRepoURL, is a GitHub repository.
+func GitHubRepo(repoURL string) (string, bool) {
+	rest, ok := strings.CutPrefix(repoURL, "https://github.com/")
+	if !ok || strings.Count(rest, "/") != 1 {
+		return "", false
+	}
+	return rest, true
+}
@@ -0,0 +1,59 @@
+package provider_test
+
+import (
+	"testing"
+
+	"github.com/yutakobayashidev/repiq/internal/provider"
+)
+
+func TestNormalizeRepoURL(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want string
+	}{
+		{"https://github.com/facebook/react", "https://github.com/facebook/react"},
+		{"git+https://github.com/facebook/react.git", "https://github.com/facebook/react"},
+		{"git://github.com/facebook/react.git", "https://github.com/facebook/react"},
+		{"git+ssh://git@github.com/facebook/react.git", "https://github.com/facebook/react"},
+		{"git@github.com:facebook/react.git", "https://github.com/facebook/react"},
+		{"https://www.github.com/Facebook/react/", "https://github.com/Facebook/react"},
+		{"https://github.com/babel/babel/tree/main/packages/babel-core", "https://github.com/babel/babel"},
+		{"github:facebook/react", "https://github.com/facebook/react"},
+		{"facebook/react", "https://github.com/facebook/react"},
+		{"gitlab:gitlab-org/gitlab", "https://gitlab.com/gitlab-org/gitlab"},
+		{"bitbucket:atlassian/python-bitbucket", "https://bitbucket.org/atlassian/python-bitbucket"},
+		{"https://gitlab.com/group/subgroup/project/-/tree/main", "https://gitlab.com/group/subgroup/project"},
+		{"https://git.sr.ht/~sircmpwn/hare", "https://git.sr.ht/~sircmpwn/hare"},
+		{"", ""},
+		{"https://github.com/facebook", ""},
+		{"https://react.dev", ""},
+		{"file:///home/me/repo", ""},
+		{"not a url", ""},
+	}
+	for _, tt := range tests {
+		if got := provider.NormalizeRepoURL(tt.raw); got != tt.want {
+			t.Errorf("NormalizeRepoURL(%q) = %q, want %q", tt.raw, got, tt.want)
+		}
+	}
+}
+
+func TestIsForgeRepo(t *testing.T) {
+	if !provider.IsForgeRepo("https://github.com/facebook/react") {
+		t.Error("github.com should be a forge")
+	}
+	if provider.IsForgeRepo("https://requests.readthedocs.io/en/latest") {
+		t.Error("readthedocs should not be a forge")
+	}
+}
+
+func TestGitHubRepo(t *testing.T) {
+	if got, ok := provider.GitHubRepo("https://github.com/facebook/react"); !ok || got != "facebook/react" {
+		t.Errorf("got (%q, %v), want facebook/react", got, ok)
+	}
+	if _, ok := provider.GitHubRepo("https://gitlab.com/gitlab-org/gitlab"); ok {
+		t.Error("GitLab URL should not be a GitHub repo")
+	}
+	if _, ok := provider.GitHubRepo(""); ok {
+		t.Error("empty URL should not be a GitHub repo")
+	}
+}
@@ -2,15 +2,23 @@ package provider
 
 import (
 	"fmt"
+	"regexp"
 	"strings"
 )
 
+var validHostRe = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9.-]*[a-zA-Z0-9])?(:[0-9]+)?$`)
+
 // Target represents a parsed <scheme>:<identifier> input.
 type Target struct {
 	Scheme     string
 	Identifier string
 }
 
+// String returns the target in its "scheme:identifier" form.
+func (t Target) String() string {
+	return t.Scheme + ":" + t.Identifier
+}
+
 // ParseTarget parses a string in the form "scheme:identifier".
 func ParseTarget(s string) (Target, error) {
 	idx := strings.Index(s, ":")
@@ -27,3 +35,9 @@ func ParseTarget(s string) (Target, error) {
 	}
 	return Target{Scheme: scheme, Identifier: id}, nil
 }
+
+// ValidHost reports whether host is a hostname, with an optional port, that
+// identifiers may name.
+func ValidHost(host string) bool {
+	return validHostRe.MatchString(host)
+}
@@ -12,6 +12,8 @@ func TestParseTarget(t *testing.T) {
 		{"github:facebook/react", "github", "facebook/react", false},
 		{"npm:react", "npm", "react", false},
 		{"github:owner/repo", "github", "owner/repo", false},
+		// Only the first ':' separates the scheme.
+		{"maven:com.google.guava:guava", "maven", "com.google.guava:guava", false},
 		{"", "", "", true},
 		{"nocolon", "", "", true},
 		{":missingscheme", "", "", true},
@@ -35,6 +37,26 @@ func TestParseTarget(t *testing.T) {
 			if tgt.Identifier != tt.wantID {
 				t.Errorf("identifier: got %q, want %q", tgt.Identifier, tt.wantID)
 			}
+			if tgt.String() != tt.input {
+				t.Errorf("String: got %q, want %q", tgt.String(), tt.input)
+			}
 		})
 	}
 }
+
+func TestValidHost(t *testing.T) {
+	for host, want := range map[string]bool{
+		"github.example.com": true,
+		"localhost:3000":     true,
+		"10.0.0.1":           true,
+		"":                   false,
+		"-bad.example.com":   false,
+		"bad host":           false,
+		"example.com:port":   false,
+		"user@example.com":   false,
+	} {
+		if got := ValidHost(host); got != want {
+			t.Errorf("ValidHost(%q) = %v, want %v", host, got, want)
+		}
+	}
+}
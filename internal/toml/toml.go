@@ -0,0 +1,551 @@
+// Package toml implements a small TOML reader sufficient for the manifest,
+// lockfile and configuration files repiq consumes. It decodes a document
+// into nested map[string]any values instead of binding to structs.
+//
+// Supported value types: strings (basic, literal, multi-line), integers,
+// floats, booleans, arrays and inline tables. Dates and times are returned
+// as their raw string form.
+package toml
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Parse decodes a TOML document. Tables become map[string]any, arrays
+// become []any, and arrays of tables become []any of map[string]any.
+func Parse(data []byte) (map[string]any, error) {
+	p := &parser{src: []rune(string(data)), line: 1}
+	root := map[string]any{}
+	current := root
+
+	for {
+		p.skipWhitespaceAndNewlines()
+		if p.eof() {
+			return root, nil
+		}
+		switch p.peek() {
+		case '#':
+			p.skipComment()
+		case '[':
+			table, err := p.parseHeader(root)
+			if err != nil {
+				return nil, err
+			}
+			current = table
+		default:
+			if err := p.parseKeyValue(current); err != nil {
+				return nil, err
+			}
+		}
+		if err := p.expectLineEnd(); err != nil {
+			return nil, err
+		}
+	}
+}
+
+type parser struct {
+	src  []rune
+	pos  int
+	line int
+}
+
+func (p *parser) eof() bool { return p.pos >= len(p.src) }
+
+func (p *parser) peek() rune {
+	if p.eof() {
+		return 0
+	}
+	return p.src[p.pos]
+}
+
+func (p *parser) hasPrefix(s string) bool {
+	rs := []rune(s)
+	if p.pos+len(rs) > len(p.src) {
+		return false
+	}
+	for i, r := range rs {
+		if p.src[p.pos+i] != r {
+			return false
+		}
+	}
+	return true
+}
+
+func (p *parser) next() rune {
+	r := p.src[p.pos]
+	p.pos++
+	if r == '\n' {
+		p.line++
+	}
+	return r
+}
+
+func (p *parser) errorf(format string, args ...any) error {
+	return fmt.Errorf("toml: line %d: %s", p.line, fmt.Sprintf(format, args...))
+}
+
+func (p *parser) skipWhitespace() {
+	for !p.eof() && (p.peek() == ' ' || p.peek() == '\t') {
+		p.pos++
+	}
+}
+
+func (p *parser) skipWhitespaceAndNewlines() {
+	for !p.eof() {
+		switch p.peek() {
+		case ' ', '\t', '\r', '\n':
+			p.next()
+		default:
+			return
+		}
+	}
+}
+
+// skipBlank skips whitespace, newlines and comments (used inside arrays).
+func (p *parser) skipBlank() {
+	for {
+		p.skipWhitespaceAndNewlines()
+		if p.peek() != '#' {
+			return
+		}
+		p.skipComment()
+	}
+}
+
+func (p *parser) skipComment() {
+	for !p.eof() && p.peek() != '\n' {
+		p.pos++
+	}
+}
+
+func (p *parser) expectLineEnd() error {
+	p.skipWhitespace()
+	if p.peek() == '#' {
+		p.skipComment()
+	}
+	if p.peek() == '\r' {
+		p.pos++
+	}
+	if p.eof() || p.peek() == '\n' {
+		return nil
+	}
+	return p.errorf("unexpected %q after value", p.peek())
+}
+
+// parseHeader parses a [table] or [[array.of.tables]] header and returns
+// the table that subsequent key/value pairs belong to.
+func (p *parser) parseHeader(root map[string]any) (map[string]any, error) {
+	p.next()
+	isArray := false
+	if p.peek() == '[' {
+		p.next()
+		isArray = true
+	}
+	keys, err := p.parseKeyPath()
+	if err != nil {
+		return nil, err
+	}
+	p.skipWhitespace()
+	closing := "]"
+	if isArray {
+		closing = "]]"
+	}
+	if !p.hasPrefix(closing) {
+		return nil, p.errorf("expected %q to close table header", closing)
+	}
+	p.pos += len(closing)
+
+	parent, err := descend(root, keys[:len(keys)-1])
+	if err != nil {
+		return nil, p.errorf("%v", err)
+	}
+	last := keys[len(keys)-1]
+
+	if isArray {
+		arr, _ := parent[last].([]any)
+		if existing, ok := parent[last]; ok && arr == nil {
+			return nil, p.errorf("key %q is not an array of tables (got %T)", last, existing)
+		}
+		table := map[string]any{}
+		parent[last] = append(arr, table)
+		return table, nil
+	}
+
+	switch existing := parent[last].(type) {
+	case nil:
+		table := map[string]any{}
+		parent[last] = table
+		return table, nil
+	case map[string]any:
+		return existing, nil
+	default:
+		return nil, p.errorf("key %q is already defined as %T", last, existing)
+	}
+}
+
+// descend walks (and creates) nested tables along keys. When a key refers
+// to an array of tables, the most recently appended table is used.
+func descend(table map[string]any, keys []string) (map[string]any, error) {
+	for _, k := range keys {
+		switch v := table[k].(type) {
+		case nil:
+			child := map[string]any{}
+			table[k] = child
+			table = child
+		case map[string]any:
+			table = v
+		case []any:
+			if len(v) == 0 {
+				return nil, fmt.Errorf("key %q is an empty array", k)
+			}
+			child, ok := v[len(v)-1].(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("key %q is not a table", k)
+			}
+			table = child
+		default:
+			return nil, fmt.Errorf("key %q is already defined as %T", k, v)
+		}
+	}
+	return table, nil
+}
+
+func (p *parser) parseKeyValue(table map[string]any) error {
+	keys, err := p.parseKeyPath()
+	if err != nil {
+		return err
+	}
+	p.skipWhitespace()
+	if p.peek() != '=' {
+		return p.errorf("expected '=' after key %q", strings.Join(keys, "."))
+	}
+	p.next()
+	p.skipWhitespace()
+	value, err := p.parseValue()
+	if err != nil {
+		return err
+	}
+	parent, err := descend(table, keys[:len(keys)-1])
+	if err != nil {
+		return p.errorf("%v", err)
+	}
+	last := keys[len(keys)-1]
+	if _, exists := parent[last]; exists {
+		return p.errorf("duplicate key %q", strings.Join(keys, "."))
+	}
+	parent[last] = value
+	return nil
+}
+
+func (p *parser) parseKeyPath() ([]string, error) {
+	var keys []string
+	for {
+		p.skipWhitespace()
+		key, err := p.parseKey()
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+		p.skipWhitespace()
+		if p.peek() != '.' {
+			return keys, nil
+		}
+		p.next()
+	}
+}
+
+func (p *parser) parseKey() (string, error) {
+	switch p.peek() {
+	case '"':
+		return p.parseBasicString()
+	case '\'':
+		return p.parseLiteralString()
+	}
+	start := p.pos
+	for !p.eof() {
+		r := p.peek()
+		if r == '_' || r == '-' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			p.pos++
+			continue
+		}
+		break
+	}
+	if p.pos == start {
+		return "", p.errorf("expected key, got %q", p.peek())
+	}
+	return string(p.src[start:p.pos]), nil
+}
+
+func (p *parser) parseValue() (any, error) {
+	switch {
+	case p.hasPrefix(`"""`):
+		return p.parseMultilineBasicString()
+	case p.hasPrefix(`'''`):
+		return p.parseMultilineLiteralString()
+	case p.peek() == '"':
+		return p.parseBasicString()
+	case p.peek() == '\'':
+		return p.parseLiteralString()
+	case p.peek() == '[':
+		return p.parseArray()
+	case p.peek() == '{':
+		return p.parseInlineTable()
+	case p.hasPrefix("true"):
+		p.pos += 4
+		return true, nil
+	case p.hasPrefix("false"):
+		p.pos += 5
+		return false, nil
+	}
+	return p.parseScalar()
+}
+
+func (p *parser) parseBasicString() (string, error) {
+	p.next()
+	var b strings.Builder
+	for {
+		if p.eof() || p.peek() == '\n' {
+			return "", p.errorf("unterminated string")
+		}
+		r := p.next()
+		switch r {
+		case '"':
+			return b.String(), nil
+		case '\\':
+			if err := p.parseEscape(&b); err != nil {
+				return "", err
+			}
+		default:
+			b.WriteRune(r)
+		}
+	}
+}
+
+func (p *parser) parseEscape(b *strings.Builder) error {
+	if p.eof() {
+		return p.errorf("unterminated escape sequence")
+	}
+	r := p.next()
+	switch r {
+	case 'b':
+		b.WriteRune('\b')
+	case 't':
+		b.WriteRune('\t')
+	case 'n':
+		b.WriteRune('\n')
+	case 'f':
+		b.WriteRune('\f')
+	case 'r':
+		b.WriteRune('\r')
+	case 'e':
+		b.WriteRune('\x1b')
+	case '"', '\\':
+		b.WriteRune(r)
+	case 'u', 'U':
+		n := 4
+		if r == 'U' {
+			n = 8
+		}
+		if p.pos+n > len(p.src) {
+			return p.errorf("invalid unicode escape")
+		}
+		code, err := strconv.ParseUint(string(p.src[p.pos:p.pos+n]), 16, 32)
+		if err != nil {
+			return p.errorf("invalid unicode escape: %v", err)
+		}
+		p.pos += n
+		b.WriteRune(rune(code))
+	default:
+		return p.errorf("invalid escape sequence \\%c", r)
+	}
+	return nil
+}
+
+func (p *parser) parseLiteralString() (string, error) {
+	p.next()
+	start := p.pos
+	for {
+		if p.eof() || p.peek() == '\n' {
+			return "", p.errorf("unterminated string")
+		}
+		if p.next() == '\'' {
+			return string(p.src[start : p.pos-1]), nil
+		}
+	}
+}
+
+func (p *parser) parseMultilineBasicString() (string, error) {
+	p.pos += 3
+	p.skipLeadingNewline()
+	var b strings.Builder
+	for {
+		if p.eof() {
+			return "", p.errorf("unterminated multi-line string")
+		}
+		if p.hasPrefix(`"""`) {
+			p.pos += 3
+			// Up to two additional quotes may close the string.
+			for i := 0; i < 2 && p.peek() == '"'; i++ {
+				b.WriteRune(p.next())
+			}
+			return b.String(), nil
+		}
+		r := p.next()
+		if r != '\\' {
+			b.WriteRune(r)
+			continue
+		}
+		// A backslash at the end of a line trims all following whitespace.
+		save := p.pos
+		p.skipWhitespace()
+		if p.peek() == '\r' || p.peek() == '\n' {
+			p.skipWhitespaceAndNewlines()
+			continue
+		}
+		p.pos = save
+		if err := p.parseEscape(&b); err != nil {
+			return "", err
+		}
+	}
+}
+
+func (p *parser) parseMultilineLiteralString() (string, error) {
+	p.pos += 3
+	p.skipLeadingNewline()
+	start := p.pos
+	for {
+		if p.eof() {
+			return "", p.errorf("unterminated multi-line string")
+		}
+		if p.hasPrefix(`'''`) {
+			end := p.pos
+			p.pos += 3
+			for i := 0; i < 2 && p.peek() == '\''; i++ {
+				p.pos++
+				end++
+			}
+			return string(p.src[start:end]), nil
+		}
+		p.next()
+	}
+}
+
+func (p *parser) skipLeadingNewline() {
+	if p.hasPrefix("\r\n") {
+		p.pos += 2
+		p.line++
+	} else if p.peek() == '\n' {
+		p.next()
+	}
+}
+
+func (p *parser) parseArray() ([]any, error) {
+	p.next()
+	arr := []any{}
+	for {
+		p.skipBlank()
+		if p.peek() == ']' {
+			p.next()
+			return arr, nil
+		}
+		v, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		arr = append(arr, v)
+		p.skipBlank()
+		switch p.peek() {
+		case ',':
+			p.next()
+		case ']':
+		default:
+			return nil, p.errorf("expected ',' or ']' in array, got %q", p.peek())
+		}
+	}
+}
+
+func (p *parser) parseInlineTable() (map[string]any, error) {
+	p.next()
+	table := map[string]any{}
+	p.skipWhitespace()
+	if p.peek() == '}' {
+		p.next()
+		return table, nil
+	}
+	for {
+		p.skipWhitespace()
+		if err := p.parseKeyValue(table); err != nil {
+			return nil, err
+		}
+		p.skipWhitespace()
+		switch p.peek() {
+		case ',':
+			p.next()
+		case '}':
+			p.next()
+			return table, nil
+		default:
+			return nil, p.errorf("expected ',' or '}' in inline table, got %q", p.peek())
+		}
+	}
+}
+
+// parseScalar parses numbers and bare date/time values.
+func (p *parser) parseScalar() (any, error) {
+	start := p.pos
+	for !p.eof() {
+		r := p.peek()
+		if r == ',' || r == ']' || r == '}' || r == '#' || r == '\n' || r == '\r' {
+			break
+		}
+		// Allow a single space between a date and a time (RFC 3339 variant).
+		if r == ' ' || r == '\t' {
+			if !(r == ' ' && p.pos+1 < len(p.src) && isDigit(p.src[p.pos+1]) && looksLikeDate(p.src[start:p.pos])) {
+				break
+			}
+		}
+		p.pos++
+	}
+	raw := strings.TrimSpace(string(p.src[start:p.pos]))
+	if raw == "" {
+		return nil, p.errorf("expected value")
+	}
+
+	switch raw {
+	case "inf", "+inf":
+		return math.Inf(1), nil
+	case "-inf":
+		return math.Inf(-1), nil
+	case "nan", "+nan", "-nan":
+		return math.NaN(), nil
+	}
+
+	if looksLikeDate([]rune(raw)) || strings.Contains(raw, ":") {
+		return raw, nil
+	}
+
+	clean := strings.ReplaceAll(raw, "_", "")
+	if strings.HasPrefix(clean, "0x") || strings.HasPrefix(clean, "0o") || strings.HasPrefix(clean, "0b") {
+		n, err := strconv.ParseInt(clean, 0, 64)
+		if err != nil {
+			return nil, p.errorf("invalid integer %q", raw)
+		}
+		return n, nil
+	}
+	if n, err := strconv.ParseInt(clean, 10, 64); err == nil {
+		return n, nil
+	}
+	if f, err := strconv.ParseFloat(clean, 64); err == nil {
+		return f, nil
+	}
+	return nil, p.errorf("invalid value %q", raw)
+}
+
+func isDigit(r rune) bool { return r >= '0' && r <= '9' }
+
+func looksLikeDate(rs []rune) bool {
+	return len(rs) >= 10 && isDigit(rs[0]) && isDigit(rs[3]) && rs[4] == '-' && rs[7] == '-'
+}
@@ -0,0 +1,158 @@
+package toml
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseScalars(t *testing.T) {
+	doc := `
+# comment
+name = "repiq"        # trailing comment
+literal = 'C:\path'
+count = 1_000
+ratio = 0.5
+enabled = true
+disabled = false
+hex = 0x1F
+date = 2024-01-02
+datetime = 1979-05-27T07:32:00Z
+`
+	got, err := Parse([]byte(doc))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	want := map[string]any{
+		"name":     "repiq",
+		"literal":  `C:\path`,
+		"count":    int64(1000),
+		"ratio":    0.5,
+		"enabled":  true,
+		"disabled": false,
+		"hex":      int64(31),
+		"date":     "2024-01-02",
+		"datetime": "1979-05-27T07:32:00Z",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %#v\nwant %#v", got, want)
+	}
+}
+
+func TestParseStrings(t *testing.T) {
+	doc := "escaped = \"a\\tb\\u00e9\"\n" +
+		"multi = \"\"\"\nline1\nline2\"\"\"\n" +
+		"trimmed = \"\"\"\\\n    hello \\\n    world\"\"\"\n" +
+		"raw = '''\n\\n stays'''\n"
+	got, err := Parse([]byte(doc))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	want := map[string]any{
+		"escaped": "a\tbé",
+		"multi":   "line1\nline2",
+		"trimmed": "hello world",
+		"raw":     `\n stays`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %#v\nwant %#v", got, want)
+	}
+}
+
+func TestParseTables(t *testing.T) {
+	doc := `
+[package]
+name = "demo"
+
+[dependencies]
+serde = { version = "1.0", features = ["derive"] }
+tokio.version = "1"
+
+[target.'cfg(unix)'.dependencies]
+libc = "0.2"
+
+[[package.authors]]
+name = "a"
+
+[[package.authors]]
+name = "b"
+`
+	got, err := Parse([]byte(doc))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	deps := got["dependencies"].(map[string]any)
+	serde := deps["serde"].(map[string]any)
+	if serde["version"] != "1.0" {
+		t.Errorf("serde.version = %v, want 1.0", serde["version"])
+	}
+	if !reflect.DeepEqual(serde["features"], []any{"derive"}) {
+		t.Errorf("serde.features = %v", serde["features"])
+	}
+	if deps["tokio"].(map[string]any)["version"] != "1" {
+		t.Errorf("tokio.version = %v, want 1", deps["tokio"])
+	}
+	unix := got["target"].(map[string]any)["cfg(unix)"].(map[string]any)
+	if unix["dependencies"].(map[string]any)["libc"] != "0.2" {
+		t.Errorf("target dependency not parsed: %v", unix)
+	}
+	authors := got["package"].(map[string]any)["authors"].([]any)
+	if len(authors) != 2 || authors[1].(map[string]any)["name"] != "b" {
+		t.Errorf("authors = %v", authors)
+	}
+}
+
+func TestParseArrayOfTablesSubtable(t *testing.T) {
+	doc := `
+[[package]]
+name = "a"
+
+[package.dependencies]
+b = ">=1"
+
+[[package]]
+name = "b"
+`
+	got, err := Parse([]byte(doc))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	pkgs := got["package"].([]any)
+	if len(pkgs) != 2 {
+		t.Fatalf("got %d packages, want 2", len(pkgs))
+	}
+	first := pkgs[0].(map[string]any)
+	if first["dependencies"].(map[string]any)["b"] != ">=1" {
+		t.Errorf("dependencies of first package not attached: %v", first)
+	}
+}
+
+func TestParseMultilineArray(t *testing.T) {
+	doc := `
+dependencies = [
+  "requests>=2", # http
+  "click",
+]
+`
+	got, err := Parse([]byte(doc))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if !reflect.DeepEqual(got["dependencies"], []any{"requests>=2", "click"}) {
+		t.Errorf("dependencies = %v", got["dependencies"])
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, doc := range []string{
+		`name = "unterminated`,
+		`name`,
+		"a = 1\na = 2",
+		`a = [1, 2`,
+		`[table`,
+		`a = 1 b = 2`,
+	} {
+		if _, err := Parse([]byte(doc)); err == nil {
+			t.Errorf("expected error for %q", doc)
+		}
+	}
+}
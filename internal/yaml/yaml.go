@@ -0,0 +1,477 @@
+// Package yaml implements a small YAML reader covering the subset used by
+// lockfiles and repiq's own configuration: block mappings and sequences,
+// plain and quoted scalars, single-line flow collections, and literal or
+// folded block scalars. Anchors, tags and multi-document streams are not
+// supported.
+package yaml
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Parse decodes a YAML document. Mappings become map[string]any, sequences
+// become []any, and scalars become string, int64, float64, bool or nil.
+func Parse(data []byte) (any, error) {
+	p := &parser{}
+	for i, raw := range strings.Split(string(data), "\n") {
+		raw = strings.TrimRight(raw, "\r")
+		trimmed := strings.TrimLeft(raw, " ")
+		if strings.HasPrefix(trimmed, "\t") {
+			return nil, fmt.Errorf("yaml: line %d: tabs are not allowed for indentation", i+1)
+		}
+		p.lines = append(p.lines, line{
+			num:    i + 1,
+			indent: len(raw) - len(trimmed),
+			raw:    raw,
+			text:   stripComment(trimmed),
+		})
+	}
+	p.skipBlank()
+	if p.eof() {
+		return nil, nil
+	}
+	if p.cur().text == "---" {
+		p.pos++
+		p.skipBlank()
+		if p.eof() {
+			return nil, nil
+		}
+	}
+	v, err := p.parseNode(p.cur().indent)
+	if err != nil {
+		return nil, err
+	}
+	p.skipBlank()
+	if !p.eof() && p.cur().text != "..." {
+		return nil, p.errorf("unexpected content %q", p.cur().text)
+	}
+	return v, nil
+}
+
+type line struct {
+	num    int
+	indent int
+	raw    string
+	text   string
+}
+
+type parser struct {
+	lines []line
+	pos   int
+}
+
+func (p *parser) eof() bool  { return p.pos >= len(p.lines) }
+func (p *parser) cur() *line { return &p.lines[p.pos] }
+
+func (p *parser) errorf(format string, args ...any) error {
+	num := len(p.lines)
+	if !p.eof() {
+		num = p.cur().num
+	}
+	return fmt.Errorf("yaml: line %d: %s", num, fmt.Sprintf(format, args...))
+}
+
+func (p *parser) skipBlank() {
+	for !p.eof() && p.cur().text == "" {
+		p.pos++
+	}
+}
+
+func isSeqItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+func (p *parser) parseNode(indent int) (any, error) {
+	p.skipBlank()
+	if p.eof() {
+		return nil, nil
+	}
+	l := p.cur()
+	if isSeqItem(l.text) {
+		return p.parseSequence(l.indent)
+	}
+	if _, _, ok := splitKey(l.text); ok {
+		return p.parseMapping(l.indent)
+	}
+	p.pos++
+	return parseScalar(l.text)
+}
+
+func (p *parser) parseSequence(indent int) ([]any, error) {
+	seq := []any{}
+	for {
+		p.skipBlank()
+		if p.eof() {
+			return seq, nil
+		}
+		l := p.cur()
+		if l.indent < indent {
+			return seq, nil
+		}
+		if l.indent > indent || !isSeqItem(l.text) {
+			return nil, p.errorf("bad indentation in sequence")
+		}
+		rest := strings.TrimLeft(strings.TrimPrefix(l.text, "-"), " ")
+		if rest == "" {
+			p.pos++
+			v, err := p.childNode(indent)
+			if err != nil {
+				return nil, err
+			}
+			seq = append(seq, v)
+			continue
+		}
+		// Re-home the remainder of the line as if it started its own
+		// (more deeply indented) line, so "- key: value" parses as a
+		// mapping and "- - x" as a nested sequence.
+		l.indent += len(l.text) - len(rest)
+		l.text = rest
+		if isSeqItem(rest) {
+			v, err := p.parseSequence(l.indent)
+			if err != nil {
+				return nil, err
+			}
+			seq = append(seq, v)
+			continue
+		}
+		if _, _, ok := splitKey(rest); ok {
+			v, err := p.parseMapping(l.indent)
+			if err != nil {
+				return nil, err
+			}
+			seq = append(seq, v)
+			continue
+		}
+		p.pos++
+		v, err := parseScalar(rest)
+		if err != nil {
+			return nil, p.errorf("%v", err)
+		}
+		seq = append(seq, v)
+	}
+}
+
+func (p *parser) parseMapping(indent int) (map[string]any, error) {
+	m := map[string]any{}
+	for {
+		p.skipBlank()
+		if p.eof() {
+			return m, nil
+		}
+		l := p.cur()
+		if l.indent < indent || l.text == "..." {
+			return m, nil
+		}
+		if l.indent > indent {
+			return nil, p.errorf("bad indentation in mapping")
+		}
+		if isSeqItem(l.text) {
+			return m, nil
+		}
+		key, value, ok := splitKey(l.text)
+		if !ok {
+			return nil, p.errorf("expected 'key: value', got %q", l.text)
+		}
+		if _, dup := m[key]; dup {
+			return nil, p.errorf("duplicate key %q", key)
+		}
+		p.pos++
+
+		switch {
+		case value == "":
+			v, err := p.childNode(indent)
+			if err != nil {
+				return nil, err
+			}
+			m[key] = v
+		case isBlockScalarHeader(value):
+			m[key] = p.blockScalar(indent, value)
+		default:
+			v, err := parseScalar(value)
+			if err != nil {
+				return nil, p.errorf("%v", err)
+			}
+			m[key] = v
+		}
+	}
+}
+
+// childNode parses the value nested under a "key:" or "-" line at indent.
+// A sequence may sit at the same indent as its parent key.
+func (p *parser) childNode(indent int) (any, error) {
+	p.skipBlank()
+	if p.eof() {
+		return nil, nil
+	}
+	l := p.cur()
+	if l.indent > indent || (l.indent == indent && isSeqItem(l.text)) {
+		return p.parseNode(l.indent)
+	}
+	return nil, nil
+}
+
+func isBlockScalarHeader(v string) bool {
+	if v == "" || (v[0] != '|' && v[0] != '>') {
+		return false
+	}
+	return strings.Trim(v[1:], "+-0123456789") == ""
+}
+
+func (p *parser) blockScalar(indent int, header string) string {
+	var lines []string
+	blockIndent := -1
+	for !p.eof() {
+		l := p.cur()
+		if strings.TrimSpace(l.raw) == "" {
+			lines = append(lines, "")
+			p.pos++
+			continue
+		}
+		if l.indent <= indent {
+			break
+		}
+		if blockIndent < 0 {
+			blockIndent = l.indent
+		}
+		lines = append(lines, l.raw[min(blockIndent, l.indent):])
+		p.pos++
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	var s string
+	if header[0] == '>' {
+		s = strings.Join(lines, " ")
+	} else {
+		s = strings.Join(lines, "\n")
+	}
+	if !strings.Contains(header, "-") {
+		s += "\n"
+	}
+	return s
+}
+
+// splitKey splits "key: value" (or "key:") into its parts. Quoted keys are
+// unquoted. It reports false when the text is not a mapping entry.
+func splitKey(text string) (key, value string, ok bool) {
+	if text == "" || text[0] == '[' || text[0] == '{' {
+		return "", "", false
+	}
+	if text[0] == '"' || text[0] == '\'' {
+		end := closingQuote(text)
+		if end < 0 {
+			return "", "", false
+		}
+		rest := strings.TrimLeft(text[end+1:], " ")
+		if !strings.HasPrefix(rest, ":") {
+			return "", "", false
+		}
+		k, err := unquote(text[:end+1])
+		if err != nil {
+			return "", "", false
+		}
+		return k, strings.TrimSpace(rest[1:]), true
+	}
+	if strings.HasSuffix(text, ":") {
+		return strings.TrimSpace(text[:len(text)-1]), "", true
+	}
+	if i := strings.Index(text, ": "); i > 0 {
+		return strings.TrimSpace(text[:i]), strings.TrimSpace(text[i+2:]), true
+	}
+	return "", "", false
+}
+
+// closingQuote returns the index of the quote closing the string that
+// starts at s[0], or -1.
+func closingQuote(s string) int {
+	q := s[0]
+	for i := 1; i < len(s); i++ {
+		switch {
+		case q == '"' && s[i] == '\\':
+			i++
+		case q == '\'' && s[i] == '\'' && i+1 < len(s) && s[i+1] == '\'':
+			i++
+		case s[i] == q:
+			return i
+		}
+	}
+	return -1
+}
+
+func unquote(s string) (string, error) {
+	if s[0] == '\'' {
+		return strings.ReplaceAll(s[1:len(s)-1], "''", "'"), nil
+	}
+	return strconv.Unquote(s)
+}
+
+// stripComment removes a trailing "# comment" that is not inside quotes.
+func stripComment(s string) string {
+	inSingle, inDouble := false, false
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '\\' && inDouble:
+			i++
+		case c == '\'' && !inDouble:
+			inSingle = !inSingle
+		case c == '"' && !inSingle:
+			inDouble = !inDouble
+		case c == '#' && !inSingle && !inDouble && (i == 0 || s[i-1] == ' '):
+			return strings.TrimRight(s[:i], " ")
+		}
+	}
+	return strings.TrimRight(s, " ")
+}
+
+func parseScalar(s string) (any, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, nil
+	}
+	switch s[0] {
+	case '"', '\'':
+		end := closingQuote(s)
+		if end != len(s)-1 {
+			return nil, fmt.Errorf("malformed quoted scalar %q", s)
+		}
+		return unquote(s)
+	case '[', '{':
+		f := &flow{s: s}
+		v, err := f.value()
+		if err != nil {
+			return nil, err
+		}
+		f.skipSpace()
+		if f.pos != len(f.s) {
+			return nil, fmt.Errorf("unexpected %q after flow collection", f.s[f.pos:])
+		}
+		return v, nil
+	}
+	return plainScalar(s), nil
+}
+
+func plainScalar(s string) any {
+	switch s {
+	case "~", "null", "Null", "NULL":
+		return nil
+	case "true", "True", "TRUE":
+		return true
+	case "false", "False", "FALSE":
+		return false
+	}
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return n
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil && strings.ContainsAny(s, ".eE") && !strings.ContainsAny(s, "xX") {
+		return f
+	}
+	return s
+}
+
+// flow parses single-line flow collections such as [a, b] and {k: v}.
+type flow struct {
+	s   string
+	pos int
+}
+
+func (f *flow) skipSpace() {
+	for f.pos < len(f.s) && f.s[f.pos] == ' ' {
+		f.pos++
+	}
+}
+
+func (f *flow) value() (any, error) {
+	f.skipSpace()
+	if f.pos >= len(f.s) {
+		return nil, fmt.Errorf("unexpected end of flow collection")
+	}
+	switch f.s[f.pos] {
+	case '[':
+		return f.sequence()
+	case '{':
+		return f.mapping()
+	case '"', '\'':
+		end := closingQuote(f.s[f.pos:])
+		if end < 0 {
+			return nil, fmt.Errorf("unterminated quoted scalar")
+		}
+		raw := f.s[f.pos : f.pos+end+1]
+		f.pos += end + 1
+		return unquote(raw)
+	}
+	start := f.pos
+	for f.pos < len(f.s) && !strings.ContainsRune(",]}", rune(f.s[f.pos])) {
+		if f.s[f.pos] == ':' && (f.pos+1 == len(f.s) || f.s[f.pos+1] == ' ') {
+			break
+		}
+		f.pos++
+	}
+	return plainScalar(strings.TrimSpace(f.s[start:f.pos])), nil
+}
+
+func (f *flow) sequence() ([]any, error) {
+	f.pos++
+	seq := []any{}
+	for {
+		f.skipSpace()
+		if f.pos < len(f.s) && f.s[f.pos] == ']' {
+			f.pos++
+			return seq, nil
+		}
+		v, err := f.value()
+		if err != nil {
+			return nil, err
+		}
+		seq = append(seq, v)
+		f.skipSpace()
+		if f.pos >= len(f.s) {
+			return nil, fmt.Errorf("unterminated flow sequence")
+		}
+		switch f.s[f.pos] {
+		case ',':
+			f.pos++
+		case ']':
+		default:
+			return nil, fmt.Errorf("expected ',' or ']' in flow sequence")
+		}
+	}
+}
+
+func (f *flow) mapping() (map[string]any, error) {
+	f.pos++
+	m := map[string]any{}
+	for {
+		f.skipSpace()
+		if f.pos < len(f.s) && f.s[f.pos] == '}' {
+			f.pos++
+			return m, nil
+		}
+		k, err := f.value()
+		if err != nil {
+			return nil, err
+		}
+		f.skipSpace()
+		var v any
+		if f.pos < len(f.s) && f.s[f.pos] == ':' {
+			f.pos++
+			if v, err = f.value(); err != nil {
+				return nil, err
+			}
+		}
+		m[fmt.Sprint(k)] = v
+		f.skipSpace()
+		if f.pos >= len(f.s) {
+			return nil, fmt.Errorf("unterminated flow mapping")
+		}
+		switch f.s[f.pos] {
+		case ',':
+			f.pos++
+		case '}':
+		default:
+			return nil, fmt.Errorf("expected ',' or '}' in flow mapping")
+		}
+	}
+}
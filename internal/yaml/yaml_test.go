@@ -0,0 +1,165 @@
+package yaml
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseMapping(t *testing.T) {
+	doc := `
+# comment
+name: repiq
+count: 42
+ratio: 0.5
+enabled: true
+empty:
+quoted: "a # not a comment"
+single: 'it''s'
+url: https://example.com/x  # trailing comment
+`
+	got, err := Parse([]byte(doc))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	want := map[string]any{
+		"name":    "repiq",
+		"count":   int64(42),
+		"ratio":   0.5,
+		"enabled": true,
+		"empty":   nil,
+		"quoted":  "a # not a comment",
+		"single":  "it's",
+		"url":     "https://example.com/x",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %#v\nwant %#v", got, want)
+	}
+}
+
+func TestParseNested(t *testing.T) {
+	doc := `
+rules:
+  - field: last_commit_days
+    max: 365
+  - field: license
+    allow: [MIT, "Apache-2.0"]
+  - scheme: npm
+    field: weekly_downloads
+    min: 1000
+tags:
+- a
+- b
+`
+	got, err := Parse([]byte(doc))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	m := got.(map[string]any)
+	rules := m["rules"].([]any)
+	if len(rules) != 3 {
+		t.Fatalf("got %d rules, want 3", len(rules))
+	}
+	second := rules[1].(map[string]any)
+	if !reflect.DeepEqual(second["allow"], []any{"MIT", "Apache-2.0"}) {
+		t.Errorf("allow = %#v", second["allow"])
+	}
+	third := rules[2].(map[string]any)
+	if third["min"] != int64(1000) || third["scheme"] != "npm" {
+		t.Errorf("third rule = %#v", third)
+	}
+	if !reflect.DeepEqual(m["tags"], []any{"a", "b"}) {
+		t.Errorf("tags = %#v", m["tags"])
+	}
+}
+
+func TestParsePnpmStyle(t *testing.T) {
+	doc := `lockfileVersion: '9.0'
+
+importers:
+
+  .:
+    dependencies:
+      react:
+        specifier: ^18.2.0
+        version: 18.2.0
+
+packages:
+
+  '@babel/core@7.24.0':
+    resolution: {integrity: sha512-abc==}
+    engines: {node: '>=6.9.0'}
+
+snapshots:
+
+  react@18.2.0:
+    dependencies:
+      loose-envify: 1.4.0
+`
+	got, err := Parse([]byte(doc))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	m := got.(map[string]any)
+	if m["lockfileVersion"] != "9.0" {
+		t.Errorf("lockfileVersion = %#v", m["lockfileVersion"])
+	}
+	root := m["importers"].(map[string]any)["."].(map[string]any)
+	react := root["dependencies"].(map[string]any)["react"].(map[string]any)
+	if react["version"] != "18.2.0" {
+		t.Errorf("react version = %#v", react["version"])
+	}
+	babel := m["packages"].(map[string]any)["@babel/core@7.24.0"].(map[string]any)
+	res := babel["resolution"].(map[string]any)
+	if res["integrity"] != "sha512-abc==" {
+		t.Errorf("integrity = %#v", res["integrity"])
+	}
+	if babel["engines"].(map[string]any)["node"] != ">=6.9.0" {
+		t.Errorf("engines = %#v", babel["engines"])
+	}
+	snap := m["snapshots"].(map[string]any)["react@18.2.0"].(map[string]any)
+	if snap["dependencies"].(map[string]any)["loose-envify"] != "1.4.0" {
+		t.Errorf("snapshot deps = %#v", snap)
+	}
+}
+
+func TestParseBlockScalar(t *testing.T) {
+	doc := "literal: |\n  line1\n  line2\nfolded: >-\n  a\n  b\nafter: x\n"
+	got, err := Parse([]byte(doc))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	m := got.(map[string]any)
+	if m["literal"] != "line1\nline2\n" {
+		t.Errorf("literal = %q", m["literal"])
+	}
+	if m["folded"] != "a b" {
+		t.Errorf("folded = %q", m["folded"])
+	}
+	if m["after"] != "x" {
+		t.Errorf("after = %#v", m["after"])
+	}
+}
+
+func TestParseEmpty(t *testing.T) {
+	got, err := Parse([]byte("# only a comment\n"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if got != nil {
+		t.Errorf("got %#v, want nil", got)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, doc := range []string{
+		"a: 1\na: 2\n",
+		"a:\n    b: 1\n  c: 2\n",
+		"a: [1, 2\n",
+		"a: \"unterminated\n",
+		"a:\n\tb: 1\n",
+	} {
+		if _, err := Parse([]byte(doc)); err == nil {
+			t.Errorf("expected error for %q", doc)
+		}
+	}
+}